@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/org-structure-api/internal/config"
+	"github.com/org-structure-api/internal/repository"
+	"github.com/org-structure-api/internal/service"
+	"gorm.io/gorm"
+)
+
+// maxReportedMismatches - сколько расхождений выводит проверка иерархии
+const maxReportedMismatches = 100
+
+// runCommand выполняет служебную команду, переданную аргументами вместо запуска сервера:
+//
+//	api hierarchy check    - сверить таблицу замыкания с parent_id
+//	api hierarchy rebuild  - пересобрать таблицу замыкания
+//	api trash purge        - окончательно удалить записи старше TRASH_RETENTION
+func runCommand(ctx context.Context, cfg *config.Config, db *gorm.DB, logger *slog.Logger, args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "hierarchy":
+		return runHierarchyCommand(ctx, db, logger, args[1])
+	case len(args) == 2 && args[0] == "trash" && args[1] == "purge":
+		trashService := service.NewTrashService(
+			repository.NewDepartmentRepository(db),
+			repository.NewEmployeeRepository(db),
+			repository.NewTxManager(db),
+		)
+		return purgeTrash(ctx, trashService, cfg.Trash.Retention, logger)
+	default:
+		return fmt.Errorf("unknown command %q", args)
+	}
+}
+
+func runHierarchyCommand(ctx context.Context, db *gorm.DB, logger *slog.Logger, command string) error {
+	hierarchy := repository.NewHierarchyRepository(db)
+
+	switch command {
+	case "check":
+		mismatches, err := hierarchy.Check(ctx, maxReportedMismatches)
+		if err != nil {
+			return fmt.Errorf("failed to check hierarchy: %w", err)
+		}
+		for _, m := range mismatches {
+			logger.Warn("closure mismatch",
+				slog.Int64("ancestor_id", m.AncestorID),
+				slog.Int64("descendant_id", m.DescendantID),
+				slog.Any("expected_depth", m.ExpectedDepth),
+				slog.Any("actual_depth", m.ActualDepth),
+			)
+		}
+		if len(mismatches) > 0 {
+			return fmt.Errorf("closure table is inconsistent, run \"hierarchy rebuild\"")
+		}
+		logger.Info("closure table is consistent")
+	case "rebuild":
+		rows, err := hierarchy.Rebuild(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to rebuild hierarchy: %w", err)
+		}
+		logger.Info("closure table rebuilt", slog.Int64("rows", rows))
+	default:
+		return fmt.Errorf("unknown hierarchy command %q", command)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/org-structure-api/internal/config"
+	"github.com/org-structure-api/internal/service"
+)
+
+// runPurgeJob с периодом cfg.PurgeInterval окончательно удаляет записи, пролежавшие
+// в корзине дольше cfg.Retention. Завершается при отмене ctx
+func runPurgeJob(ctx context.Context, trashService service.TrashService, cfg config.TrashConfig, logger *slog.Logger) {
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purgeTrash(ctx, trashService, cfg.Retention, logger)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeTrash(ctx context.Context, trashService service.TrashService, retention time.Duration, logger *slog.Logger) error {
+	result, err := trashService.Purge(ctx, retention)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("failed to purge trash", slog.Any("error", err))
+		}
+		return err
+	}
+
+	if result.Departments > 0 || result.Employees > 0 {
+		logger.Info("trash purged",
+			slog.Int64("departments", result.Departments),
+			slog.Int64("employees", result.Employees),
+		)
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	gormlogger "gorm.io/gorm/logger"
 )
 
+//go:embed migrations/*.sql
 var embedMigrations embed.FS
 
 func main() {
@@ -54,19 +55,57 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Служебные команды выполняются вместо запуска сервера
+	if len(os.Args) > 1 {
+		if err := runCommand(context.Background(), cfg, db, logger, os.Args[1:]); err != nil {
+			logger.Error("command failed", slog.Any("error", err))
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Инициализация репозиториев
 	deptRepo := repository.NewDepartmentRepository(db)
 	empRepo := repository.NewEmployeeRepository(db)
+	transferRepo := repository.NewTransferRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	temporalRepo := repository.NewTemporalRepository(db)
+	snapshotRepo := repository.NewSnapshotRepository(db)
+	delegationRepo := repository.NewDelegationRepository(db)
+	txManager := repository.NewTxManager(db)
 
 	// Инициализация сервисов
-	deptService := service.NewDepartmentService(deptRepo, empRepo)
-	empService := service.NewEmployeeService(empRepo, deptRepo)
+	employeePolicy := service.EmployeePolicy{
+		StrictHeadcount:     cfg.Headcount.Strict,
+		AutoCreatePositions: cfg.Positions.AutoCreate,
+	}
+	deptService := service.NewDepartmentService(deptRepo, empRepo, temporalRepo, txManager, employeePolicy)
+	empService := service.NewEmployeeService(empRepo, deptRepo, transferRepo, temporalRepo, txManager, employeePolicy)
+	trashService := service.NewTrashService(deptRepo, empRepo, txManager)
+	auditService := service.NewAuditService(auditRepo)
+	diffService := service.NewDiffService(temporalRepo, snapshotRepo)
+	snapshotService := service.NewSnapshotService(snapshotRepo)
+	draftService := service.NewDraftService(repository.NewDraftRepository(db), deptService, empService, txManager)
+	managementService := service.NewManagementService(empRepo, deptRepo, repository.NewManagementRepository(db), delegationRepo, cfg.Management.SpanOfControlThreshold)
+	delegationService := service.NewDelegationService(delegationRepo, txManager)
+	positionService := service.NewPositionService(repository.NewPositionRepository(db), txManager)
+	headcountService := service.NewHeadcountService(repository.NewHeadcountRepository(db), deptRepo, txManager)
 
 	// Инициализация хендлеров
 	deptHandler := handler.NewDepartmentHandler(deptService, empService, logger)
+	empHandler := handler.NewEmployeeHandler(empService, logger)
+	trashHandler := handler.NewTrashHandler(trashService, logger)
+	auditHandler := handler.NewAuditHandler(auditService, logger)
+	diffHandler := handler.NewDiffHandler(diffService, logger)
+	snapshotHandler := handler.NewSnapshotHandler(snapshotService, logger)
+	draftHandler := handler.NewDraftHandler(draftService, logger)
+	managementHandler := handler.NewManagementHandler(managementService, logger)
+	delegationHandler := handler.NewDelegationHandler(delegationService, logger)
+	positionHandler := handler.NewPositionHandler(positionService, logger)
+	headcountHandler := handler.NewHeadcountHandler(headcountService, logger)
 
 	// Настройка роутера
-	router := handler.NewRouter(deptHandler, logger)
+	router := handler.NewRouter(deptHandler, empHandler, trashHandler, auditHandler, diffHandler, snapshotHandler, draftHandler, managementHandler, delegationHandler, positionHandler, headcountHandler, logger)
 	httpHandler := router.Setup()
 
 	// Настройка HTTP сервера
@@ -78,6 +117,12 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Фоновая очистка корзины
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	if cfg.Trash.PurgeInterval > 0 {
+		go runPurgeJob(jobsCtx, trashService, cfg.Trash, logger)
+	}
+
 	done := make(chan bool)
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -85,6 +130,7 @@ func main() {
 	go func() {
 		<-quit
 		logger.Info("server is shutting down...")
+		stopJobs()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
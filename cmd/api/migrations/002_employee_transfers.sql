@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS employee_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    employee_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    from_department_id BIGINT REFERENCES departments(id) ON DELETE SET NULL,
+    to_department_id BIGINT REFERENCES departments(id) ON DELETE SET NULL,
+    effective_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_transfers_employee_id ON employee_transfers(employee_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS employee_transfers;
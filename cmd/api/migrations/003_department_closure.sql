@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS department_closure (
+    ancestor_id BIGINT NOT NULL REFERENCES departments(id) ON DELETE CASCADE,
+    descendant_id BIGINT NOT NULL REFERENCES departments(id) ON DELETE CASCADE,
+    depth INT NOT NULL,
+    PRIMARY KEY (ancestor_id, descendant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_department_closure_descendant ON department_closure(descendant_id, depth);
+
+INSERT INTO department_closure (ancestor_id, descendant_id, depth)
+WITH RECURSIVE paths AS (
+    SELECT id AS ancestor_id, id AS descendant_id, 0 AS depth FROM departments
+    UNION ALL
+    SELECT p.ancestor_id, d.id, p.depth + 1 FROM departments d
+    INNER JOIN paths p ON d.parent_id = p.descendant_id
+)
+SELECT ancestor_id, descendant_id, depth FROM paths;
+
+-- +goose Down
+DROP TABLE IF EXISTS department_closure;
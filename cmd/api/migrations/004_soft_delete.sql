@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_departments_deleted_at ON departments(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_employees_deleted_at ON employees(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Имя должно быть уникальным только среди неудалённых подразделений
+ALTER TABLE departments DROP CONSTRAINT IF EXISTS unique_name_per_parent;
+CREATE UNIQUE INDEX IF NOT EXISTS unique_name_per_parent ON departments(name, parent_id) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DELETE FROM employees WHERE deleted_at IS NOT NULL;
+DELETE FROM departments WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS unique_name_per_parent;
+ALTER TABLE departments ADD CONSTRAINT unique_name_per_parent UNIQUE (name, parent_id);
+
+DROP INDEX IF EXISTS idx_employees_deleted_at;
+DROP INDEX IF EXISTS idx_departments_deleted_at;
+
+ALTER TABLE employees DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE departments DROP COLUMN IF EXISTS deleted_at;
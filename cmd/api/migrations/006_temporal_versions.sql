@@ -0,0 +1,46 @@
+-- +goose Up
+-- История имени и родителя подразделений и назначений сотрудников.
+-- Интервал действия [valid_from, valid_to) в днях; valid_to = NULL у действующей записи
+CREATE TABLE IF NOT EXISTS department_versions (
+    id BIGSERIAL PRIMARY KEY,
+    department_id BIGINT NOT NULL REFERENCES departments(id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL,
+    parent_id BIGINT,
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    CONSTRAINT department_versions_interval CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_department_versions_open ON department_versions(department_id) WHERE valid_to IS NULL;
+CREATE INDEX IF NOT EXISTS idx_department_versions_department ON department_versions(department_id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_department_versions_parent ON department_versions(parent_id, valid_from);
+
+CREATE TABLE IF NOT EXISTS employee_assignments (
+    id BIGSERIAL PRIMARY KEY,
+    employee_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    department_id BIGINT NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    CONSTRAINT employee_assignments_interval CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employee_assignments_open ON employee_assignments(employee_id) WHERE valid_to IS NULL;
+CREATE INDEX IF NOT EXISTS idx_employee_assignments_employee ON employee_assignments(employee_id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_employee_assignments_department ON employee_assignments(department_id, valid_from);
+
+-- Начальная история: текущее состояние с даты создания записи до даты удаления.
+-- Более ранние перемещения и переводы не восстанавливаются; записи,
+-- удалённые в день создания, в историю не попадают
+INSERT INTO department_versions (department_id, name, parent_id, valid_from, valid_to)
+SELECT id, name, parent_id, (created_at AT TIME ZONE 'UTC')::date, (deleted_at AT TIME ZONE 'UTC')::date
+FROM departments
+WHERE deleted_at IS NULL OR (deleted_at AT TIME ZONE 'UTC')::date > (created_at AT TIME ZONE 'UTC')::date;
+
+INSERT INTO employee_assignments (employee_id, department_id, valid_from, valid_to)
+SELECT id, department_id, (created_at AT TIME ZONE 'UTC')::date, (deleted_at AT TIME ZONE 'UTC')::date
+FROM employees
+WHERE deleted_at IS NULL OR (deleted_at AT TIME ZONE 'UTC')::date > (created_at AT TIME ZONE 'UTC')::date;
+
+-- +goose Down
+DROP TABLE IF EXISTS employee_assignments;
+DROP TABLE IF EXISTS department_versions;
@@ -0,0 +1,61 @@
+-- +goose Up
+-- Снимки структуры организации. Каждое сохранение под тем же именем получает
+-- следующую версию; содержимое снимка после создания не изменяется
+CREATE TABLE IF NOT EXISTS snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    version INT NOT NULL,
+    created_by VARCHAR(200) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT snapshots_name_version UNIQUE (name, version)
+);
+
+-- ID подразделений и сотрудников хранятся без внешних ключей: снимок переживает
+-- окончательное удаление записей из корзины
+CREATE TABLE IF NOT EXISTS snapshot_departments (
+    snapshot_id BIGINT NOT NULL REFERENCES snapshots(id),
+    department_id BIGINT NOT NULL,
+    name VARCHAR(200) NOT NULL,
+    parent_id BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (snapshot_id, department_id)
+);
+
+CREATE TABLE IF NOT EXISTS snapshot_employees (
+    snapshot_id BIGINT NOT NULL REFERENCES snapshots(id),
+    employee_id BIGINT NOT NULL,
+    department_id BIGINT NOT NULL,
+    full_name VARCHAR(200) NOT NULL,
+    position VARCHAR(200) NOT NULL,
+    hired_at DATE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (snapshot_id, employee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_snapshot_employees_department ON snapshot_employees(snapshot_id, department_id);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION snapshots_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'snapshots are immutable';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER snapshots_immutable
+    BEFORE UPDATE OR DELETE ON snapshots
+    FOR EACH ROW EXECUTE FUNCTION snapshots_immutable();
+
+CREATE TRIGGER snapshot_departments_immutable
+    BEFORE UPDATE OR DELETE ON snapshot_departments
+    FOR EACH ROW EXECUTE FUNCTION snapshots_immutable();
+
+CREATE TRIGGER snapshot_employees_immutable
+    BEFORE UPDATE OR DELETE ON snapshot_employees
+    FOR EACH ROW EXECUTE FUNCTION snapshots_immutable();
+
+-- +goose Down
+DROP TABLE IF EXISTS snapshot_employees;
+DROP TABLE IF EXISTS snapshot_departments;
+DROP TABLE IF EXISTS snapshots;
+DROP FUNCTION IF EXISTS snapshots_immutable();
@@ -0,0 +1,36 @@
+-- +goose Up
+-- Черновики реорганизации: операции копятся в черновике, проверяются и
+-- применяются вместе в одной транзакции
+CREATE TABLE IF NOT EXISTS reorg_drafts (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_by VARCHAR(200) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT reorg_drafts_status CHECK (status IN ('open', 'applied', 'discarded'))
+);
+
+-- ID подразделений и сотрудников хранятся без внешних ключей: они проверяются
+-- при предпросмотре и применении, а к тому времени записи могут быть удалены
+CREATE TABLE IF NOT EXISTS reorg_draft_operations (
+    id BIGSERIAL PRIMARY KEY,
+    draft_id BIGINT NOT NULL REFERENCES reorg_drafts(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    ref VARCHAR(100),
+    department_id BIGINT,
+    department_ref VARCHAR(100),
+    parent_id BIGINT,
+    parent_ref VARCHAR(100),
+    name VARCHAR(200),
+    delete_mode VARCHAR(20),
+    reassign_to_id BIGINT,
+    reassign_to_ref VARCHAR(100),
+    employee_id BIGINT,
+    CONSTRAINT reorg_draft_operations_position UNIQUE (draft_id, position)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS reorg_draft_operations;
+DROP TABLE IF EXISTS reorg_drafts;
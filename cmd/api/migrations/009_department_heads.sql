@@ -0,0 +1,28 @@
+-- +goose Up
+-- Руководитель подразделения - сотрудник, возможно из другого подразделения
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS head_employee_id BIGINT REFERENCES employees(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_departments_head_employee_id ON departments(head_employee_id);
+
+-- При мягком удалении сотрудника снимаем его с руководства, как внешний ключ
+-- делает это при окончательном удалении
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION clear_deleted_department_heads() RETURNS trigger AS $$
+BEGIN
+    UPDATE departments SET head_employee_id = NULL WHERE head_employee_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER employees_clear_department_heads
+    AFTER UPDATE OF deleted_at ON employees
+    FOR EACH ROW
+    WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+    EXECUTE FUNCTION clear_deleted_department_heads();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS employees_clear_department_heads ON employees;
+DROP FUNCTION IF EXISTS clear_deleted_department_heads();
+DROP INDEX IF EXISTS idx_departments_head_employee_id;
+ALTER TABLE departments DROP COLUMN IF EXISTS head_employee_id;
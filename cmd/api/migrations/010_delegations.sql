@@ -0,0 +1,24 @@
+-- +goose Up
+-- Делегирование полномочий руководителя подразделения заместителю на период.
+-- Записи не удаляются: отзыв отмечается revoked_at
+CREATE TABLE IF NOT EXISTS delegations (
+    id BIGSERIAL PRIMARY KEY,
+    department_id BIGINT NOT NULL REFERENCES departments(id) ON DELETE CASCADE,
+    delegator_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    delegate_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    starts_on DATE NOT NULL,
+    ends_on DATE NOT NULL,
+    created_by VARCHAR(200) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_by VARCHAR(200),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT delegations_period CHECK (starts_on <= ends_on),
+    CONSTRAINT delegations_not_self CHECK (delegator_id <> delegate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_delegations_department_id ON delegations(department_id, starts_on);
+CREATE INDEX IF NOT EXISTS idx_delegations_delegator_id ON delegations(delegator_id);
+CREATE INDEX IF NOT EXISTS idx_delegations_delegate_id ON delegations(delegate_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS delegations;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- Справочник должностей. Названия уникальны без учёта регистра
+CREATE TABLE IF NOT EXISTS positions (
+    id BIGSERIAL PRIMARY KEY,
+    title VARCHAR(200) NOT NULL,
+    job_family VARCHAR(100),
+    grade INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positions_grade_positive CHECK (grade > 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_positions_title ON positions(lower(title));
+CREATE INDEX IF NOT EXISTS idx_positions_job_family ON positions(job_family);
+
+-- Заполняем справочник должностями, уже указанными у сотрудников (включая удалённых).
+-- Из вариантов одного названия в разном регистре берётся самый ранний
+INSERT INTO positions (title)
+SELECT DISTINCT ON (lower(btrim(position))) btrim(position)
+FROM employees
+WHERE btrim(position) <> ''
+ORDER BY lower(btrim(position)), id;
+
+-- Текстовое поле position сохраняется для совместимости и всегда равно названию должности
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS position_id BIGINT REFERENCES positions(id);
+
+UPDATE employees e
+SET position_id = p.id, position = p.title
+FROM positions p
+WHERE lower(btrim(e.position)) = lower(p.title);
+
+CREATE INDEX IF NOT EXISTS idx_employees_position_id ON employees(position_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_employees_position_id;
+ALTER TABLE employees DROP COLUMN IF EXISTS position_id;
+DROP TABLE IF EXISTS positions;
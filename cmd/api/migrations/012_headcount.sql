@@ -0,0 +1,23 @@
+-- +goose Up
+-- Утверждённая численность: сколько сотрудников на должности предусмотрено
+-- в подразделении. Занятые места считаются по сотрудникам при чтении.
+-- Должность с местами в плане удалить нельзя, чтобы план не терялся незаметно
+CREATE TABLE IF NOT EXISTS headcount_slots (
+    id BIGSERIAL PRIMARY KEY,
+    department_id BIGINT NOT NULL REFERENCES departments(id) ON DELETE CASCADE,
+    position_id BIGINT NOT NULL REFERENCES positions(id) ON DELETE RESTRICT,
+    approved INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT headcount_slots_approved_non_negative CHECK (approved >= 0),
+    CONSTRAINT headcount_slots_department_position UNIQUE (department_id, position_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_headcount_slots_position_id ON headcount_slots(position_id);
+
+-- Занятые места считаются по подразделению и должности сотрудника
+CREATE INDEX IF NOT EXISTS idx_employees_department_position ON employees(department_id, position_id) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_employees_department_position;
+DROP TABLE IF EXISTS headcount_slots;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Снимки сохраняют руководителя подразделения и должность сотрудника из справочника.
+-- Как и остальные ID в снимках, хранятся без внешних ключей; в снимках, созданных
+-- до этой миграции, они пусты
+ALTER TABLE snapshot_departments ADD COLUMN IF NOT EXISTS head_employee_id BIGINT;
+ALTER TABLE snapshot_employees ADD COLUMN IF NOT EXISTS position_id BIGINT;
+
+-- +goose Down
+ALTER TABLE snapshot_employees DROP COLUMN IF EXISTS position_id;
+ALTER TABLE snapshot_departments DROP COLUMN IF EXISTS head_employee_id;
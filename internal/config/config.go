@@ -3,12 +3,18 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config содержит настройки приложения
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Trash      TrashConfig
+	Management ManagementConfig
+	Headcount  HeadcountConfig
+	Positions  PositionsConfig
 }
 
 // ServerConfig - настройки HTTP сервера
@@ -26,6 +32,35 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// TrashConfig - настройки корзины удалённых записей
+type TrashConfig struct {
+	// Retention - сколько хранятся удалённые записи до окончательного удаления
+	Retention time.Duration
+	// PurgeInterval - период запуска очистки; 0 отключает фоновую очистку
+	PurgeInterval time.Duration
+}
+
+// ManagementConfig - настройки отчётов по линии подчинения
+type ManagementConfig struct {
+	// SpanOfControlThreshold - число прямых подчинённых, сверх которого руководитель
+	// попадает в отчёт о нормах управляемости
+	SpanOfControlThreshold int
+}
+
+// HeadcountConfig - настройки планирования численности
+type HeadcountConfig struct {
+	// Strict - запрещать приём, перевод и смену должности сотрудника, если в плане
+	// численности подразделения нет вакансии на его должность
+	Strict bool
+}
+
+// PositionsConfig - настройки справочника должностей
+type PositionsConfig struct {
+	// AutoCreate - создавать должность в справочнике, если у сотрудника указано
+	// неизвестное название
+	AutoCreate bool
+}
+
 // DSN возвращает строку подключения к PostgreSQL
 func (c *DatabaseConfig) DSN() string {
 	return fmt.Sprintf(
@@ -48,6 +83,19 @@ func Load() *Config {
 			DBName:   getEnv("DB_NAME", "orgstructure"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
+		Trash: TrashConfig{
+			Retention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+			PurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		},
+		Management: ManagementConfig{
+			SpanOfControlThreshold: getEnvInt("SPAN_OF_CONTROL_THRESHOLD", 8),
+		},
+		Headcount: HeadcountConfig{
+			Strict: getEnvBool("HEADCOUNT_STRICT", false),
+		},
+		Positions: PositionsConfig{
+			AutoCreate: getEnvBool("POSITIONS_AUTO_CREATE", false),
+		},
 	}
 }
 
@@ -58,3 +106,30 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration возвращает длительность из переменной окружения (например, "720h")
+// или значение по умолчанию, если переменная не задана или некорректна
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvInt возвращает целое число из переменной окружения или значение по умолчанию,
+// если переменная не задана или некорректна
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvBool возвращает логическое значение из переменной окружения ("true", "1", ...)
+// или значение по умолчанию, если переменная не задана или некорректна
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -1,16 +1,62 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Определение бизнес-ошибок
 var (
-	ErrDepartmentNotFound      = errors.New("department not found")
-	ErrEmployeeNotFound        = errors.New("employee not found")
-	ErrDuplicateDepartmentName = errors.New("department with this name already exists in the same parent")
-	ErrSelfReference           = errors.New("department cannot be its own parent")
-	ErrCyclicReference         = errors.New("moving department would create a cycle")
-	ErrInvalidDeleteMode       = errors.New("invalid delete mode")
-	ErrReassignTargetRequired  = errors.New("reassign_to_department_id is required when mode is reassign")
-	ErrReassignTargetNotFound  = errors.New("target department for reassignment not found")
-	ErrCannotReassignToSelf    = errors.New("cannot reassign employees to the same department being deleted")
+	ErrDepartmentNotFound       = errors.New("department not found")
+	ErrEmployeeNotFound         = errors.New("employee not found")
+	ErrDuplicateDepartmentName  = errors.New("department with this name already exists in the same parent")
+	ErrSelfReference            = errors.New("department cannot be its own parent")
+	ErrCyclicReference          = errors.New("moving department would create a cycle")
+	ErrInvalidDeleteMode        = errors.New("invalid delete mode")
+	ErrReassignTargetRequired   = errors.New("reassign_to_department_id is required when mode is reassign")
+	ErrReassignTargetNotFound   = errors.New("target department for reassignment not found")
+	ErrCannotReassignToSelf     = errors.New("cannot reassign employees to the same department being deleted")
+	ErrTransferToSameDepartment = errors.New("employee already belongs to the target department")
+	ErrFutureEffectiveDate      = errors.New("effective date cannot be in the future")
+	ErrEffectiveDateTooEarly    = errors.New("effective date is earlier than the latest recorded change")
+	ErrInvalidCursor            = errors.New("invalid pagination cursor")
+	ErrDepartmentNotDeleted     = errors.New("department is not deleted")
+	ErrEmployeeNotDeleted       = errors.New("employee is not deleted")
+	ErrParentDeleted            = errors.New("parent department is deleted, restore it first")
+	ErrSnapshotNotFound         = errors.New("snapshot not found")
+	ErrDraftNotFound            = errors.New("draft not found")
+	ErrDraftOperationNotFound   = errors.New("draft operation not found")
+	ErrDraftNotOpen             = errors.New("draft is already applied or discarded")
+	ErrInvalidDraftOperation    = errors.New("invalid draft operation")
+	ErrCannotMergeIntoSelf      = errors.New("cannot merge department into itself")
+	ErrMergeTargetNotFound      = errors.New("target department for merge not found")
+	ErrMergeNameConflict        = errors.New("child department names conflict with the merge target")
+	ErrCloneTooLarge            = errors.New("department subtree is too large to clone")
+	ErrDepartmentNameTooLong    = errors.New("department name is too long")
+	ErrDelegationNotFound       = errors.New("delegation not found")
+	ErrDelegatorNotHead         = errors.New("delegator is not the head of the department")
+	ErrInvalidDelegationPeriod  = errors.New("delegation must not end before it starts or in the past")
+	ErrDelegationOverlap        = errors.New("delegation overlaps another delegation for the department")
+	ErrDelegationRevoked        = errors.New("delegation is already revoked")
+	ErrPositionNotFound         = errors.New("position not found")
+	ErrDuplicatePositionTitle   = errors.New("position with this title already exists")
+	ErrPositionInUse            = errors.New("position is assigned to employees or headcount slots")
+	ErrHeadcountSlotNotFound    = errors.New("headcount slot not found")
+	ErrHeadcountExhausted       = errors.New("department has no open headcount for the position")
 )
+
+// DraftOperationError - ошибка операции черновика с её позицией; бизнес-ошибка
+// операции доступна через errors.Is
+type DraftOperationError struct {
+	Position int
+	Type     string
+	Err      error
+}
+
+func (e *DraftOperationError) Error() string {
+	return fmt.Sprintf("operation %d (%s): %v", e.Position, e.Type, e.Err)
+}
+
+func (e *DraftOperationError) Unwrap() error {
+	return e.Err
+}
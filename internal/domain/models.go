@@ -1,7 +1,10 @@
 package domain
 
 import (
+	"encoding/json"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Department представляет подразделение организации
@@ -10,6 +13,10 @@ type Department struct {
 	Name      string    `json:"name" gorm:"type:varchar(200);not null"`
 	ParentID  *int64    `json:"parent_id" gorm:"index"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	// DeletedAt - время мягкого удаления; GORM автоматически исключает удалённые записи
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty"`
+	// HeadEmployeeID - руководитель подразделения; снимается при удалении сотрудника
+	HeadEmployeeID *int64 `json:"head_employee_id" gorm:"index"`
 
 	Parent    *Department  `json:"-" gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE"`
 	Children  []Department `json:"children,omitempty" gorm:"foreignKey:ParentID"`
@@ -21,6 +28,14 @@ func (Department) TableName() string {
 	return "departments"
 }
 
+// SameParent сравнивает ссылки на родителя, где nil означает корень
+func SameParent(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 // Employee представляет сотрудника
 type Employee struct {
 	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -29,6 +44,11 @@ type Employee struct {
 	Position     string     `json:"position" gorm:"type:varchar(200);not null"`
 	HiredAt      *time.Time `json:"hired_at" gorm:"type:date"`
 	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	// DeletedAt - время мягкого удаления; GORM автоматически исключает удалённые записи
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty"`
+	// PositionID - должность из справочника; Position хранится для совместимости
+	// и совпадает с её названием
+	PositionID *int64 `json:"position_id" gorm:"index"`
 
 	Department *Department `json:"-" gorm:"foreignKey:DepartmentID"`
 }
@@ -37,3 +57,321 @@ type Employee struct {
 func (Employee) TableName() string {
 	return "employees"
 }
+
+// Position - должность из справочника: название, профессиональное семейство и грейд
+type Position struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Title     string    `json:"title" gorm:"type:varchar(200);not null"`
+	JobFamily *string   `json:"job_family" gorm:"type:varchar(100);index"`
+	Grade     *int      `json:"grade"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName задаёт имя таблицы для GORM
+func (Position) TableName() string {
+	return "positions"
+}
+
+// HeadcountSlot - утверждённая численность должности в подразделении. Filled и
+// PositionTitle вычисляются при чтении: Filled - число сотрудников подразделения
+// на этой должности, оно может превышать Approved
+type HeadcountSlot struct {
+	ID            int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	DepartmentID  int64     `json:"department_id" gorm:"not null;index"`
+	PositionID    int64     `json:"position_id" gorm:"not null;index"`
+	Approved      int       `json:"approved" gorm:"not null"`
+	Filled        int       `json:"filled" gorm:"->"`
+	PositionTitle string    `json:"position_title" gorm:"->"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName задаёт имя таблицы для GORM
+func (HeadcountSlot) TableName() string {
+	return "headcount_slots"
+}
+
+// Open возвращает число вакантных мест
+func (s *HeadcountSlot) Open() int {
+	return max(s.Approved-s.Filled, 0)
+}
+
+// Vacancies - утверждённая численность поддерева подразделения с итогами
+type Vacancies struct {
+	DepartmentID int64
+	// Slots - места подразделения и всех его дочерних
+	Slots    []HeadcountSlot
+	Approved int
+	Filled   int
+	// Open - сумма вакантных мест; сверхштатные сотрудники одной должности
+	// не закрывают вакансии другой
+	Open int
+}
+
+// EmployeeTransfer - запись истории переводов сотрудника между подразделениями
+type EmployeeTransfer struct {
+	ID               int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	EmployeeID       int64     `json:"employee_id" gorm:"not null;index"`
+	FromDepartmentID *int64    `json:"from_department_id"`
+	ToDepartmentID   *int64    `json:"to_department_id"`
+	EffectiveDate    time.Time `json:"effective_date" gorm:"type:date;not null"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName задаёт имя таблицы для GORM
+func (EmployeeTransfer) TableName() string {
+	return "employee_transfers"
+}
+
+// DepartmentVersion - имя и родитель подразделения, действовавшие в интервале дат
+// [ValidFrom, ValidTo); ValidTo равен nil у действующей версии
+type DepartmentVersion struct {
+	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	DepartmentID int64      `json:"department_id" gorm:"not null;index"`
+	Name         string     `json:"name" gorm:"type:varchar(200);not null"`
+	ParentID     *int64     `json:"parent_id"`
+	ValidFrom    time.Time  `json:"valid_from" gorm:"type:date;not null"`
+	ValidTo      *time.Time `json:"valid_to" gorm:"type:date"`
+}
+
+// TableName задаёт имя таблицы для GORM
+func (DepartmentVersion) TableName() string {
+	return "department_versions"
+}
+
+// EmployeeAssignment - принадлежность сотрудника подразделению в интервале дат
+// [ValidFrom, ValidTo); ValidTo равен nil у действующего назначения
+type EmployeeAssignment struct {
+	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	EmployeeID   int64      `json:"employee_id" gorm:"not null;index"`
+	DepartmentID int64      `json:"department_id" gorm:"not null"`
+	ValidFrom    time.Time  `json:"valid_from" gorm:"type:date;not null"`
+	ValidTo      *time.Time `json:"valid_to" gorm:"type:date"`
+}
+
+// TableName задаёт имя таблицы для GORM
+func (EmployeeAssignment) TableName() string {
+	return "employee_assignments"
+}
+
+// Сущности и операции журнала аудита
+const (
+	AuditEntityDepartment = "department"
+	AuditEntityEmployee   = "employee"
+	AuditEntityDelegation = "delegation"
+	AuditEntityPosition   = "position"
+	AuditEntityHeadcount  = "headcount"
+
+	AuditOperationCreate   = "create"
+	AuditOperationUpdate   = "update"
+	AuditOperationDelete   = "delete"
+	AuditOperationReassign = "reassign"
+	AuditOperationTransfer = "transfer"
+	AuditOperationRestore  = "restore"
+	AuditOperationMerge    = "merge"
+	AuditOperationRevoke   = "revoke"
+	AuditOperationPurge    = "purge"
+)
+
+// AuditEntry - запись журнала аудита об изменении сущности
+type AuditEntry struct {
+	ID         int64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	OccurredAt time.Time       `json:"occurred_at" gorm:"autoCreateTime"`
+	Actor      string          `json:"actor" gorm:"type:varchar(200);not null"`
+	Entity     string          `json:"entity" gorm:"type:varchar(50);not null"`
+	EntityID   int64           `json:"entity_id" gorm:"not null"`
+	Operation  string          `json:"operation" gorm:"type:varchar(50);not null"`
+	Before     json.RawMessage `json:"before" gorm:"type:jsonb"`
+	After      json.RawMessage `json:"after" gorm:"type:jsonb"`
+	RequestID  string          `json:"request_id" gorm:"type:varchar(100);not null"`
+}
+
+// TableName задаёт имя таблицы для GORM
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+// Snapshot - неизменяемый снимок структуры организации. Снимки с одним именем
+// нумеруются версиями начиная с 1
+type Snapshot struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"type:varchar(200);not null"`
+	Version   int       `json:"version" gorm:"not null"`
+	CreatedBy string    `json:"created_by" gorm:"type:varchar(200);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName задаёт имя таблицы для GORM
+func (Snapshot) TableName() string {
+	return "snapshots"
+}
+
+// Состояния черновика реорганизации и виды его операций
+const (
+	DraftStatusOpen      = "open"
+	DraftStatusApplied   = "applied"
+	DraftStatusDiscarded = "discarded"
+
+	DraftOperationCreate   = "create"
+	DraftOperationRename   = "rename"
+	DraftOperationMove     = "move"
+	DraftOperationDelete   = "delete"
+	DraftOperationTransfer = "transfer"
+)
+
+// Draft - черновик реорганизации: набор операций, которые применяются вместе
+// в одной транзакции или отменяются
+type Draft struct {
+	ID        int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string     `json:"name" gorm:"type:varchar(200);not null"`
+	Status    string     `json:"status" gorm:"type:varchar(20);not null"`
+	CreatedBy string     `json:"created_by" gorm:"type:varchar(200);not null"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	ClosedAt  *time.Time `json:"closed_at"`
+
+	Operations []DraftOperation `json:"operations,omitempty" gorm:"foreignKey:DraftID"`
+}
+
+// TableName задаёт имя таблицы для GORM
+func (Draft) TableName() string {
+	return "reorg_drafts"
+}
+
+// DraftOperation - операция черновика. Подразделение, создаваемое операцией create
+// с меткой Ref, следующие операции указывают через поля *Ref вместо ID
+type DraftOperation struct {
+	ID       int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	DraftID  int64  `json:"draft_id" gorm:"not null"`
+	Position int    `json:"position" gorm:"not null"`
+	Type     string `json:"type" gorm:"type:varchar(20);not null"`
+	Ref      string `json:"ref,omitempty" gorm:"type:varchar(100)"`
+	// DepartmentID - изменяемое подразделение (rename, move, delete)
+	// или подразделение, куда переводится сотрудник (transfer)
+	DepartmentID  *int64 `json:"department_id"`
+	DepartmentRef string `json:"department_ref,omitempty" gorm:"type:varchar(100)"`
+	// ParentID - родитель создаваемого (create) или новый родитель перемещаемого (move) подразделения
+	ParentID      *int64 `json:"parent_id"`
+	ParentRef     string `json:"parent_ref,omitempty" gorm:"type:varchar(100)"`
+	Name          string `json:"name,omitempty" gorm:"type:varchar(200)"`
+	DeleteMode    string `json:"delete_mode,omitempty" gorm:"type:varchar(20)"`
+	ReassignToID  *int64 `json:"reassign_to_id"`
+	ReassignToRef string `json:"reassign_to_ref,omitempty" gorm:"type:varchar(100)"`
+	EmployeeID    *int64 `json:"employee_id"`
+}
+
+// TableName задаёт имя таблицы для GORM
+func (DraftOperation) TableName() string {
+	return "reorg_draft_operations"
+}
+
+// OrgState - плоское состояние организации на момент времени: подразделения
+// без вложенности и сотрудники с подразделением, в котором они состояли
+type OrgState struct {
+	Departments []Department
+	Employees   []Employee
+}
+
+// Виды изменений между двумя состояниями организации
+const (
+	ChangeDepartmentAdded   = "added"
+	ChangeDepartmentRemoved = "removed"
+	ChangeDepartmentRenamed = "renamed"
+	ChangeDepartmentMoved   = "moved"
+
+	ChangeEmployeeHired       = "hired"
+	ChangeEmployeeTransferred = "transferred"
+	ChangeEmployeeRemoved     = "removed"
+)
+
+// DepartmentChange - изменение подразделения; Before равен nil у добавленного,
+// After - у удалённого подразделения
+type DepartmentChange struct {
+	Type         string
+	DepartmentID int64
+	Before       *Department
+	After        *Department
+}
+
+// EmployeeChange - изменение принадлежности сотрудника подразделению;
+// FromDepartmentID равен nil у принятого, ToDepartmentID - у ушедшего сотрудника
+type EmployeeChange struct {
+	Type             string
+	EmployeeID       int64
+	FullName         string
+	FromDepartmentID *int64
+	ToDepartmentID   *int64
+}
+
+// OrgDiff - изменения структуры организации между двумя состояниями
+type OrgDiff struct {
+	Departments []DepartmentChange
+	Employees   []EmployeeChange
+}
+
+// Manager - руководитель подразделения в цепочке управления сотрудника
+type Manager struct {
+	Department Department
+	Employee   Employee
+	// Delegation - действующее делегирование, по которому Employee замещает руководителя
+	Delegation *Delegation
+}
+
+// Delegation - передача полномочий руководителя подразделения заместителю на период
+// с StartsOn по EndsOn включительно. Делегирование действует, пока не отозвано и
+// делегирующий остаётся руководителем подразделения
+type Delegation struct {
+	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	DepartmentID int64      `json:"department_id" gorm:"not null;index"`
+	DelegatorID  int64      `json:"delegator_id" gorm:"not null;index"`
+	DelegateID   int64      `json:"delegate_id" gorm:"not null;index"`
+	StartsOn     time.Time  `json:"starts_on" gorm:"type:date;not null"`
+	EndsOn       time.Time  `json:"ends_on" gorm:"type:date;not null"`
+	CreatedBy    string     `json:"created_by" gorm:"type:varchar(200);not null"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	RevokedBy    *string    `json:"revoked_by" gorm:"type:varchar(200)"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+}
+
+// TableName задаёт имя таблицы для GORM
+func (Delegation) TableName() string {
+	return "delegations"
+}
+
+// ActiveOn сообщает, действует ли неотозванное делегирование в дату date
+func (d *Delegation) ActiveOn(date time.Time) bool {
+	return d.RevokedAt == nil && !date.Before(d.StartsOn) && !date.After(d.EndsOn)
+}
+
+// Report - прямой или косвенный подчинённый руководителя
+type Report struct {
+	Employee Employee
+	// ManagerID - непосредственный руководитель подчинённого
+	ManagerID int64
+	// Depth - уровень подчинения: 1 - прямой подчинённый
+	Depth int
+}
+
+// SpanOfControl - число прямых подчинённых руководителя
+type SpanOfControl struct {
+	Manager       Employee
+	DirectReports int
+}
+
+// DepartmentImpact - последствия изменения подразделения, вычисленные без сохранения
+type DepartmentImpact struct {
+	// Department - подразделение после изменения; nil при удалении. При создании ID не резервируется
+	Department *Department
+	// AffectedDepartmentIDs - изменяемые подразделения и поддеревья, перемещаемые вместе с ними
+	AffectedDepartmentIDs []int64
+	// DeletedDepartmentIDs - подразделения, которые попадут в корзину
+	DeletedDepartmentIDs []int64
+	// ReparentedDepartments - дети удаляемого подразделения в новом положении и с новыми именами
+	ReparentedDepartments []Department
+	// ReassignToDepartmentID - подразделение, куда будут переведены сотрудники удаляемого
+	ReassignToDepartmentID *int64
+
+	MovedEmployees      int64
+	DeletedEmployees    int64
+	ReassignedEmployees int64
+}
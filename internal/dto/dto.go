@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -16,21 +17,68 @@ type UpdateDepartmentRequest struct {
 	ParentID *int64  `json:"parent_id" validate:"omitempty,min=1"`
 }
 
-// CreateEmployeeRequest - запрос на создание сотрудника
+// MergeDepartmentRequest - запрос на слияние подразделения с целевым. OnConflict задаёт
+// поведение при совпадении имён детей: fail (по умолчанию) или suffix
+type MergeDepartmentRequest struct {
+	TargetDepartmentID int64  `json:"target_department_id" validate:"required,min=1"`
+	OnConflict         string `json:"on_conflict" validate:"omitempty,oneof=fail suffix"`
+}
+
+// CloneDepartmentRequest - запрос на копирование поддерева подразделения под ParentID
+// (nil - в корень). Префикс и суффикс добавляются к имени копии корня поддерева.
+// Сотрудники не копируются; IncludeHeadcount копирует утверждённую численность
+type CloneDepartmentRequest struct {
+	ParentID         *int64 `json:"parent_id" validate:"omitempty,min=1"`
+	NamePrefix       string `json:"name_prefix" validate:"max=100"`
+	NameSuffix       string `json:"name_suffix" validate:"max=100"`
+	IncludeHeadcount bool   `json:"include_headcount"`
+}
+
+// SetDepartmentHeadRequest - запрос на назначение руководителя подразделения
+type SetDepartmentHeadRequest struct {
+	EmployeeID int64 `json:"employee_id" validate:"required,min=1"`
+}
+
+// CreateEmployeeRequest - запрос на создание сотрудника. Должность задаётся либо ID
+// из справочника, либо названием, которое ищется в справочнике без учёта регистра
 type CreateEmployeeRequest struct {
-	FullName string  `json:"full_name" validate:"required,min=1,max=200"`
-	Position string  `json:"position" validate:"required,min=1,max=200"`
-	HiredAt  *string `json:"hired_at" validate:"omitempty,datetime=2006-01-02"`
+	FullName   string  `json:"full_name" validate:"required,min=1,max=200"`
+	Position   string  `json:"position" validate:"required_without=PositionID,excluded_with=PositionID,omitempty,min=1,max=200"`
+	PositionID *int64  `json:"position_id" validate:"omitempty,min=1"`
+	HiredAt    *string `json:"hired_at" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// UpdateEmployeeRequest - запрос на частичное обновление сотрудника
+type UpdateEmployeeRequest struct {
+	FullName   *string `json:"full_name" validate:"omitempty,min=1,max=200"`
+	Position   *string `json:"position" validate:"excluded_with=PositionID,omitempty,min=1,max=200"`
+	PositionID *int64  `json:"position_id" validate:"omitempty,min=1"`
+	HiredAt    *string `json:"hired_at" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// TransferEmployeeRequest - запрос на перевод сотрудника в другое подразделение
+type TransferEmployeeRequest struct {
+	DepartmentID  int64   `json:"department_id" validate:"required,min=1"`
+	EffectiveDate *string `json:"effective_date" validate:"omitempty,datetime=2006-01-02"`
 }
 
 // DepartmentResponse - ответ с данными подразделения
 type DepartmentResponse struct {
-	ID        int64                 `json:"id"`
-	Name      string                `json:"name"`
-	ParentID  *int64                `json:"parent_id"`
-	CreatedAt time.Time             `json:"created_at"`
-	Employees []EmployeeResponse    `json:"employees,omitempty"`
-	Children  []DepartmentResponse  `json:"children,omitempty"`
+	ID             int64                `json:"id"`
+	Name           string               `json:"name"`
+	ParentID       *int64               `json:"parent_id"`
+	HeadEmployeeID *int64               `json:"head_employee_id"`
+	CreatedAt      time.Time            `json:"created_at"`
+	DeletedAt      *time.Time           `json:"deleted_at,omitempty"`
+	Path           []DepartmentPathItem `json:"path,omitempty"`
+	Employees      []EmployeeResponse   `json:"employees,omitempty"`
+	Children       []DepartmentResponse `json:"children,omitempty"`
+}
+
+// DepartmentPathItem - элемент цепочки предков подразделения
+type DepartmentPathItem struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
 }
 
 // EmployeeResponse - ответ с данными сотрудника
@@ -39,8 +87,225 @@ type EmployeeResponse struct {
 	DepartmentID int64      `json:"department_id"`
 	FullName     string     `json:"full_name"`
 	Position     string     `json:"position"`
+	PositionID   *int64     `json:"position_id"`
 	HiredAt      *string    `json:"hired_at,omitempty"`
 	CreatedAt    time.Time  `json:"created_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+}
+
+// TransferResponse - запись истории переводов сотрудника
+type TransferResponse struct {
+	ID               int64     `json:"id"`
+	EmployeeID       int64     `json:"employee_id"`
+	FromDepartmentID *int64    `json:"from_department_id"`
+	ToDepartmentID   *int64    `json:"to_department_id"`
+	EffectiveDate    string    `json:"effective_date"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// EmployeeListResponse - страница списка сотрудников
+type EmployeeListResponse struct {
+	Items      []EmployeeResponse `json:"items"`
+	NextCursor *string            `json:"next_cursor,omitempty"`
+}
+
+// DepartmentListResponse - страница списка подразделений
+type DepartmentListResponse struct {
+	Items      []DepartmentResponse `json:"items"`
+	NextCursor *string              `json:"next_cursor,omitempty"`
+}
+
+// AuditEntryResponse - запись журнала аудита
+type AuditEntryResponse struct {
+	ID         int64           `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      string          `json:"actor"`
+	Entity     string          `json:"entity"`
+	EntityID   int64           `json:"entity_id"`
+	Operation  string          `json:"operation"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+}
+
+// AuditListResponse - страница журнала аудита
+type AuditListResponse struct {
+	Items      []AuditEntryResponse `json:"items"`
+	NextCursor *string              `json:"next_cursor,omitempty"`
+}
+
+// DepartmentStateResponse - имя и родитель подразделения в одном из сравниваемых состояний
+type DepartmentStateResponse struct {
+	Name     string `json:"name"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+// DepartmentChangeResponse - изменение подразделения: added, removed, renamed или moved
+type DepartmentChangeResponse struct {
+	Type   string                   `json:"type"`
+	ID     int64                    `json:"id"`
+	Before *DepartmentStateResponse `json:"before"`
+	After  *DepartmentStateResponse `json:"after"`
+}
+
+// EmployeeChangeResponse - изменение сотрудника: hired, transferred или removed
+type EmployeeChangeResponse struct {
+	Type             string `json:"type"`
+	ID               int64  `json:"id"`
+	FullName         string `json:"full_name"`
+	FromDepartmentID *int64 `json:"from_department_id"`
+	ToDepartmentID   *int64 `json:"to_department_id"`
+}
+
+// SnapshotResponse - снимок структуры
+type SnapshotResponse struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SnapshotListResponse - страница списка снимков
+type SnapshotListResponse struct {
+	Items      []SnapshotResponse `json:"items"`
+	NextCursor *string            `json:"next_cursor,omitempty"`
+}
+
+// DraftOperationResponse - операция черновика реорганизации
+type DraftOperationResponse struct {
+	ID                     int64   `json:"id"`
+	Position               int     `json:"position"`
+	Type                   string  `json:"type"`
+	Ref                    *string `json:"ref,omitempty"`
+	DepartmentID           *int64  `json:"department_id,omitempty"`
+	DepartmentRef          *string `json:"department_ref,omitempty"`
+	ParentID               *int64  `json:"parent_id,omitempty"`
+	ParentRef              *string `json:"parent_ref,omitempty"`
+	Name                   *string `json:"name,omitempty"`
+	Mode                   *string `json:"mode,omitempty"`
+	ReassignToDepartmentID *int64  `json:"reassign_to_department_id,omitempty"`
+	ReassignToRef          *string `json:"reassign_to_ref,omitempty"`
+	EmployeeID             *int64  `json:"employee_id,omitempty"`
+}
+
+// DraftResponse - черновик реорганизации с операциями
+type DraftResponse struct {
+	ID         int64                    `json:"id"`
+	Name       string                   `json:"name"`
+	Status     string                   `json:"status"`
+	CreatedBy  string                   `json:"created_by"`
+	CreatedAt  time.Time                `json:"created_at"`
+	ClosedAt   *time.Time               `json:"closed_at,omitempty"`
+	Operations []DraftOperationResponse `json:"operations"`
+}
+
+// OrgDiffResponse - изменения структуры организации между двумя состояниями;
+// у каждой стороны заполнена либо дата, либо ID снимка
+type OrgDiffResponse struct {
+	From           *string                    `json:"from,omitempty"`
+	To             *string                    `json:"to,omitempty"`
+	FromSnapshotID *int64                     `json:"from_snapshot_id,omitempty"`
+	ToSnapshotID   *int64                     `json:"to_snapshot_id,omitempty"`
+	Departments    []DepartmentChangeResponse `json:"departments"`
+	Employees      []EmployeeChangeResponse   `json:"employees"`
+}
+
+// ManagerResponse - руководитель в цепочке управления сотрудника
+type ManagerResponse struct {
+	Department DepartmentPathItem `json:"department"`
+	Employee   EmployeeResponse   `json:"employee"`
+	// ActingForEmployeeID и DelegationID заданы, если Employee замещает руководителя
+	ActingForEmployeeID *int64 `json:"acting_for_employee_id,omitempty"`
+	DelegationID        *int64 `json:"delegation_id,omitempty"`
+}
+
+// DelegationResponse - делегирование полномочий руководителя подразделения
+type DelegationResponse struct {
+	ID           int64      `json:"id"`
+	DepartmentID int64      `json:"department_id"`
+	DelegatorID  int64      `json:"delegator_id"`
+	DelegateID   int64      `json:"delegate_id"`
+	StartsOn     string     `json:"starts_on"`
+	EndsOn       string     `json:"ends_on"`
+	CreatedBy    string     `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedBy    *string    `json:"revoked_by"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+}
+
+// PositionResponse - должность из справочника
+type PositionResponse struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	JobFamily *string   `json:"job_family"`
+	Grade     *int      `json:"grade"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PositionListResponse - страница справочника должностей
+type PositionListResponse struct {
+	Items      []PositionResponse `json:"items"`
+	NextCursor *string            `json:"next_cursor,omitempty"`
+}
+
+// HeadcountSlotResponse - утверждённые, занятые и вакантные места должности в подразделении
+type HeadcountSlotResponse struct {
+	DepartmentID  int64     `json:"department_id"`
+	PositionID    int64     `json:"position_id"`
+	PositionTitle string    `json:"position_title"`
+	Approved      int       `json:"approved"`
+	Filled        int       `json:"filled"`
+	Open          int       `json:"open"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// VacanciesResponse - места поддерева подразделения с итогами
+type VacanciesResponse struct {
+	DepartmentID int64                   `json:"department_id"`
+	Approved     int                     `json:"approved"`
+	Filled       int                     `json:"filled"`
+	Open         int                     `json:"open"`
+	Items        []HeadcountSlotResponse `json:"items"`
+}
+
+// DelegationListResponse - страница списка делегирований
+type DelegationListResponse struct {
+	Items      []DelegationResponse `json:"items"`
+	NextCursor *string              `json:"next_cursor,omitempty"`
+}
+
+// ReportResponse - подчинённый руководителя
+type ReportResponse struct {
+	Employee  EmployeeResponse `json:"employee"`
+	ManagerID int64            `json:"manager_id"`
+	Depth     int              `json:"depth"`
+}
+
+// SpanOfControlResponse - руководители, у которых прямых подчинённых больше порога
+type SpanOfControlResponse struct {
+	Threshold int                   `json:"threshold"`
+	Items     []ManagerSpanResponse `json:"items"`
+}
+
+// ManagerSpanResponse - руководитель и число его прямых подчинённых
+type ManagerSpanResponse struct {
+	Manager       EmployeeResponse `json:"manager"`
+	DirectReports int              `json:"direct_reports"`
+}
+
+// DepartmentImpactResponse - последствия изменения подразделения при dry_run=true
+type DepartmentImpactResponse struct {
+	DryRun                 bool                 `json:"dry_run"`
+	Department             *DepartmentResponse  `json:"department,omitempty"`
+	AffectedDepartmentIDs  []int64              `json:"affected_department_ids"`
+	DeletedDepartmentIDs   []int64              `json:"deleted_department_ids"`
+	ReparentedDepartments  []DepartmentResponse `json:"reparented_departments,omitempty"`
+	ReassignToDepartmentID *int64               `json:"reassign_to_department_id,omitempty"`
+	MovedEmployees         int64                `json:"moved_employees"`
+	DeletedEmployees       int64                `json:"deleted_employees"`
+	ReassignedEmployees    int64                `json:"reassigned_employees"`
 }
 
 // ErrorResponse - стандартный ответ с ошибкой
@@ -59,4 +324,172 @@ type DeleteDepartmentQuery struct {
 type GetDepartmentQuery struct {
 	Depth            int  `validate:"min=1,max=5"`
 	IncludeEmployees bool
+	IncludePath      bool
+	AsOf             *string `validate:"omitempty,datetime=2006-01-02"`
+}
+
+// AncestorsQuery - параметры запроса цепочки предков
+type AncestorsQuery struct {
+	AsOf *string `validate:"omitempty,datetime=2006-01-02"`
+}
+
+// GetEmployeeQuery - параметры запроса получения сотрудника
+type GetEmployeeQuery struct {
+	AsOf *string `validate:"omitempty,datetime=2006-01-02"`
+}
+
+// ListEmployeesQuery - параметры запроса списка сотрудников подразделения
+type ListEmployeesQuery struct {
+	Limit      int     `validate:"min=1,max=100"`
+	Cursor     string
+	Sort       string  `validate:"oneof=full_name position hired_at created_at"`
+	Order      string  `validate:"oneof=asc desc"`
+	Position   string  `validate:"max=200"`
+	PositionID *int64  `validate:"omitempty,min=1"`
+	HiredFrom  *string `validate:"omitempty,datetime=2006-01-02"`
+	HiredTo    *string `validate:"omitempty,datetime=2006-01-02"`
+	Recursive  bool
+	AsOf       *string `validate:"omitempty,datetime=2006-01-02"`
+}
+
+// ListDepartmentsQuery - параметры запроса списка корневых подразделений
+type ListDepartmentsQuery struct {
+	Limit  int    `validate:"min=1,max=100"`
+	Cursor string
+	Name   string `validate:"max=200"`
+}
+
+// OrgTreeQuery - параметры запроса дерева всей организации
+type OrgTreeQuery struct {
+	Depth            int `validate:"min=0,max=10"`
+	IncludeEmployees bool
+	AsOf             *string `validate:"omitempty,datetime=2006-01-02"`
+}
+
+// CreateDelegationRequest - запрос на делегирование полномочий руководителя подразделения
+type CreateDelegationRequest struct {
+	DepartmentID int64  `json:"department_id" validate:"required,min=1"`
+	DelegatorID  int64  `json:"delegator_id" validate:"required,min=1"`
+	DelegateID   int64  `json:"delegate_id" validate:"required,min=1,nefield=DelegatorID"`
+	StartsOn     string `json:"starts_on" validate:"required,datetime=2006-01-02"`
+	EndsOn       string `json:"ends_on" validate:"required,datetime=2006-01-02"`
+}
+
+// CreatePositionRequest - запрос на создание должности в справочнике
+type CreatePositionRequest struct {
+	Title     string  `json:"title" validate:"required,min=1,max=200"`
+	JobFamily *string `json:"job_family" validate:"omitempty,min=1,max=100"`
+	Grade     *int    `json:"grade" validate:"omitempty,min=1"`
+}
+
+// UpdatePositionRequest - запрос на частичное обновление должности
+type UpdatePositionRequest struct {
+	Title     *string `json:"title" validate:"omitempty,min=1,max=200"`
+	JobFamily *string `json:"job_family" validate:"omitempty,min=1,max=100"`
+	Grade     *int    `json:"grade" validate:"omitempty,min=1"`
+}
+
+// ListPositionsQuery - параметры запроса списка должностей
+type ListPositionsQuery struct {
+	Limit     int    `validate:"min=1,max=100"`
+	Cursor    string
+	Title     string `validate:"max=200"`
+	JobFamily string `validate:"max=100"`
+}
+
+// SetHeadcountSlotRequest - запрос на утверждение численности должности в подразделении
+type SetHeadcountSlotRequest struct {
+	Approved *int `json:"approved" validate:"required,min=0,max=100000"`
+}
+
+// ListDelegationsQuery - параметры запроса списка делегирований
+type ListDelegationsQuery struct {
+	Limit        int `validate:"min=1,max=100"`
+	Cursor       string
+	DepartmentID *int64  `validate:"omitempty,min=1"`
+	EmployeeID   *int64  `validate:"omitempty,min=1"`
+	ActiveOn     *string `validate:"omitempty,datetime=2006-01-02"`
+}
+
+// ReportsQuery - параметры запроса подчинённых сотрудника
+type ReportsQuery struct {
+	Recursive bool
+}
+
+// SpanOfControlQuery - параметры отчёта о числе подчинённых; без Threshold
+// используется порог из конфигурации
+type SpanOfControlQuery struct {
+	Threshold *int `validate:"omitempty,min=0"`
+}
+
+// OrgDiffQuery - параметры запроса изменений структуры; каждая сторона сравнения
+// задаётся либо датой, либо ID снимка
+type OrgDiffQuery struct {
+	From           *string `validate:"required_without=FromSnapshotID,excluded_with=FromSnapshotID,omitempty,datetime=2006-01-02"`
+	To             *string `validate:"required_without=ToSnapshotID,excluded_with=ToSnapshotID,omitempty,datetime=2006-01-02"`
+	FromSnapshotID *int64  `validate:"omitempty,min=1"`
+	ToSnapshotID   *int64  `validate:"omitempty,min=1"`
+}
+
+// CreateSnapshotRequest - запрос на создание снимка структуры
+type CreateSnapshotRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=200"`
+}
+
+// CreateDraftRequest - запрос на создание черновика реорганизации
+type CreateDraftRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=200"`
+}
+
+// DraftOperationRequest - операция черновика реорганизации. Набор обязательных полей
+// зависит от типа операции; вместо ID подразделения, создаваемого в этом же
+// черновике, передаётся его метка ref в поле *_ref
+type DraftOperationRequest struct {
+	Type                   string  `json:"type" validate:"required,oneof=create rename move delete transfer"`
+	Ref                    string  `json:"ref" validate:"max=100"`
+	DepartmentID           *int64  `json:"department_id" validate:"omitempty,min=1"`
+	DepartmentRef          string  `json:"department_ref" validate:"max=100"`
+	ParentID               *int64  `json:"parent_id" validate:"omitempty,min=1"`
+	ParentRef              string  `json:"parent_ref" validate:"max=100"`
+	Name                   *string `json:"name" validate:"omitempty,min=1,max=200"`
+	Mode                   string  `json:"mode" validate:"omitempty,oneof=cascade reassign"`
+	ReassignToDepartmentID *int64  `json:"reassign_to_department_id" validate:"omitempty,min=1"`
+	ReassignToRef          string  `json:"reassign_to_ref" validate:"max=100"`
+	EmployeeID             *int64  `json:"employee_id" validate:"omitempty,min=1"`
+}
+
+// DraftPreviewQuery - параметры предпросмотра дерева после применения черновика
+type DraftPreviewQuery struct {
+	Depth            int `validate:"min=0,max=10"`
+	IncludeEmployees bool
+}
+
+// ListSnapshotsQuery - параметры запроса списка снимков
+type ListSnapshotsQuery struct {
+	Limit  int `validate:"min=1,max=100"`
+	Cursor string
+	Name   string
+}
+
+// SnapshotTreeQuery - параметры запроса дерева снимка
+type SnapshotTreeQuery struct {
+	Depth            int `validate:"min=0,max=10"`
+	IncludeEmployees bool
+}
+
+// ListTrashQuery - параметры запроса списка удалённых записей
+type ListTrashQuery struct {
+	Limit  int `validate:"min=1,max=100"`
+	Cursor string
+}
+
+// ListAuditQuery - параметры запроса журнала аудита. From и To задают
+// полуинтервал [from, to) в формате RFC 3339
+type ListAuditQuery struct {
+	Limit    int     `validate:"min=1,max=100"`
+	Cursor   string
+	Entity   string  `validate:"required_with=EntityID,omitempty,oneof=department employee delegation position headcount"`
+	EntityID *int64  `validate:"omitempty,min=1"`
+	From     *string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To       *string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
 }
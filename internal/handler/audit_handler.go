@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+type AuditHandler struct {
+	baseHandler
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		baseHandler:  newBaseHandler(logger),
+		auditService: auditService,
+	}
+}
+
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	query, err := h.parseListQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	entries, nextCursor, err := h.auditService.List(r.Context(), &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.AuditListResponse{
+		Items: make([]dto.AuditEntryResponse, len(entries)),
+	}
+	for i, entry := range entries {
+		resp.Items[i] = h.toAuditEntryResponse(&entry)
+	}
+	if nextCursor != "" {
+		resp.NextCursor = &nextCursor
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *AuditHandler) parseListQuery(r *http.Request) (dto.ListAuditQuery, error) {
+	values := r.URL.Query()
+	query := dto.ListAuditQuery{
+		Limit:  20,
+		Cursor: values.Get("cursor"),
+		Entity: values.Get("entity"),
+	}
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit: %w", err)
+		}
+		query.Limit = limit
+	}
+
+	if idStr := values.Get("id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid id: %w", err)
+		}
+		query.EntityID = &id
+	}
+
+	if from := values.Get("from"); from != "" {
+		query.From = &from
+	}
+
+	if to := values.Get("to"); to != "" {
+		query.To = &to
+	}
+
+	return query, nil
+}
+
+func (h *AuditHandler) toAuditEntryResponse(entry *domain.AuditEntry) dto.AuditEntryResponse {
+	return dto.AuditEntryResponse{
+		ID:         entry.ID,
+		OccurredAt: entry.OccurredAt,
+		Actor:      entry.Actor,
+		Entity:     entry.Entity,
+		EntityID:   entry.EntityID,
+		Operation:  entry.Operation,
+		Before:     entry.Before,
+		After:      entry.After,
+		RequestID:  entry.RequestID,
+	}
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+)
+
+// baseHandler содержит общие для всех хендлеров зависимости и вспомогательные методы
+type baseHandler struct {
+	validator *validator.Validate
+	logger    *slog.Logger
+}
+
+func newBaseHandler(logger *slog.Logger) baseHandler {
+	return baseHandler{
+		validator: validator.New(),
+		logger:    logger,
+	}
+}
+
+// extractPathID извлекает ID из первого сегмента пути после prefix
+func extractPathID(r *http.Request, prefix string) (int64, error) {
+	path := strings.TrimPrefix(r.URL.Path, prefix)
+	path = strings.Trim(path, "/")
+
+	parts := strings.Split(path, "/")
+	if len(parts) == 0 || parts[0] == "" {
+		return 0, errors.New("id is required")
+	}
+
+	return strconv.ParseInt(parts[0], 10, 64)
+}
+
+// optionalQueryParam возвращает значение параметра запроса или nil, если он не передан
+func optionalQueryParam(r *http.Request, name string) *string {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func (h *baseHandler) toEmployeeResponse(emp *domain.Employee) dto.EmployeeResponse {
+	resp := dto.EmployeeResponse{
+		ID:           emp.ID,
+		DepartmentID: emp.DepartmentID,
+		FullName:     emp.FullName,
+		Position:     emp.Position,
+		PositionID:   emp.PositionID,
+		CreatedAt:    emp.CreatedAt,
+	}
+
+	if emp.HiredAt != nil {
+		hiredAt := emp.HiredAt.Format("2006-01-02")
+		resp.HiredAt = &hiredAt
+	}
+
+	if emp.DeletedAt.Valid {
+		resp.DeletedAt = &emp.DeletedAt.Time
+	}
+
+	return resp
+}
+
+func (h *baseHandler) toDepartmentResponse(dept *domain.Department) dto.DepartmentResponse {
+	resp := dto.DepartmentResponse{
+		ID:             dept.ID,
+		Name:           dept.Name,
+		ParentID:       dept.ParentID,
+		CreatedAt:      dept.CreatedAt,
+		HeadEmployeeID: dept.HeadEmployeeID,
+	}
+
+	if dept.DeletedAt.Valid {
+		resp.DeletedAt = &dept.DeletedAt.Time
+	}
+
+	return resp
+}
+
+func (h *baseHandler) toDepartmentResponseWithChildren(dept *domain.Department, includeEmployees bool) dto.DepartmentResponse {
+	resp := dto.DepartmentResponse{
+		ID:             dept.ID,
+		Name:           dept.Name,
+		ParentID:       dept.ParentID,
+		CreatedAt:      dept.CreatedAt,
+		HeadEmployeeID: dept.HeadEmployeeID,
+	}
+
+	if includeEmployees && len(dept.Employees) > 0 {
+		resp.Employees = make([]dto.EmployeeResponse, len(dept.Employees))
+		for i, emp := range dept.Employees {
+			resp.Employees[i] = h.toEmployeeResponse(&emp)
+		}
+	}
+
+	if len(dept.Children) > 0 {
+		resp.Children = make([]dto.DepartmentResponse, len(dept.Children))
+		for i, child := range dept.Children {
+			resp.Children[i] = h.toDepartmentResponseWithChildren(&child, includeEmployees)
+		}
+	}
+
+	return resp
+}
+
+func (h *baseHandler) handleServiceError(w http.ResponseWriter, err error) {
+	// Для ошибок операций черновика сообщаем, какая операция не прошла
+	var details string
+	var opErr *domain.DraftOperationError
+	if errors.As(err, &opErr) {
+		details = fmt.Sprintf("operation %d (%s)", opErr.Position, opErr.Type)
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrDepartmentNotFound):
+		h.respondError(w, http.StatusNotFound, "department not found", details)
+	case errors.Is(err, domain.ErrEmployeeNotFound):
+		h.respondError(w, http.StatusNotFound, "employee not found", details)
+	case errors.Is(err, domain.ErrDuplicateDepartmentName):
+		h.respondError(w, http.StatusConflict, "department with this name already exists", details)
+	case errors.Is(err, domain.ErrSelfReference):
+		h.respondError(w, http.StatusBadRequest, "department cannot be its own parent", details)
+	case errors.Is(err, domain.ErrCyclicReference):
+		h.respondError(w, http.StatusConflict, "moving department would create a cycle", details)
+	case errors.Is(err, domain.ErrInvalidDeleteMode):
+		h.respondError(w, http.StatusBadRequest, "invalid delete mode, use 'cascade' or 'reassign'", details)
+	case errors.Is(err, domain.ErrReassignTargetRequired):
+		h.respondError(w, http.StatusBadRequest, "reassign_to_department_id is required when mode is reassign", details)
+	case errors.Is(err, domain.ErrReassignTargetNotFound):
+		h.respondError(w, http.StatusNotFound, "target department for reassignment not found", details)
+	case errors.Is(err, domain.ErrCannotReassignToSelf):
+		h.respondError(w, http.StatusBadRequest, "cannot reassign to the same department being deleted", details)
+	case errors.Is(err, domain.ErrTransferToSameDepartment):
+		h.respondError(w, http.StatusBadRequest, "employee already belongs to the target department", details)
+	case errors.Is(err, domain.ErrFutureEffectiveDate):
+		h.respondError(w, http.StatusBadRequest, "effective date cannot be in the future", details)
+	case errors.Is(err, domain.ErrEffectiveDateTooEarly):
+		h.respondError(w, http.StatusConflict, "effective date is earlier than the latest recorded change", details)
+	case errors.Is(err, domain.ErrInvalidCursor):
+		h.respondError(w, http.StatusBadRequest, "invalid pagination cursor", details)
+	case errors.Is(err, domain.ErrDepartmentNotDeleted):
+		h.respondError(w, http.StatusConflict, "department is not deleted", details)
+	case errors.Is(err, domain.ErrEmployeeNotDeleted):
+		h.respondError(w, http.StatusConflict, "employee is not deleted", details)
+	case errors.Is(err, domain.ErrParentDeleted):
+		h.respondError(w, http.StatusConflict, "parent department is deleted, restore it first", details)
+	case errors.Is(err, domain.ErrSnapshotNotFound):
+		h.respondError(w, http.StatusNotFound, "snapshot not found", details)
+	case errors.Is(err, domain.ErrDraftNotFound):
+		h.respondError(w, http.StatusNotFound, "draft not found", details)
+	case errors.Is(err, domain.ErrDraftOperationNotFound):
+		h.respondError(w, http.StatusNotFound, "draft operation not found", details)
+	case errors.Is(err, domain.ErrDraftNotOpen):
+		h.respondError(w, http.StatusConflict, "draft is already applied or discarded", details)
+	case errors.Is(err, domain.ErrInvalidDraftOperation):
+		h.respondError(w, http.StatusBadRequest, "invalid draft operation", err.Error())
+	case errors.Is(err, domain.ErrCannotMergeIntoSelf):
+		h.respondError(w, http.StatusBadRequest, "cannot merge department into itself", details)
+	case errors.Is(err, domain.ErrMergeTargetNotFound):
+		h.respondError(w, http.StatusNotFound, "target department for merge not found", details)
+	case errors.Is(err, domain.ErrMergeNameConflict):
+		h.respondError(w, http.StatusConflict, "child department names conflict with the merge target", err.Error())
+	case errors.Is(err, domain.ErrCloneTooLarge):
+		h.respondError(w, http.StatusBadRequest, "department subtree is too large to clone", err.Error())
+	case errors.Is(err, domain.ErrDepartmentNameTooLong):
+		h.respondError(w, http.StatusBadRequest, "department name is too long", details)
+	case errors.Is(err, domain.ErrDelegationNotFound):
+		h.respondError(w, http.StatusNotFound, "delegation not found", details)
+	case errors.Is(err, domain.ErrDelegatorNotHead):
+		h.respondError(w, http.StatusConflict, "delegator is not the head of the department", details)
+	case errors.Is(err, domain.ErrInvalidDelegationPeriod):
+		h.respondError(w, http.StatusBadRequest, "delegation must not end before it starts or in the past", details)
+	case errors.Is(err, domain.ErrDelegationOverlap):
+		h.respondError(w, http.StatusConflict, "delegation overlaps another delegation for the department", details)
+	case errors.Is(err, domain.ErrDelegationRevoked):
+		h.respondError(w, http.StatusConflict, "delegation is already revoked", details)
+	case errors.Is(err, domain.ErrPositionNotFound):
+		h.respondError(w, http.StatusNotFound, "position not found", details)
+	case errors.Is(err, domain.ErrDuplicatePositionTitle):
+		h.respondError(w, http.StatusConflict, "position with this title already exists", details)
+	case errors.Is(err, domain.ErrPositionInUse):
+		h.respondError(w, http.StatusConflict, "position is assigned to employees or headcount slots", details)
+	case errors.Is(err, domain.ErrHeadcountSlotNotFound):
+		h.respondError(w, http.StatusNotFound, "headcount slot not found", details)
+	case errors.Is(err, domain.ErrHeadcountExhausted):
+		h.respondError(w, http.StatusConflict, "department has no open headcount for the position", details)
+	default:
+		h.logger.Error("internal error", slog.Any("error", err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "")
+	}
+}
+
+func (h *baseHandler) respondJSON(w http.ResponseWriter, status int, data any) {
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", slog.Any("error", err))
+	}
+}
+
+func (h *baseHandler) respondError(w http.ResponseWriter, status int, errMsg, details string) {
+	w.WriteHeader(status)
+	resp := dto.ErrorResponse{Error: errMsg}
+	if details != "" {
+		resp.Message = details
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode error response", slog.Any("error", err))
+	}
+}
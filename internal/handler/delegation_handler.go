@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+type DelegationHandler struct {
+	baseHandler
+	delegationService service.DelegationService
+}
+
+func NewDelegationHandler(delegationService service.DelegationService, logger *slog.Logger) *DelegationHandler {
+	return &DelegationHandler{
+		baseHandler:       newBaseHandler(logger),
+		delegationService: delegationService,
+	}
+}
+
+func (h *DelegationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateDelegationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	delegation, err := h.delegationService.Create(r.Context(), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, h.toDelegationResponse(delegation))
+}
+
+func (h *DelegationHandler) List(w http.ResponseWriter, r *http.Request) {
+	query, err := h.parseListQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	delegations, nextCursor, err := h.delegationService.List(r.Context(), &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.DelegationListResponse{
+		Items: make([]dto.DelegationResponse, len(delegations)),
+	}
+	for i, delegation := range delegations {
+		resp.Items[i] = h.toDelegationResponse(&delegation)
+	}
+	if nextCursor != "" {
+		resp.NextCursor = &nextCursor
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DelegationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid delegation id", err.Error())
+		return
+	}
+
+	delegation, err := h.delegationService.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toDelegationResponse(delegation))
+}
+
+func (h *DelegationHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid delegation id", err.Error())
+		return
+	}
+
+	delegation, err := h.delegationService.Revoke(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toDelegationResponse(delegation))
+}
+
+func (h *DelegationHandler) extractID(r *http.Request) (int64, error) {
+	return extractPathID(r, "/delegations/")
+}
+
+func (h *DelegationHandler) parseListQuery(r *http.Request) (dto.ListDelegationsQuery, error) {
+	values := r.URL.Query()
+	query := dto.ListDelegationsQuery{
+		Limit:    20,
+		Cursor:   values.Get("cursor"),
+		ActiveOn: optionalQueryParam(r, "active_on"),
+	}
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit: %w", err)
+		}
+		query.Limit = limit
+	}
+
+	if idStr := values.Get("department_id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid department_id: %w", err)
+		}
+		query.DepartmentID = &id
+	}
+
+	if idStr := values.Get("employee_id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid employee_id: %w", err)
+		}
+		query.EmployeeID = &id
+	}
+
+	return query, nil
+}
+
+func (h *DelegationHandler) toDelegationResponse(delegation *domain.Delegation) dto.DelegationResponse {
+	return dto.DelegationResponse{
+		ID:           delegation.ID,
+		DepartmentID: delegation.DepartmentID,
+		DelegatorID:  delegation.DelegatorID,
+		DelegateID:   delegation.DelegateID,
+		StartsOn:     delegation.StartsOn.Format("2006-01-02"),
+		EndsOn:       delegation.EndsOn.Format("2006-01-02"),
+		CreatedBy:    delegation.CreatedBy,
+		CreatedAt:    delegation.CreatedAt,
+		RevokedBy:    delegation.RevokedBy,
+		RevokedAt:    delegation.RevokedAt,
+	}
+}
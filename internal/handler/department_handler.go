@@ -2,23 +2,20 @@ package handler
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
-	"strings"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/org-structure-api/internal/domain"
 	"github.com/org-structure-api/internal/dto"
 	"github.com/org-structure-api/internal/service"
 )
 
 type DepartmentHandler struct {
+	baseHandler
 	deptService service.DepartmentService
 	empService  service.EmployeeService
-	validator   *validator.Validate
-	logger      *slog.Logger
 }
 
 func NewDepartmentHandler(
@@ -27,10 +24,9 @@ func NewDepartmentHandler(
 	logger *slog.Logger,
 ) *DepartmentHandler {
 	return &DepartmentHandler{
+		baseHandler: newBaseHandler(logger),
 		deptService: deptService,
 		empService:  empService,
-		validator:   validator.New(),
-		logger:      logger,
 	}
 }
 
@@ -46,6 +42,12 @@ func (h *DepartmentHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if isDryRun(r) {
+		impact, err := h.deptService.CreateDryRun(r.Context(), &req)
+		h.respondImpact(w, impact, true, err)
+		return
+	}
+
 	dept, err := h.deptService.Create(r.Context(), &req)
 	if err != nil {
 		h.handleServiceError(w, err)
@@ -74,7 +76,97 @@ func (h *DepartmentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.respondJSON(w, http.StatusOK, h.toDepartmentResponseWithChildren(dept, query.IncludeEmployees))
+	resp := h.toDepartmentResponseWithChildren(dept, query.IncludeEmployees)
+
+	if query.IncludePath {
+		ancestors, err := h.deptService.GetAncestors(r.Context(), id, &dto.AncestorsQuery{AsOf: query.AsOf})
+		if err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		resp.Path = h.toPath(ancestors)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DepartmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	query, err := h.parseListQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	departments, nextCursor, err := h.deptService.ListRoots(r.Context(), &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.DepartmentListResponse{
+		Items: make([]dto.DepartmentResponse, len(departments)),
+	}
+	for i, dept := range departments {
+		resp.Items[i] = h.toDepartmentResponse(&dept)
+	}
+	if nextCursor != "" {
+		resp.NextCursor = &nextCursor
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DepartmentHandler) GetOrgTree(w http.ResponseWriter, r *http.Request) {
+	query, err := h.parseOrgTreeQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	forest, err := h.deptService.GetOrgTree(r.Context(), &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := make([]dto.DepartmentResponse, len(forest))
+	for i, dept := range forest {
+		resp[i] = h.toDepartmentResponseWithChildren(&dept, query.IncludeEmployees)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DepartmentHandler) GetAncestors(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid department id", err.Error())
+		return
+	}
+
+	query := dto.AncestorsQuery{AsOf: optionalQueryParam(r, "as_of")}
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	ancestors, err := h.deptService.GetAncestors(r.Context(), id, &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toPath(ancestors))
 }
 
 func (h *DepartmentHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -95,6 +187,12 @@ func (h *DepartmentHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if isDryRun(r) {
+		impact, err := h.deptService.UpdateDryRun(r.Context(), id, &req)
+		h.respondImpact(w, impact, true, err)
+		return
+	}
+
 	dept, err := h.deptService.Update(r.Context(), id, &req)
 	if err != nil {
 		h.handleServiceError(w, err)
@@ -117,6 +215,12 @@ func (h *DepartmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if isDryRun(r) {
+		impact, err := h.deptService.DeleteDryRun(r.Context(), id, &query)
+		h.respondImpact(w, impact, true, err)
+		return
+	}
+
 	if err := h.deptService.Delete(r.Context(), id, &query); err != nil {
 		h.handleServiceError(w, err)
 		return
@@ -125,6 +229,120 @@ func (h *DepartmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *DepartmentHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid department id", err.Error())
+		return
+	}
+
+	dept, err := h.deptService.Restore(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toDepartmentResponse(dept))
+}
+
+func (h *DepartmentHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid department id", err.Error())
+		return
+	}
+
+	var req dto.MergeDepartmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	if isDryRun(r) {
+		impact, err := h.deptService.MergeDryRun(r.Context(), id, &req)
+		h.respondImpact(w, impact, true, err)
+		return
+	}
+
+	impact, err := h.deptService.Merge(r.Context(), id, &req)
+	h.respondImpact(w, impact, false, err)
+}
+
+func (h *DepartmentHandler) Clone(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid department id", err.Error())
+		return
+	}
+
+	var req dto.CloneDepartmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	dept, err := h.deptService.Clone(r.Context(), id, &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, h.toDepartmentResponseWithChildren(dept, false))
+}
+
+func (h *DepartmentHandler) SetHead(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid department id", err.Error())
+		return
+	}
+
+	var req dto.SetDepartmentHeadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	dept, err := h.deptService.SetHead(r.Context(), id, &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toDepartmentResponse(dept))
+}
+
+func (h *DepartmentHandler) RemoveHead(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid department id", err.Error())
+		return
+	}
+
+	dept, err := h.deptService.RemoveHead(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toDepartmentResponse(dept))
+}
+
 func (h *DepartmentHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
 	deptID, err := h.extractID(r)
 	if err != nil {
@@ -152,17 +370,84 @@ func (h *DepartmentHandler) CreateEmployee(w http.ResponseWriter, r *http.Reques
 	h.respondJSON(w, http.StatusCreated, h.toEmployeeResponse(emp))
 }
 
-func (h *DepartmentHandler) extractID(r *http.Request) (int64, error) {
-	path := strings.TrimPrefix(r.URL.Path, "/departments/")
-	path = strings.TrimSuffix(path, "/")
-	path = strings.TrimSuffix(path, "/employees")
+func (h *DepartmentHandler) ListEmployees(w http.ResponseWriter, r *http.Request) {
+	deptID, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid department id", err.Error())
+		return
+	}
 
-	parts := strings.Split(path, "/")
-	if len(parts) == 0 || parts[0] == "" {
-		return 0, errors.New("id is required")
+	query, err := h.parseListEmployeesQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
 	}
 
-	return strconv.ParseInt(parts[0], 10, 64)
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	employees, nextCursor, err := h.empService.ListByDepartment(r.Context(), deptID, &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.EmployeeListResponse{
+		Items: make([]dto.EmployeeResponse, len(employees)),
+	}
+	for i, emp := range employees {
+		resp.Items[i] = h.toEmployeeResponse(&emp)
+	}
+	if nextCursor != "" {
+		resp.NextCursor = &nextCursor
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// isDryRun сообщает, запрошена ли только проверка изменения (dry_run=true)
+func isDryRun(r *http.Request) bool {
+	return r.URL.Query().Get("dry_run") == "true"
+}
+
+// respondImpact отвечает последствиями изменения: вычисленными в режиме dry_run
+// или уже применёнными, если dryRun = false
+func (h *DepartmentHandler) respondImpact(w http.ResponseWriter, impact *domain.DepartmentImpact, dryRun bool, err error) {
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.DepartmentImpactResponse{
+		DryRun:                 dryRun,
+		AffectedDepartmentIDs:  impact.AffectedDepartmentIDs,
+		DeletedDepartmentIDs:   impact.DeletedDepartmentIDs,
+		ReassignToDepartmentID: impact.ReassignToDepartmentID,
+		MovedEmployees:         impact.MovedEmployees,
+		DeletedEmployees:       impact.DeletedEmployees,
+		ReassignedEmployees:    impact.ReassignedEmployees,
+	}
+	if resp.AffectedDepartmentIDs == nil {
+		resp.AffectedDepartmentIDs = []int64{}
+	}
+	if resp.DeletedDepartmentIDs == nil {
+		resp.DeletedDepartmentIDs = []int64{}
+	}
+	if impact.Department != nil {
+		dept := h.toDepartmentResponse(impact.Department)
+		resp.Department = &dept
+	}
+	for _, dept := range impact.ReparentedDepartments {
+		resp.ReparentedDepartments = append(resp.ReparentedDepartments, h.toDepartmentResponse(&dept))
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DepartmentHandler) extractID(r *http.Request) (int64, error) {
+	return extractPathID(r, "/departments/")
 }
 
 func (h *DepartmentHandler) parseGetQuery(r *http.Request) dto.GetDepartmentQuery {
@@ -181,9 +466,51 @@ func (h *DepartmentHandler) parseGetQuery(r *http.Request) dto.GetDepartmentQuer
 		query.IncludeEmployees = includeStr == "true"
 	}
 
+	query.IncludePath = r.URL.Query().Get("include_path") == "true"
+	query.AsOf = optionalQueryParam(r, "as_of")
+
 	return query
 }
 
+func (h *DepartmentHandler) parseListQuery(r *http.Request) (dto.ListDepartmentsQuery, error) {
+	values := r.URL.Query()
+	query := dto.ListDepartmentsQuery{
+		Limit:  20,
+		Cursor: values.Get("cursor"),
+		Name:   values.Get("name"),
+	}
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit: %w", err)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+func (h *DepartmentHandler) parseOrgTreeQuery(r *http.Request) (dto.OrgTreeQuery, error) {
+	values := r.URL.Query()
+	query := dto.OrgTreeQuery{
+		Depth: 5,
+	}
+
+	if depthStr := values.Get("depth"); depthStr != "" {
+		depth, err := strconv.Atoi(depthStr)
+		if err != nil {
+			return query, fmt.Errorf("invalid depth: %w", err)
+		}
+		query.Depth = depth
+	}
+
+	query.IncludeEmployees = values.Get("include_employees") == "true"
+	query.AsOf = optionalQueryParam(r, "as_of")
+
+	return query, nil
+}
+
 func (h *DepartmentHandler) parseDeleteQuery(r *http.Request) dto.DeleteDepartmentQuery {
 	query := dto.DeleteDepartmentQuery{
 		Mode: r.URL.Query().Get("mode"),
@@ -198,97 +525,58 @@ func (h *DepartmentHandler) parseDeleteQuery(r *http.Request) dto.DeleteDepartme
 	return query
 }
 
-func (h *DepartmentHandler) toDepartmentResponse(dept *domain.Department) dto.DepartmentResponse {
-	return dto.DepartmentResponse{
-		ID:        dept.ID,
-		Name:      dept.Name,
-		ParentID:  dept.ParentID,
-		CreatedAt: dept.CreatedAt,
-	}
-}
-
-func (h *DepartmentHandler) toDepartmentResponseWithChildren(dept *domain.Department, includeEmployees bool) dto.DepartmentResponse {
-	resp := dto.DepartmentResponse{
-		ID:        dept.ID,
-		Name:      dept.Name,
-		ParentID:  dept.ParentID,
-		CreatedAt: dept.CreatedAt,
+func (h *DepartmentHandler) parseListEmployeesQuery(r *http.Request) (dto.ListEmployeesQuery, error) {
+	values := r.URL.Query()
+	query := dto.ListEmployeesQuery{
+		Limit:    20,
+		Cursor:   values.Get("cursor"),
+		Sort:     "created_at",
+		Order:    "asc",
+		Position: values.Get("position"),
 	}
 
-	if includeEmployees && len(dept.Employees) > 0 {
-		resp.Employees = make([]dto.EmployeeResponse, len(dept.Employees))
-		for i, emp := range dept.Employees {
-			resp.Employees[i] = h.toEmployeeResponse(&emp)
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit: %w", err)
 		}
+		query.Limit = limit
 	}
 
-	if len(dept.Children) > 0 {
-		resp.Children = make([]dto.DepartmentResponse, len(dept.Children))
-		for i, child := range dept.Children {
-			resp.Children[i] = h.toDepartmentResponseWithChildren(&child, includeEmployees)
-		}
+	if sort := values.Get("sort"); sort != "" {
+		query.Sort = sort
 	}
 
-	return resp
-}
-
-func (h *DepartmentHandler) toEmployeeResponse(emp *domain.Employee) dto.EmployeeResponse {
-	resp := dto.EmployeeResponse{
-		ID:           emp.ID,
-		DepartmentID: emp.DepartmentID,
-		FullName:     emp.FullName,
-		Position:     emp.Position,
-		CreatedAt:    emp.CreatedAt,
+	if order := values.Get("order"); order != "" {
+		query.Order = order
 	}
 
-	if emp.HiredAt != nil {
-		hiredAt := emp.HiredAt.Format("2006-01-02")
-		resp.HiredAt = &hiredAt
+	if idStr := values.Get("position_id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid position_id: %w", err)
+		}
+		query.PositionID = &id
 	}
 
-	return resp
-}
-
-func (h *DepartmentHandler) handleServiceError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, domain.ErrDepartmentNotFound):
-		h.respondError(w, http.StatusNotFound, "department not found", "")
-	case errors.Is(err, domain.ErrEmployeeNotFound):
-		h.respondError(w, http.StatusNotFound, "employee not found", "")
-	case errors.Is(err, domain.ErrDuplicateDepartmentName):
-		h.respondError(w, http.StatusConflict, "department with this name already exists", "")
-	case errors.Is(err, domain.ErrSelfReference):
-		h.respondError(w, http.StatusBadRequest, "department cannot be its own parent", "")
-	case errors.Is(err, domain.ErrCyclicReference):
-		h.respondError(w, http.StatusConflict, "moving department would create a cycle", "")
-	case errors.Is(err, domain.ErrInvalidDeleteMode):
-		h.respondError(w, http.StatusBadRequest, "invalid delete mode, use 'cascade' or 'reassign'", "")
-	case errors.Is(err, domain.ErrReassignTargetRequired):
-		h.respondError(w, http.StatusBadRequest, "reassign_to_department_id is required when mode is reassign", "")
-	case errors.Is(err, domain.ErrReassignTargetNotFound):
-		h.respondError(w, http.StatusNotFound, "target department for reassignment not found", "")
-	case errors.Is(err, domain.ErrCannotReassignToSelf):
-		h.respondError(w, http.StatusBadRequest, "cannot reassign to the same department being deleted", "")
-	default:
-		h.logger.Error("internal error", slog.Any("error", err))
-		h.respondError(w, http.StatusInternalServerError, "internal server error", "")
+	if hiredFrom := values.Get("hired_from"); hiredFrom != "" {
+		query.HiredFrom = &hiredFrom
 	}
-}
 
-func (h *DepartmentHandler) respondJSON(w http.ResponseWriter, status int, data any) {
-	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		h.logger.Error("failed to encode response", slog.Any("error", err))
+	if hiredTo := values.Get("hired_to"); hiredTo != "" {
+		query.HiredTo = &hiredTo
 	}
+
+	query.Recursive = values.Get("recursive") == "true"
+	query.AsOf = optionalQueryParam(r, "as_of")
+
+	return query, nil
 }
 
-func (h *DepartmentHandler) respondError(w http.ResponseWriter, status int, errMsg, details string) {
-	w.WriteHeader(status)
-	resp := dto.ErrorResponse{Error: errMsg}
-	if details != "" {
-		resp.Message = details
-	}
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		h.logger.Error("failed to encode error response", slog.Any("error", err))
+func (h *DepartmentHandler) toPath(chain []domain.Department) []dto.DepartmentPathItem {
+	path := make([]dto.DepartmentPathItem, len(chain))
+	for i, dept := range chain {
+		path[i] = dto.DepartmentPathItem{ID: dept.ID, Name: dept.Name}
 	}
+	return path
 }
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+type DiffHandler struct {
+	baseHandler
+	diffService service.DiffService
+}
+
+func NewDiffHandler(diffService service.DiffService, logger *slog.Logger) *DiffHandler {
+	return &DiffHandler{
+		baseHandler: newBaseHandler(logger),
+		diffService: diffService,
+	}
+}
+
+func (h *DiffHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	query, err := h.parseDiffQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	diff, err := h.diffService.Diff(r.Context(), &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.OrgDiffResponse{
+		From:           query.From,
+		To:             query.To,
+		FromSnapshotID: query.FromSnapshotID,
+		ToSnapshotID:   query.ToSnapshotID,
+		Departments:    make([]dto.DepartmentChangeResponse, len(diff.Departments)),
+		Employees:      make([]dto.EmployeeChangeResponse, len(diff.Employees)),
+	}
+	for i, change := range diff.Departments {
+		resp.Departments[i] = dto.DepartmentChangeResponse{
+			Type:   change.Type,
+			ID:     change.DepartmentID,
+			Before: toDepartmentStateResponse(change.Before),
+			After:  toDepartmentStateResponse(change.After),
+		}
+	}
+	for i, change := range diff.Employees {
+		resp.Employees[i] = dto.EmployeeChangeResponse{
+			Type:             change.Type,
+			ID:               change.EmployeeID,
+			FullName:         change.FullName,
+			FromDepartmentID: change.FromDepartmentID,
+			ToDepartmentID:   change.ToDepartmentID,
+		}
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DiffHandler) parseDiffQuery(r *http.Request) (dto.OrgDiffQuery, error) {
+	query := dto.OrgDiffQuery{
+		From: optionalQueryParam(r, "from"),
+		To:   optionalQueryParam(r, "to"),
+	}
+
+	if idStr := r.URL.Query().Get("from_snapshot_id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid from_snapshot_id: %w", err)
+		}
+		query.FromSnapshotID = &id
+	}
+
+	if idStr := r.URL.Query().Get("to_snapshot_id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid to_snapshot_id: %w", err)
+		}
+		query.ToSnapshotID = &id
+	}
+
+	return query, nil
+}
+
+func toDepartmentStateResponse(dept *domain.Department) *dto.DepartmentStateResponse {
+	if dept == nil {
+		return nil
+	}
+	return &dto.DepartmentStateResponse{Name: dept.Name, ParentID: dept.ParentID}
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+type DraftHandler struct {
+	baseHandler
+	draftService service.DraftService
+}
+
+func NewDraftHandler(draftService service.DraftService, logger *slog.Logger) *DraftHandler {
+	return &DraftHandler{
+		baseHandler:  newBaseHandler(logger),
+		draftService: draftService,
+	}
+}
+
+func (h *DraftHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	draft, err := h.draftService.Create(r.Context(), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, h.toDraftResponse(draft))
+}
+
+func (h *DraftHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid draft id", err.Error())
+		return
+	}
+
+	draft, err := h.draftService.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toDraftResponse(draft))
+}
+
+func (h *DraftHandler) AddOperation(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid draft id", err.Error())
+		return
+	}
+
+	var req dto.DraftOperationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	op, err := h.draftService.AddOperation(r.Context(), id, &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, h.toDraftOperationResponse(op))
+}
+
+func (h *DraftHandler) RemoveOperation(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid draft id", err.Error())
+		return
+	}
+
+	// /drafts/{id}/operations/{operationID}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	opID, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid operation id", err.Error())
+		return
+	}
+
+	if err := h.draftService.RemoveOperation(r.Context(), id, opID); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *DraftHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid draft id", err.Error())
+		return
+	}
+
+	query, err := h.parsePreviewQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	forest, err := h.draftService.Preview(r.Context(), id, &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := make([]dto.DepartmentResponse, len(forest))
+	for i, dept := range forest {
+		resp[i] = h.toDepartmentResponseWithChildren(&dept, query.IncludeEmployees)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DraftHandler) Apply(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid draft id", err.Error())
+		return
+	}
+
+	draft, err := h.draftService.Apply(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toDraftResponse(draft))
+}
+
+func (h *DraftHandler) Discard(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid draft id", err.Error())
+		return
+	}
+
+	draft, err := h.draftService.Discard(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toDraftResponse(draft))
+}
+
+func (h *DraftHandler) extractID(r *http.Request) (int64, error) {
+	return extractPathID(r, "/drafts/")
+}
+
+func (h *DraftHandler) parsePreviewQuery(r *http.Request) (dto.DraftPreviewQuery, error) {
+	values := r.URL.Query()
+	query := dto.DraftPreviewQuery{
+		Depth: 5,
+	}
+
+	if depthStr := values.Get("depth"); depthStr != "" {
+		depth, err := strconv.Atoi(depthStr)
+		if err != nil {
+			return query, fmt.Errorf("invalid depth: %w", err)
+		}
+		query.Depth = depth
+	}
+
+	query.IncludeEmployees = values.Get("include_employees") == "true"
+
+	return query, nil
+}
+
+func (h *DraftHandler) toDraftResponse(draft *domain.Draft) dto.DraftResponse {
+	resp := dto.DraftResponse{
+		ID:         draft.ID,
+		Name:       draft.Name,
+		Status:     draft.Status,
+		CreatedBy:  draft.CreatedBy,
+		CreatedAt:  draft.CreatedAt,
+		ClosedAt:   draft.ClosedAt,
+		Operations: make([]dto.DraftOperationResponse, len(draft.Operations)),
+	}
+	for i, op := range draft.Operations {
+		resp.Operations[i] = h.toDraftOperationResponse(&op)
+	}
+	return resp
+}
+
+func (h *DraftHandler) toDraftOperationResponse(op *domain.DraftOperation) dto.DraftOperationResponse {
+	// nonEmpty превращает пустые строковые поля в отсутствующие в ответе
+	nonEmpty := func(value string) *string {
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+
+	return dto.DraftOperationResponse{
+		ID:                     op.ID,
+		Position:               op.Position,
+		Type:                   op.Type,
+		Ref:                    nonEmpty(op.Ref),
+		DepartmentID:           op.DepartmentID,
+		DepartmentRef:          nonEmpty(op.DepartmentRef),
+		ParentID:               op.ParentID,
+		ParentRef:              nonEmpty(op.ParentRef),
+		Name:                   nonEmpty(op.Name),
+		Mode:                   nonEmpty(op.DeleteMode),
+		ReassignToDepartmentID: op.ReassignToID,
+		ReassignToRef:          nonEmpty(op.ReassignToRef),
+		EmployeeID:             op.EmployeeID,
+	}
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+type EmployeeHandler struct {
+	baseHandler
+	empService service.EmployeeService
+}
+
+func NewEmployeeHandler(empService service.EmployeeService, logger *slog.Logger) *EmployeeHandler {
+	return &EmployeeHandler{
+		baseHandler: newBaseHandler(logger),
+		empService:  empService,
+	}
+}
+
+func (h *EmployeeHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid employee id", err.Error())
+		return
+	}
+
+	query := dto.GetEmployeeQuery{AsOf: optionalQueryParam(r, "as_of")}
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	emp, err := h.empService.GetByID(r.Context(), id, &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toEmployeeResponse(emp))
+}
+
+func (h *EmployeeHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid employee id", err.Error())
+		return
+	}
+
+	var req dto.UpdateEmployeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	emp, err := h.empService.Update(r.Context(), id, &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toEmployeeResponse(emp))
+}
+
+func (h *EmployeeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid employee id", err.Error())
+		return
+	}
+
+	if err := h.empService.Delete(r.Context(), id); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *EmployeeHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid employee id", err.Error())
+		return
+	}
+
+	emp, err := h.empService.Restore(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toEmployeeResponse(emp))
+}
+
+func (h *EmployeeHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid employee id", err.Error())
+		return
+	}
+
+	var req dto.TransferEmployeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	emp, err := h.empService.Transfer(r.Context(), id, &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toEmployeeResponse(emp))
+}
+
+func (h *EmployeeHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid employee id", err.Error())
+		return
+	}
+
+	transfers, err := h.empService.GetTransferHistory(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := make([]dto.TransferResponse, len(transfers))
+	for i, transfer := range transfers {
+		resp[i] = h.toTransferResponse(&transfer)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *EmployeeHandler) extractID(r *http.Request) (int64, error) {
+	return extractPathID(r, "/employees/")
+}
+
+func (h *EmployeeHandler) toTransferResponse(transfer *domain.EmployeeTransfer) dto.TransferResponse {
+	return dto.TransferResponse{
+		ID:               transfer.ID,
+		EmployeeID:       transfer.EmployeeID,
+		FromDepartmentID: transfer.FromDepartmentID,
+		ToDepartmentID:   transfer.ToDepartmentID,
+		EffectiveDate:    transfer.EffectiveDate.Format("2006-01-02"),
+		CreatedAt:        transfer.CreatedAt,
+	}
+}
@@ -0,0 +1,451 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/org-structure-api/internal/dto"
+)
+
+func TestGetEmployee_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+		"hired_at":  "2024-01-15",
+	})
+
+	resp, err := http.Get(ts.server.URL + "/employees/1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result dto.EmployeeResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if result.FullName != "John Doe" || result.DepartmentID != 1 {
+		t.Errorf("unexpected employee: %+v", result)
+	}
+	if result.HiredAt == nil || *result.HiredAt != "2024-01-15" {
+		t.Errorf("expected hired_at '2024-01-15', got %v", result.HiredAt)
+	}
+}
+
+func TestGetEmployee_NotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.server.URL + "/employees/999")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestGetEmployee_InvalidID(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.server.URL + "/employees/abc")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestUpdateEmployee_PartialUpdate(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+	})
+
+	resp, err := patchJSON(ts.server.URL+"/employees/1", map[string]any{
+		"position": "Senior Developer",
+		"hired_at": "2023-03-01",
+	})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result dto.EmployeeResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if result.FullName != "John Doe" {
+		t.Errorf("expected full_name to be unchanged, got '%s'", result.FullName)
+	}
+	if result.Position != "Senior Developer" {
+		t.Errorf("expected 'Senior Developer', got '%s'", result.Position)
+	}
+	if result.HiredAt == nil || *result.HiredAt != "2023-03-01" {
+		t.Errorf("expected hired_at '2023-03-01', got %v", result.HiredAt)
+	}
+}
+
+func TestUpdateEmployee_ValidationError(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+	})
+
+	cases := []map[string]any{
+		{"full_name": ""},
+		{"position": ""},
+		{"hired_at": "15.01.2024"},
+	}
+
+	for _, body := range cases {
+		resp, err := patchJSON(ts.server.URL+"/employees/1", body)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%v: expected %d, got %d", body, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestUpdateEmployee_NotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := patchJSON(ts.server.URL+"/employees/999", map[string]any{"full_name": "Test"})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestDeleteEmployee_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+	})
+
+	resp, err := deleteRequest(ts.server.URL + "/employees/1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	if _, ok := ts.empRepo.employees[1]; ok {
+		t.Error("expected employee to be deleted")
+	}
+}
+
+func TestRestoreEmployee_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+	})
+	resp, _ := deleteRequest(ts.server.URL + "/employees/1")
+	resp.Body.Close()
+
+	resp, err := http.Get(ts.server.URL + "/trash/employees")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var trash dto.EmployeeListResponse
+	json.NewDecoder(resp.Body).Decode(&trash)
+	if len(trash.Items) != 1 || trash.Items[0].DeletedAt == nil {
+		t.Fatalf("expected deleted employee in trash, got %+v", trash.Items)
+	}
+
+	resp, err = http.Post(ts.server.URL+"/employees/1/restore", "application/json", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if _, ok := ts.empRepo.employees[1]; !ok {
+		t.Error("expected employee to be restored")
+	}
+
+	resp, err = http.Post(ts.server.URL+"/employees/1/restore", "application/json", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected %d for a live employee, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestDeleteEmployee_NotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := deleteRequest(ts.server.URL + "/employees/999")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestTransferEmployee_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "HR"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+	})
+
+	resp, err := postJSON(ts.server.URL+"/employees/1/transfer", map[string]any{
+		"department_id":  2,
+		"effective_date": "2024-06-01",
+	})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var emp dto.EmployeeResponse
+	json.NewDecoder(resp.Body).Decode(&emp)
+	if emp.DepartmentID != 2 {
+		t.Errorf("expected department_id 2, got %d", emp.DepartmentID)
+	}
+
+	resp, err = http.Get(ts.server.URL + "/employees/1/history")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var history []dto.TransferResponse
+	json.NewDecoder(resp.Body).Decode(&history)
+	if len(history) != 1 {
+		t.Fatalf("expected 1 transfer, got %d", len(history))
+	}
+	if *history[0].FromDepartmentID != 1 || *history[0].ToDepartmentID != 2 || history[0].EffectiveDate != "2024-06-01" {
+		t.Errorf("unexpected transfer: %+v", history[0])
+	}
+}
+
+func TestTransferEmployee_SameDepartment(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+	})
+
+	resp, err := postJSON(ts.server.URL+"/employees/1/transfer", map[string]any{"department_id": 1})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestTransferEmployee_TargetNotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+	})
+
+	resp, err := postJSON(ts.server.URL+"/employees/1/transfer", map[string]any{"department_id": 999})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestTransferEmployee_ValidationError(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := postJSON(ts.server.URL+"/employees/1/transfer", map[string]any{
+		"department_id":  2,
+		"effective_date": "01.06.2024",
+	})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestGetEmployeeHistory_NotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.server.URL + "/employees/999/history")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestListDepartmentEmployees_Pagination(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	for _, name := range []string{"Alice", "Bob", "Carol"} {
+		mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{
+			"full_name": name,
+			"position":  "Developer",
+		})
+	}
+
+	resp, err := http.Get(ts.server.URL + "/departments/1/employees?limit=2&sort=full_name")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var page dto.EmployeeListResponse
+	json.NewDecoder(resp.Body).Decode(&page)
+	if len(page.Items) != 2 || page.NextCursor == nil {
+		t.Fatalf("expected 2 items and a next cursor, got %d items and %v", len(page.Items), page.NextCursor)
+	}
+
+	resp, err = http.Get(ts.server.URL + "/departments/1/employees?limit=2&sort=full_name&cursor=" + *page.NextCursor)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	page = dto.EmployeeListResponse{}
+	json.NewDecoder(resp.Body).Decode(&page)
+	if len(page.Items) != 1 || page.NextCursor != nil {
+		t.Errorf("expected the last page with 1 item, got %d items and cursor %v", len(page.Items), page.NextCursor)
+	}
+	if len(page.Items) == 1 && page.Items[0].FullName != "Carol" {
+		t.Errorf("expected 'Carol', got '%s'", page.Items[0].FullName)
+	}
+}
+
+func TestListDepartmentEmployees_InvalidQuery(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+
+	queries := []string{
+		"limit=0",
+		"limit=abc",
+		"limit=1000",
+		"sort=salary",
+		"order=up",
+		"hired_from=2024/01/01",
+		"cursor=broken",
+	}
+
+	for _, query := range queries {
+		resp, err := http.Get(ts.server.URL + "/departments/1/employees?" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected %d, got %d", query, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestListDepartmentEmployees_DepartmentNotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.server.URL + "/departments/999/employees")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
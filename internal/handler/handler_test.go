@@ -8,23 +8,29 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/org-structure-api/internal/domain"
 	"github.com/org-structure-api/internal/dto"
 	"github.com/org-structure-api/internal/handler"
+	"github.com/org-structure-api/internal/service"
+	"gorm.io/gorm"
 )
 
 type mockDepartmentRepo struct {
 	departments map[int64]*domain.Department
+	deleted     map[int64]*domain.Department
 	nextID      int64
 }
 
 func newMockDepartmentRepo() *mockDepartmentRepo {
 	return &mockDepartmentRepo{
 		departments: make(map[int64]*domain.Department),
+		deleted:     make(map[int64]*domain.Department),
 		nextID:      1,
 	}
 }
@@ -54,9 +60,12 @@ func (m *mockDepartmentRepo) Update(ctx context.Context, dept *domain.Department
 }
 
 func (m *mockDepartmentRepo) Delete(ctx context.Context, id int64) error {
-	if _, ok := m.departments[id]; !ok {
+	dept, ok := m.departments[id]
+	if !ok {
 		return domain.ErrDepartmentNotFound
 	}
+	dept.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	m.deleted[id] = dept
 	delete(m.departments, id)
 	return nil
 }
@@ -109,14 +118,72 @@ func (m *mockDepartmentRepo) GetAllDescendantIDs(ctx context.Context, id int64)
 	return result, nil
 }
 
+func (m *mockDepartmentRepo) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Department, error) {
+	return m.GetByID(ctx, id)
+}
+
+func (m *mockDepartmentRepo) GetChildren(ctx context.Context, parentID int64) ([]domain.Department, error) {
+	var result []domain.Department
+	for _, dept := range m.departments {
+		if dept.ParentID != nil && *dept.ParentID == parentID {
+			result = append(result, *dept)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDepartmentRepo) DetachChildren(ctx context.Context, parentID int64) error {
+	for _, dept := range m.departments {
+		if dept.ParentID != nil && *dept.ParentID == parentID {
+			dept.ParentID = nil
+		}
+	}
+	return nil
+}
+
+func (m *mockDepartmentRepo) LockForMove(ctx context.Context, id, newParentID int64) error {
+	return nil
+}
+
+func (m *mockDepartmentRepo) LockName(ctx context.Context, name string, parentID *int64) error {
+	return nil
+}
+
+func (m *mockDepartmentRepo) GetAncestorIDs(ctx context.Context, id int64) ([]int64, error) {
+	var result []int64
+	for dept, ok := m.departments[id]; ok; {
+		result = append(result, dept.ID)
+		if dept.ParentID == nil {
+			break
+		}
+		dept, ok = m.departments[*dept.ParentID]
+	}
+	return result, nil
+}
+
+func (m *mockDepartmentRepo) GetAncestors(ctx context.Context, id int64) ([]domain.Department, error) {
+	ids, _ := m.GetAncestorIDs(ctx, id)
+	if len(ids) == 0 {
+		return nil, domain.ErrDepartmentNotFound
+	}
+
+	chain := make([]domain.Department, len(ids))
+	for i, ancestorID := range ids {
+		chain[len(ids)-1-i] = *m.departments[ancestorID]
+	}
+	return chain, nil
+}
+
 type mockEmployeeRepo struct {
 	employees map[int64]*domain.Employee
+	deleted   map[int64]*domain.Employee
 	nextID    int64
 }
 
 func newMockEmployeeRepo() *mockEmployeeRepo {
 	return &mockEmployeeRepo{
 		employees: make(map[int64]*domain.Employee),
+		deleted:   make(map[int64]*domain.Employee),
 		nextID:    1,
 	}
 }
@@ -152,11 +219,21 @@ func (m *mockEmployeeRepo) Update(ctx context.Context, emp *domain.Employee) err
 }
 
 func (m *mockEmployeeRepo) Delete(ctx context.Context, id int64) error {
+	emp, ok := m.employees[id]
+	if !ok {
+		return domain.ErrEmployeeNotFound
+	}
+	emp.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	m.deleted[id] = emp
 	delete(m.employees, id)
 	return nil
 }
 
-func (m *mockEmployeeRepo) ReassignToDepartment(ctx context.Context, fromDeptID, toDeptID int64) error {
+func (m *mockEmployeeRepo) Transfer(ctx context.Context, emp *domain.Employee, effectiveDate time.Time) error {
+	return m.Update(ctx, emp)
+}
+
+func (m *mockEmployeeRepo) ReassignToDepartment(ctx context.Context, fromDeptID, toDeptID int64, effectiveDate time.Time) error {
 	for _, emp := range m.employees {
 		if emp.DepartmentID == fromDeptID {
 			emp.DepartmentID = toDeptID
@@ -168,6 +245,11 @@ func (m *mockEmployeeRepo) ReassignToDepartment(ctx context.Context, fromDeptID,
 type mockDepartmentService struct {
 	deptRepo *mockDepartmentRepo
 	empRepo  *mockEmployeeRepo
+
+	// asOfRequests - значения as_of, переданные в методы чтения
+	asOfRequests []*string
+	// impact - результат методов dry-run
+	impact *domain.DepartmentImpact
 }
 
 func (s *mockDepartmentService) Create(ctx context.Context, req *dto.CreateDepartmentRequest) (*domain.Department, error) {
@@ -191,6 +273,7 @@ func (s *mockDepartmentService) Create(ctx context.Context, req *dto.CreateDepar
 }
 
 func (s *mockDepartmentService) GetByID(ctx context.Context, id int64, query *dto.GetDepartmentQuery) (*domain.Department, error) {
+	s.asOfRequests = append(s.asOfRequests, query.AsOf)
 	return s.deptRepo.GetByID(ctx, id)
 }
 
@@ -259,15 +342,164 @@ func (s *mockDepartmentService) Delete(ctx context.Context, id int64, query *dto
 			return domain.ErrReassignTargetNotFound
 		}
 
-		s.empRepo.ReassignToDepartment(ctx, id, targetID)
+		s.empRepo.ReassignToDepartment(ctx, id, targetID, time.Now())
 	}
 
 	return s.deptRepo.Delete(ctx, id)
 }
 
+func (s *mockDepartmentService) CreateDryRun(ctx context.Context, req *dto.CreateDepartmentRequest) (*domain.DepartmentImpact, error) {
+	return s.impact, nil
+}
+
+func (s *mockDepartmentService) UpdateDryRun(ctx context.Context, id int64, req *dto.UpdateDepartmentRequest) (*domain.DepartmentImpact, error) {
+	if _, err := s.deptRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.impact, nil
+}
+
+func (s *mockDepartmentService) DeleteDryRun(ctx context.Context, id int64, query *dto.DeleteDepartmentQuery) (*domain.DepartmentImpact, error) {
+	if _, err := s.deptRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.impact, nil
+}
+
+func (s *mockDepartmentService) Merge(ctx context.Context, id int64, req *dto.MergeDepartmentRequest) (*domain.DepartmentImpact, error) {
+	impact, err := s.MergeDryRun(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	s.empRepo.ReassignToDepartment(ctx, id, req.TargetDepartmentID, time.Now())
+	return impact, s.deptRepo.Delete(ctx, id)
+}
+
+func (s *mockDepartmentService) MergeDryRun(ctx context.Context, id int64, req *dto.MergeDepartmentRequest) (*domain.DepartmentImpact, error) {
+	if req.TargetDepartmentID == id {
+		return nil, domain.ErrCannotMergeIntoSelf
+	}
+	if _, err := s.deptRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	target, err := s.deptRepo.GetByID(ctx, req.TargetDepartmentID)
+	if err != nil {
+		return nil, domain.ErrMergeTargetNotFound
+	}
+	return &domain.DepartmentImpact{
+		Department:             target,
+		AffectedDepartmentIDs:  []int64{id, target.ID},
+		DeletedDepartmentIDs:   []int64{id},
+		ReassignToDepartmentID: &target.ID,
+	}, nil
+}
+
+func (s *mockDepartmentService) Clone(ctx context.Context, id int64, req *dto.CloneDepartmentRequest) (*domain.Department, error) {
+	source, err := s.deptRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Create(ctx, &dto.CreateDepartmentRequest{Name: req.NamePrefix + source.Name + req.NameSuffix, ParentID: req.ParentID})
+}
+
+func (s *mockDepartmentService) SetHead(ctx context.Context, id int64, req *dto.SetDepartmentHeadRequest) (*domain.Department, error) {
+	dept, err := s.deptRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.empRepo.GetByID(ctx, req.EmployeeID); err != nil {
+		return nil, err
+	}
+	dept.HeadEmployeeID = &req.EmployeeID
+	return dept, nil
+}
+
+func (s *mockDepartmentService) RemoveHead(ctx context.Context, id int64) (*domain.Department, error) {
+	dept, err := s.deptRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dept.HeadEmployeeID = nil
+	return dept, nil
+}
+
+func (s *mockDepartmentService) Restore(ctx context.Context, id int64) (*domain.Department, error) {
+	if _, ok := s.deptRepo.departments[id]; ok {
+		return nil, domain.ErrDepartmentNotDeleted
+	}
+	dept, ok := s.deptRepo.deleted[id]
+	if !ok {
+		return nil, domain.ErrDepartmentNotFound
+	}
+	if dept.ParentID != nil {
+		if _, err := s.deptRepo.GetByID(ctx, *dept.ParentID); err != nil {
+			return nil, domain.ErrParentDeleted
+		}
+	}
+
+	dept.DeletedAt = gorm.DeletedAt{}
+	s.deptRepo.departments[id] = dept
+	delete(s.deptRepo.deleted, id)
+	return dept, nil
+}
+
+func (s *mockDepartmentService) GetAncestors(ctx context.Context, id int64, query *dto.AncestorsQuery) ([]domain.Department, error) {
+	s.asOfRequests = append(s.asOfRequests, query.AsOf)
+	return s.deptRepo.GetAncestors(ctx, id)
+}
+
+func (s *mockDepartmentService) ListRoots(ctx context.Context, query *dto.ListDepartmentsQuery) ([]domain.Department, string, error) {
+	var after int64
+	if query.Cursor != "" {
+		var err error
+		if after, err = strconv.ParseInt(query.Cursor, 10, 64); err != nil {
+			return nil, "", domain.ErrInvalidCursor
+		}
+	}
+
+	var result []domain.Department
+	for id := after + 1; id < s.deptRepo.nextID; id++ {
+		dept, ok := s.deptRepo.departments[id]
+		if !ok || dept.ParentID != nil || !strings.Contains(strings.ToLower(dept.Name), strings.ToLower(query.Name)) {
+			continue
+		}
+		if len(result) == query.Limit {
+			return result, strconv.FormatInt(result[len(result)-1].ID, 10), nil
+		}
+		result = append(result, *dept)
+	}
+	return result, "", nil
+}
+
+func (s *mockDepartmentService) GetOrgTree(ctx context.Context, query *dto.OrgTreeQuery) ([]domain.Department, error) {
+	s.asOfRequests = append(s.asOfRequests, query.AsOf)
+	var build func(dept domain.Department, depth int) domain.Department
+	build = func(dept domain.Department, depth int) domain.Department {
+		if depth == 0 {
+			return dept
+		}
+		for id := int64(1); id < s.deptRepo.nextID; id++ {
+			child, ok := s.deptRepo.departments[id]
+			if ok && child.ParentID != nil && *child.ParentID == dept.ID {
+				dept.Children = append(dept.Children, build(*child, depth-1))
+			}
+		}
+		return dept
+	}
+
+	var forest []domain.Department
+	for id := int64(1); id < s.deptRepo.nextID; id++ {
+		if dept, ok := s.deptRepo.departments[id]; ok && dept.ParentID == nil {
+			forest = append(forest, build(*dept, query.Depth))
+		}
+	}
+	return forest, nil
+}
+
 type mockEmployeeService struct {
-	empRepo  *mockEmployeeRepo
-	deptRepo *mockDepartmentRepo
+	empRepo   *mockEmployeeRepo
+	deptRepo  *mockDepartmentRepo
+	transfers []domain.EmployeeTransfer
 }
 
 func (s *mockEmployeeService) Create(ctx context.Context, departmentID int64, req *dto.CreateEmployeeRequest) (*domain.Employee, error) {
@@ -279,6 +511,7 @@ func (s *mockEmployeeService) Create(ctx context.Context, departmentID int64, re
 		DepartmentID: departmentID,
 		FullName:     req.FullName,
 		Position:     req.Position,
+		PositionID:   req.PositionID,
 	}
 
 	if req.HiredAt != nil {
@@ -293,7 +526,7 @@ func (s *mockEmployeeService) Create(ctx context.Context, departmentID int64, re
 	return emp, nil
 }
 
-func (s *mockEmployeeService) GetByID(ctx context.Context, id int64) (*domain.Employee, error) {
+func (s *mockEmployeeService) GetByID(ctx context.Context, id int64, query *dto.GetEmployeeQuery) (*domain.Employee, error) {
 	return s.empRepo.GetByID(ctx, id)
 }
 
@@ -301,152 +534,754 @@ func (s *mockEmployeeService) GetByDepartmentID(ctx context.Context, departmentI
 	return s.empRepo.GetByDepartmentID(ctx, departmentID)
 }
 
-type testServer struct {
-	server   *httptest.Server
-	deptRepo *mockDepartmentRepo
-	empRepo  *mockEmployeeRepo
-}
-
-func setupTestServer(_ *testing.T) *testServer {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-
-	deptRepo := newMockDepartmentRepo()
-	empRepo := newMockEmployeeRepo()
-
-	deptService := &mockDepartmentService{deptRepo: deptRepo, empRepo: empRepo}
-	empService := &mockEmployeeService{empRepo: empRepo, deptRepo: deptRepo}
-
-	deptHandler := handler.NewDepartmentHandler(deptService, empService, logger)
-	router := handler.NewRouter(deptHandler, logger)
+func (s *mockEmployeeService) Update(ctx context.Context, id int64, req *dto.UpdateEmployeeRequest) (*domain.Employee, error) {
+	emp, err := s.empRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-	return &testServer{
-		server:   httptest.NewServer(router.Setup()),
-		deptRepo: deptRepo,
-		empRepo:  empRepo,
+	if req.FullName != nil {
+		emp.FullName = *req.FullName
+	}
+	if req.Position != nil {
+		emp.Position = *req.Position
+	}
+	if req.HiredAt != nil {
+		hiredAt, err := time.Parse("2006-01-02", *req.HiredAt)
+		if err != nil {
+			return nil, err
+		}
+		emp.HiredAt = &hiredAt
 	}
+
+	s.empRepo.Update(ctx, emp)
+	return emp, nil
 }
 
-func (ts *testServer) Close() {
-	ts.server.Close()
+func (s *mockEmployeeService) Delete(ctx context.Context, id int64) error {
+	return s.empRepo.Delete(ctx, id)
 }
 
-func postJSON(url string, body map[string]any) (*http.Response, error) {
-	data, _ := json.Marshal(body)
-	return http.Post(url, "application/json", bytes.NewBuffer(data))
+func (s *mockEmployeeService) Restore(ctx context.Context, id int64) (*domain.Employee, error) {
+	if _, ok := s.empRepo.employees[id]; ok {
+		return nil, domain.ErrEmployeeNotDeleted
+	}
+	emp, ok := s.empRepo.deleted[id]
+	if !ok {
+		return nil, domain.ErrEmployeeNotFound
+	}
+
+	emp.DeletedAt = gorm.DeletedAt{}
+	s.empRepo.employees[id] = emp
+	delete(s.empRepo.deleted, id)
+	return emp, nil
 }
 
-func patchJSON(url string, body map[string]any) (*http.Response, error) {
-	data, _ := json.Marshal(body)
-	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(data))
+func (s *mockEmployeeService) Transfer(ctx context.Context, id int64, req *dto.TransferEmployeeRequest) (*domain.Employee, error) {
+	emp, err := s.empRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	return http.DefaultClient.Do(req)
+	if emp.DepartmentID == req.DepartmentID {
+		return nil, domain.ErrTransferToSameDepartment
+	}
+	if _, err := s.deptRepo.GetByID(ctx, req.DepartmentID); err != nil {
+		return nil, err
+	}
+
+	effectiveDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.EffectiveDate != nil {
+		effectiveDate, _ = time.Parse("2006-01-02", *req.EffectiveDate)
+	}
+
+	fromDeptID := emp.DepartmentID
+	emp.DepartmentID = req.DepartmentID
+	s.transfers = append(s.transfers, domain.EmployeeTransfer{
+		ID:               int64(len(s.transfers) + 1),
+		EmployeeID:       id,
+		FromDepartmentID: &fromDeptID,
+		ToDepartmentID:   &req.DepartmentID,
+		EffectiveDate:    effectiveDate,
+		CreatedAt:        time.Now(),
+	})
+	return emp, nil
 }
 
-func deleteRequest(url string) (*http.Response, error) {
-	req, err := http.NewRequest(http.MethodDelete, url, nil)
-	if err != nil {
+func (s *mockEmployeeService) GetTransferHistory(ctx context.Context, id int64) ([]domain.EmployeeTransfer, error) {
+	if _, err := s.empRepo.GetByID(ctx, id); err != nil {
 		return nil, err
 	}
-	return http.DefaultClient.Do(req)
-}
 
-func mustPost(t *testing.T, url string, body map[string]any) {
-	resp, err := postJSON(url, body)
-	if err != nil {
-		t.Fatalf("request failed: %v", err)
+	var result []domain.EmployeeTransfer
+	for _, transfer := range s.transfers {
+		if transfer.EmployeeID == id {
+			result = append(result, transfer)
+		}
 	}
-	resp.Body.Close()
+	return result, nil
 }
 
-func TestHealthCheck(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+func (s *mockEmployeeService) ListByDepartment(ctx context.Context, departmentID int64, query *dto.ListEmployeesQuery) ([]domain.Employee, string, error) {
+	if _, err := s.deptRepo.GetByID(ctx, departmentID); err != nil {
+		return nil, "", err
+	}
 
-	resp, err := http.Get(ts.server.URL + "/health")
-	if err != nil {
-		t.Fatalf("request failed: %v", err)
+	var after int64
+	if query.Cursor != "" {
+		var err error
+		if after, err = strconv.ParseInt(query.Cursor, 10, 64); err != nil {
+			return nil, "", domain.ErrInvalidCursor
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	var result []domain.Employee
+	for id := after + 1; id < s.empRepo.nextID; id++ {
+		emp, ok := s.empRepo.employees[id]
+		if !ok || emp.DepartmentID != departmentID {
+			continue
+		}
+		if len(result) == query.Limit {
+			return result, strconv.FormatInt(result[len(result)-1].ID, 10), nil
+		}
+		result = append(result, *emp)
 	}
+	return result, "", nil
 }
 
-func TestCreateDepartment_Success(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+type mockTrashService struct {
+	deptRepo *mockDepartmentRepo
+	empRepo  *mockEmployeeRepo
+}
 
-	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": "IT Department"})
-	if err != nil {
-		t.Fatalf("request failed: %v", err)
+func (s *mockTrashService) ListDepartments(ctx context.Context, query *dto.ListTrashQuery) ([]domain.Department, string, error) {
+	var result []domain.Department
+	for id := s.deptRepo.nextID - 1; id > 0; id-- {
+		if dept, ok := s.deptRepo.deleted[id]; ok {
+			result = append(result, *dept)
+		}
 	}
-	defer resp.Body.Close()
+	return result, "", nil
+}
 
-	if resp.StatusCode != http.StatusCreated {
-		t.Errorf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
+func (s *mockTrashService) ListEmployees(ctx context.Context, query *dto.ListTrashQuery) ([]domain.Employee, string, error) {
+	var result []domain.Employee
+	for id := s.empRepo.nextID - 1; id > 0; id-- {
+		if emp, ok := s.empRepo.deleted[id]; ok {
+			result = append(result, *emp)
+		}
 	}
+	return result, "", nil
+}
 
-	var result dto.DepartmentResponse
-	json.NewDecoder(resp.Body).Decode(&result)
-	if result.Name != "IT Department" {
-		t.Errorf("expected name 'IT Department', got '%s'", result.Name)
-	}
+func (s *mockTrashService) Purge(ctx context.Context, retention time.Duration) (*service.PurgeResult, error) {
+	return &service.PurgeResult{}, nil
 }
 
-func TestCreateDepartment_WithParent(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+type mockAuditService struct {
+	entries   []domain.AuditEntry
+	lastQuery *dto.ListAuditQuery
+}
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent"})
+func (s *mockAuditService) List(ctx context.Context, query *dto.ListAuditQuery) ([]domain.AuditEntry, string, error) {
+	s.lastQuery = query
+	return s.entries, "", nil
+}
 
-	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 1})
-	if err != nil {
-		t.Fatalf("request failed: %v", err)
-	}
-	defer resp.Body.Close()
+type mockDiffService struct {
+	diff      domain.OrgDiff
+	lastQuery *dto.OrgDiffQuery
+}
 
-	if resp.StatusCode != http.StatusCreated {
-		t.Errorf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
-	}
+func (s *mockDiffService) Diff(ctx context.Context, query *dto.OrgDiffQuery) (*domain.OrgDiff, error) {
+	s.lastQuery = query
+	return &s.diff, nil
 }
 
-func TestCreateDepartment_EmptyName(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+type mockSnapshotService struct {
+	snapshots map[int64]*domain.Snapshot
+	trees     map[int64][]domain.Department
+	lastTree  *dto.SnapshotTreeQuery
+}
 
-	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": ""})
-	if err != nil {
-		t.Fatalf("request failed: %v", err)
+func newMockSnapshotService() *mockSnapshotService {
+	return &mockSnapshotService{
+		snapshots: make(map[int64]*domain.Snapshot),
+		trees:     make(map[int64][]domain.Department),
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+func (s *mockSnapshotService) Create(ctx context.Context, req *dto.CreateSnapshotRequest) (*domain.Snapshot, error) {
+	snap := &domain.Snapshot{ID: int64(len(s.snapshots) + 1), Name: req.Name, CreatedBy: domain.ActorFromContext(ctx), CreatedAt: time.Now()}
+	for _, other := range s.snapshots {
+		if other.Name == req.Name {
+			snap.Version = max(snap.Version, other.Version)
+		}
 	}
+	snap.Version++
+	s.snapshots[snap.ID] = snap
+	return snap, nil
 }
 
-func TestCreateDepartment_MissingName(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+func (s *mockSnapshotService) GetByID(ctx context.Context, id int64) (*domain.Snapshot, error) {
+	snap, ok := s.snapshots[id]
+	if !ok {
+		return nil, domain.ErrSnapshotNotFound
+	}
+	return snap, nil
+}
 
-	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{})
-	if err != nil {
-		t.Fatalf("request failed: %v", err)
+func (s *mockSnapshotService) List(ctx context.Context, query *dto.ListSnapshotsQuery) ([]domain.Snapshot, string, error) {
+	var result []domain.Snapshot
+	for id := int64(len(s.snapshots)); id > 0; id-- {
+		if snap := s.snapshots[id]; query.Name == "" || snap.Name == query.Name {
+			result = append(result, *snap)
+		}
 	}
-	defer resp.Body.Close()
+	return result, "", nil
+}
 
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+func (s *mockSnapshotService) GetTree(ctx context.Context, id int64, query *dto.SnapshotTreeQuery) ([]domain.Department, error) {
+	s.lastTree = query
+	if _, ok := s.snapshots[id]; !ok {
+		return nil, domain.ErrSnapshotNotFound
 	}
+	return s.trees[id], nil
 }
 
-func TestCreateDepartment_ParentNotFound(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+// mockDraftService хранит черновики в памяти; Apply возвращает applyErr, если она задана
+type mockDraftService struct {
+	drafts   map[int64]*domain.Draft
+	applyErr error
+}
+
+func newMockDraftService() *mockDraftService {
+	return &mockDraftService{drafts: make(map[int64]*domain.Draft)}
+}
+
+func (s *mockDraftService) Create(ctx context.Context, req *dto.CreateDraftRequest) (*domain.Draft, error) {
+	draft := &domain.Draft{ID: int64(len(s.drafts) + 1), Name: req.Name, Status: domain.DraftStatusOpen, CreatedBy: domain.ActorFromContext(ctx), CreatedAt: time.Now()}
+	s.drafts[draft.ID] = draft
+	return draft, nil
+}
+
+func (s *mockDraftService) GetByID(ctx context.Context, id int64) (*domain.Draft, error) {
+	draft, ok := s.drafts[id]
+	if !ok {
+		return nil, domain.ErrDraftNotFound
+	}
+	return draft, nil
+}
+
+func (s *mockDraftService) AddOperation(ctx context.Context, draftID int64, req *dto.DraftOperationRequest) (*domain.DraftOperation, error) {
+	draft, err := s.GetByID(ctx, draftID)
+	if err != nil {
+		return nil, err
+	}
+	op := domain.DraftOperation{
+		ID:           int64(len(draft.Operations) + 1),
+		DraftID:      draftID,
+		Position:     len(draft.Operations) + 1,
+		Type:         req.Type,
+		Ref:          req.Ref,
+		DepartmentID: req.DepartmentID,
+		ParentID:     req.ParentID,
+		ParentRef:    req.ParentRef,
+	}
+	if req.Name != nil {
+		op.Name = *req.Name
+	}
+	draft.Operations = append(draft.Operations, op)
+	return &op, nil
+}
+
+func (s *mockDraftService) RemoveOperation(ctx context.Context, draftID, id int64) error {
+	draft, err := s.GetByID(ctx, draftID)
+	if err != nil {
+		return err
+	}
+	for i, op := range draft.Operations {
+		if op.ID == id {
+			draft.Operations = append(draft.Operations[:i], draft.Operations[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrDraftOperationNotFound
+}
+
+func (s *mockDraftService) Preview(ctx context.Context, id int64, query *dto.DraftPreviewQuery) ([]domain.Department, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return []domain.Department{{ID: 1, Name: "Company"}}, nil
+}
+
+func (s *mockDraftService) Apply(ctx context.Context, id int64) (*domain.Draft, error) {
+	return s.close(ctx, id, domain.DraftStatusApplied, s.applyErr)
+}
+
+func (s *mockDraftService) Discard(ctx context.Context, id int64) (*domain.Draft, error) {
+	return s.close(ctx, id, domain.DraftStatusDiscarded, nil)
+}
+
+func (s *mockDraftService) close(ctx context.Context, id int64, status string, err error) (*domain.Draft, error) {
+	draft, getErr := s.GetByID(ctx, id)
+	if getErr != nil {
+		return nil, getErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != domain.DraftStatusOpen {
+		return nil, domain.ErrDraftNotOpen
+	}
+	now := time.Now()
+	draft.Status, draft.ClosedAt = status, &now
+	return draft, nil
+}
+
+type mockManagementService struct {
+	deptRepo *mockDepartmentRepo
+	empRepo  *mockEmployeeRepo
+	// reportQueries и spanQueries - параметры полученных запросов
+	reportQueries []dto.ReportsQuery
+	spanQueries   []dto.SpanOfControlQuery
+}
+
+// GetReports возвращает сотрудников подразделений, которыми руководит employeeID
+func (s *mockManagementService) GetReports(ctx context.Context, employeeID int64, query *dto.ReportsQuery) ([]domain.Report, error) {
+	s.reportQueries = append(s.reportQueries, *query)
+	if _, err := s.empRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+	reports := []domain.Report{}
+	for _, dept := range s.deptRepo.departments {
+		if dept.HeadEmployeeID == nil || *dept.HeadEmployeeID != employeeID {
+			continue
+		}
+		for _, emp := range s.empRepo.employees {
+			if emp.DepartmentID == dept.ID && emp.ID != employeeID {
+				reports = append(reports, domain.Report{Employee: *emp, ManagerID: employeeID, Depth: 1})
+			}
+		}
+	}
+	return reports, nil
+}
+
+func (s *mockManagementService) GetSpanOfControl(ctx context.Context, query *dto.SpanOfControlQuery) ([]domain.SpanOfControl, int, error) {
+	s.spanQueries = append(s.spanQueries, *query)
+	threshold := 8
+	if query.Threshold != nil {
+		threshold = *query.Threshold
+	}
+	return []domain.SpanOfControl{}, threshold, nil
+}
+
+func (s *mockManagementService) GetManagers(ctx context.Context, employeeID int64) ([]domain.Manager, error) {
+	emp, err := s.empRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := s.deptRepo.GetAncestors(ctx, emp.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+	managers := []domain.Manager{}
+	for _, dept := range slices.Backward(chain) {
+		if dept.HeadEmployeeID == nil || *dept.HeadEmployeeID == emp.ID {
+			continue
+		}
+		head, err := s.empRepo.GetByID(ctx, *dept.HeadEmployeeID)
+		if err != nil {
+			return nil, err
+		}
+		managers = append(managers, domain.Manager{Department: dept, Employee: *head})
+	}
+	return managers, nil
+}
+
+type mockDelegationService struct {
+	deptRepo    *mockDepartmentRepo
+	delegations map[int64]*domain.Delegation
+}
+
+func (s *mockDelegationService) Create(ctx context.Context, req *dto.CreateDelegationRequest) (*domain.Delegation, error) {
+	dept, err := s.deptRepo.GetByID(ctx, req.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+	if dept.HeadEmployeeID == nil || *dept.HeadEmployeeID != req.DelegatorID {
+		return nil, domain.ErrDelegatorNotHead
+	}
+	startsOn, _ := time.Parse("2006-01-02", req.StartsOn)
+	endsOn, _ := time.Parse("2006-01-02", req.EndsOn)
+	if endsOn.Before(startsOn) {
+		return nil, domain.ErrInvalidDelegationPeriod
+	}
+	delegation := &domain.Delegation{
+		ID: int64(len(s.delegations) + 1), DepartmentID: req.DepartmentID, DelegatorID: req.DelegatorID, DelegateID: req.DelegateID,
+		StartsOn: startsOn, EndsOn: endsOn, CreatedBy: domain.ActorFromContext(ctx), CreatedAt: time.Now(),
+	}
+	s.delegations[delegation.ID] = delegation
+	return delegation, nil
+}
+
+func (s *mockDelegationService) GetByID(ctx context.Context, id int64) (*domain.Delegation, error) {
+	delegation, ok := s.delegations[id]
+	if !ok {
+		return nil, domain.ErrDelegationNotFound
+	}
+	return delegation, nil
+}
+
+func (s *mockDelegationService) List(ctx context.Context, query *dto.ListDelegationsQuery) ([]domain.Delegation, string, error) {
+	var result []domain.Delegation
+	for id := int64(len(s.delegations)); id > 0; id-- {
+		delegation := s.delegations[id]
+		if query.DepartmentID != nil && delegation.DepartmentID != *query.DepartmentID {
+			continue
+		}
+		result = append(result, *delegation)
+	}
+	return result, "", nil
+}
+
+func (s *mockDelegationService) Revoke(ctx context.Context, id int64) (*domain.Delegation, error) {
+	delegation, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delegation.RevokedAt != nil {
+		return nil, domain.ErrDelegationRevoked
+	}
+	now, actor := time.Now(), domain.ActorFromContext(ctx)
+	delegation.RevokedAt, delegation.RevokedBy = &now, &actor
+	return delegation, nil
+}
+
+type mockPositionService struct {
+	positions map[int64]*domain.Position
+	// used - должности, указанные у сотрудников
+	used map[int64]bool
+}
+
+func newMockPositionService() *mockPositionService {
+	return &mockPositionService{positions: make(map[int64]*domain.Position), used: make(map[int64]bool)}
+}
+
+func (s *mockPositionService) Create(ctx context.Context, req *dto.CreatePositionRequest) (*domain.Position, error) {
+	for _, position := range s.positions {
+		if strings.EqualFold(position.Title, req.Title) {
+			return nil, domain.ErrDuplicatePositionTitle
+		}
+	}
+	position := &domain.Position{
+		ID: int64(len(s.positions) + 1), Title: req.Title, JobFamily: req.JobFamily, Grade: req.Grade,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}
+	s.positions[position.ID] = position
+	return position, nil
+}
+
+func (s *mockPositionService) GetByID(ctx context.Context, id int64) (*domain.Position, error) {
+	position, ok := s.positions[id]
+	if !ok {
+		return nil, domain.ErrPositionNotFound
+	}
+	return position, nil
+}
+
+func (s *mockPositionService) List(ctx context.Context, query *dto.ListPositionsQuery) ([]domain.Position, string, error) {
+	var result []domain.Position
+	for id := int64(1); id <= int64(len(s.positions)); id++ {
+		position, ok := s.positions[id]
+		if !ok || (query.JobFamily != "" && (position.JobFamily == nil || *position.JobFamily != query.JobFamily)) {
+			continue
+		}
+		result = append(result, *position)
+	}
+	if len(result) > query.Limit {
+		return result[:query.Limit], "next", nil
+	}
+	return result, "", nil
+}
+
+func (s *mockPositionService) Update(ctx context.Context, id int64, req *dto.UpdatePositionRequest) (*domain.Position, error) {
+	position, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Title != nil {
+		position.Title = *req.Title
+	}
+	if req.JobFamily != nil {
+		position.JobFamily = req.JobFamily
+	}
+	if req.Grade != nil {
+		position.Grade = req.Grade
+	}
+	return position, nil
+}
+
+func (s *mockPositionService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	if s.used[id] {
+		return domain.ErrPositionInUse
+	}
+	delete(s.positions, id)
+	return nil
+}
+
+type mockHeadcountService struct {
+	deptRepo *mockDepartmentRepo
+	slots    []domain.HeadcountSlot
+}
+
+func (s *mockHeadcountService) SetSlot(ctx context.Context, departmentID, positionID int64, req *dto.SetHeadcountSlotRequest) (*domain.HeadcountSlot, error) {
+	if _, err := s.deptRepo.GetByID(ctx, departmentID); err != nil {
+		return nil, err
+	}
+	slot := domain.HeadcountSlot{DepartmentID: departmentID, PositionID: positionID, Approved: *req.Approved, UpdatedAt: time.Now()}
+	s.slots = append(s.slots, slot)
+	return &slot, nil
+}
+
+func (s *mockHeadcountService) RemoveSlot(ctx context.Context, departmentID, positionID int64) error {
+	for i, slot := range s.slots {
+		if slot.DepartmentID == departmentID && slot.PositionID == positionID {
+			s.slots = slices.Delete(s.slots, i, i+1)
+			return nil
+		}
+	}
+	return domain.ErrHeadcountSlotNotFound
+}
+
+func (s *mockHeadcountService) GetVacancies(ctx context.Context, departmentID int64) (*domain.Vacancies, error) {
+	if _, err := s.deptRepo.GetByID(ctx, departmentID); err != nil {
+		return nil, err
+	}
+	vacancies := &domain.Vacancies{DepartmentID: departmentID, Slots: s.slots}
+	for i := range s.slots {
+		vacancies.Approved += s.slots[i].Approved
+		vacancies.Filled += s.slots[i].Filled
+		vacancies.Open += s.slots[i].Open()
+	}
+	return vacancies, nil
+}
+
+type testServer struct {
+	server     *httptest.Server
+	deptRepo   *mockDepartmentRepo
+	empRepo    *mockEmployeeRepo
+	deptSvc    *mockDepartmentService
+	audit      *mockAuditService
+	diff       *mockDiffService
+	snapshot   *mockSnapshotService
+	draft      *mockDraftService
+	management *mockManagementService
+	positions  *mockPositionService
+	headcount  *mockHeadcountService
+}
+
+func setupTestServer(_ *testing.T) *testServer {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	deptRepo := newMockDepartmentRepo()
+	empRepo := newMockEmployeeRepo()
+
+	deptService := &mockDepartmentService{deptRepo: deptRepo, empRepo: empRepo}
+	empService := &mockEmployeeService{empRepo: empRepo, deptRepo: deptRepo}
+
+	deptHandler := handler.NewDepartmentHandler(deptService, empService, logger)
+	empHandler := handler.NewEmployeeHandler(empService, logger)
+	trashHandler := handler.NewTrashHandler(&mockTrashService{deptRepo: deptRepo, empRepo: empRepo}, logger)
+	audit := &mockAuditService{}
+	auditHandler := handler.NewAuditHandler(audit, logger)
+	diff := &mockDiffService{}
+	diffHandler := handler.NewDiffHandler(diff, logger)
+	snapshot := newMockSnapshotService()
+	snapshotHandler := handler.NewSnapshotHandler(snapshot, logger)
+	draft := newMockDraftService()
+	draftHandler := handler.NewDraftHandler(draft, logger)
+	management := &mockManagementService{deptRepo: deptRepo, empRepo: empRepo}
+	managementHandler := handler.NewManagementHandler(management, logger)
+	delegationHandler := handler.NewDelegationHandler(&mockDelegationService{deptRepo: deptRepo, delegations: make(map[int64]*domain.Delegation)}, logger)
+	positions := newMockPositionService()
+	positionHandler := handler.NewPositionHandler(positions, logger)
+	headcount := &mockHeadcountService{deptRepo: deptRepo}
+	headcountHandler := handler.NewHeadcountHandler(headcount, logger)
+	router := handler.NewRouter(deptHandler, empHandler, trashHandler, auditHandler, diffHandler, snapshotHandler, draftHandler, managementHandler, delegationHandler, positionHandler, headcountHandler, logger)
+
+	return &testServer{
+		server:     httptest.NewServer(router.Setup()),
+		deptRepo:   deptRepo,
+		empRepo:    empRepo,
+		deptSvc:    deptService,
+		audit:      audit,
+		diff:       diff,
+		snapshot:   snapshot,
+		draft:      draft,
+		management: management,
+		positions:  positions,
+		headcount:  headcount,
+	}
+}
+
+func (ts *testServer) Close() {
+	ts.server.Close()
+}
+
+func postJSON(url string, body map[string]any) (*http.Response, error) {
+	data, _ := json.Marshal(body)
+	return http.Post(url, "application/json", bytes.NewBuffer(data))
+}
+
+func patchJSON(url string, body map[string]any) (*http.Response, error) {
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return http.DefaultClient.Do(req)
+}
+
+func putJSON(url string, body map[string]any) (*http.Response, error) {
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return http.DefaultClient.Do(req)
+}
+
+func deleteRequest(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func mustPost(t *testing.T, url string, body map[string]any) {
+	resp, err := postJSON(url, body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+// mustPostResponse отправляет POST-запрос и возвращает ответ
+func mustPostResponse(t *testing.T, url string, body map[string]any) *http.Response {
+	t.Helper()
+	resp, err := postJSON(url, body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+// decodeJSON проверяет статус ответа и декодирует тело в v
+func decodeJSON(t *testing.T, resp *http.Response, status int, v any) {
+	t.Helper()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		t.Fatalf("expected %d, got %d", status, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.server.URL + "/health")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestCreateDepartment_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": "IT Department"})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var result dto.DepartmentResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if result.Name != "IT Department" {
+		t.Errorf("expected name 'IT Department', got '%s'", result.Name)
+	}
+}
+
+func TestCreateDepartment_WithParent(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent"})
+
+	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 1})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+}
+
+func TestCreateDepartment_EmptyName(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": ""})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestCreateDepartment_MissingName(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestCreateDepartment_ParentNotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
 
 	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 999})
 	if err != nil {
@@ -454,53 +1289,877 @@ func TestCreateDepartment_ParentNotFound(t *testing.T) {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNotFound {
-		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestCreateDepartment_DuplicateName(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+
+	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestCreateDepartment_SameNameDifferentParent(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent1"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent2"})
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 1})
+
+	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 2})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+}
+
+func TestCreateDepartment_InvalidJSON(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.server.URL+"/departments/", "application/json", bytes.NewBuffer([]byte("invalid")))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestGetDepartment_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+
+	resp, err := http.Get(ts.server.URL + "/departments/1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestGetDepartment_NotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.server.URL + "/departments/999")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestGetDepartment_InvalidID(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.server.URL + "/departments/abc")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestGetDepartment_IncludePath(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Company"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT", "parent_id": 1})
+
+	resp, err := http.Get(ts.server.URL + "/departments/2?include_path=true")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result dto.DepartmentResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if len(result.Path) != 2 || result.Path[0].Name != "Company" || result.Path[1].Name != "IT" {
+		t.Errorf("unexpected path: %+v", result.Path)
+	}
+
+	resp, err = http.Get(ts.server.URL + "/departments/2")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	result = dto.DepartmentResponse{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if result.Path != nil {
+		t.Errorf("expected no path by default, got %+v", result.Path)
+	}
+}
+
+func TestListDepartments_RootsOnly(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Company"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT", "parent_id": 1})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Holding"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Partners"})
+
+	resp, err := http.Get(ts.server.URL + "/departments/?limit=2")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var page dto.DepartmentListResponse
+	json.NewDecoder(resp.Body).Decode(&page)
+	if len(page.Items) != 2 || page.Items[0].Name != "Company" || page.Items[1].Name != "Holding" {
+		t.Fatalf("unexpected first page: %+v", page.Items)
+	}
+	if page.NextCursor == nil {
+		t.Fatal("expected next cursor")
+	}
+
+	resp, err = http.Get(ts.server.URL + "/departments/?limit=2&cursor=" + *page.NextCursor)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	page = dto.DepartmentListResponse{}
+	json.NewDecoder(resp.Body).Decode(&page)
+	if len(page.Items) != 1 || page.Items[0].Name != "Partners" || page.NextCursor != nil {
+		t.Errorf("unexpected last page: %+v", page)
+	}
+}
+
+func TestListDepartments_NameSearch(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Company"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Holding"})
+
+	resp, err := http.Get(ts.server.URL + "/departments/?name=hold")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var page dto.DepartmentListResponse
+	json.NewDecoder(resp.Body).Decode(&page)
+	if len(page.Items) != 1 || page.Items[0].Name != "Holding" {
+		t.Errorf("unexpected items: %+v", page.Items)
+	}
+}
+
+func TestListDepartments_InvalidLimit(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	for _, limit := range []string{"abc", "0", "101"} {
+		resp, err := http.Get(ts.server.URL + "/departments/?limit=" + limit)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("limit=%s: expected %d, got %d", limit, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestGetOrgTree_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Company"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT", "parent_id": 1})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Backend", "parent_id": 2})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Holding"})
+
+	resp, err := http.Get(ts.server.URL + "/org/tree?depth=1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var forest []dto.DepartmentResponse
+	json.NewDecoder(resp.Body).Decode(&forest)
+	if len(forest) != 2 || forest[0].Name != "Company" || forest[1].Name != "Holding" {
+		t.Fatalf("unexpected roots: %+v", forest)
+	}
+	if len(forest[0].Children) != 1 || forest[0].Children[0].Name != "IT" {
+		t.Fatalf("unexpected children: %+v", forest[0].Children)
+	}
+	if len(forest[0].Children[0].Children) != 0 {
+		t.Errorf("expected depth to be limited, got %+v", forest[0].Children[0].Children)
+	}
+}
+
+func TestGetOrgTree_InvalidDepth(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	for _, depth := range []string{"abc", "-1", "11"} {
+		resp, err := http.Get(ts.server.URL + "/org/tree?depth=" + depth)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("depth=%s: expected %d, got %d", depth, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestGetDepartmentAncestors_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Company"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT", "parent_id": 1})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Backend", "parent_id": 2})
+
+	resp, err := http.Get(ts.server.URL + "/departments/3/ancestors")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var path []dto.DepartmentPathItem
+	json.NewDecoder(resp.Body).Decode(&path)
+
+	want := []string{"Company", "IT", "Backend"}
+	if len(path) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(path))
+	}
+	for i, name := range want {
+		if path[i].Name != name || path[i].ID != int64(i+1) {
+			t.Errorf("item %d: expected %d '%s', got %+v", i, i+1, name, path[i])
+		}
+	}
+}
+
+func TestGetDepartmentAncestors_NotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.server.URL + "/departments/999/ancestors")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestAsOf_PassedToService(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Company"})
+
+	for _, path := range []string{
+		"/departments/1?as_of=2024-06-01",
+		"/departments/1/ancestors?as_of=2024-06-01",
+		"/org/tree?as_of=2024-06-01",
+	} {
+		resp, err := http.Get(ts.server.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected %d, got %d", path, http.StatusOK, resp.StatusCode)
+		}
+	}
+
+	if len(ts.deptSvc.asOfRequests) != 3 {
+		t.Fatalf("expected 3 reads, got %d", len(ts.deptSvc.asOfRequests))
+	}
+	for i, asOf := range ts.deptSvc.asOfRequests {
+		if asOf == nil || *asOf != "2024-06-01" {
+			t.Errorf("read %d: expected as_of 2024-06-01, got %v", i, asOf)
+		}
+	}
+}
+
+func TestAsOf_Invalid(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Company"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{"full_name": "John", "position": "Dev"})
+
+	for _, path := range []string{
+		"/departments/1?as_of=yesterday",
+		"/departments/1/ancestors?as_of=2024-13-01",
+		"/departments/1/employees?as_of=01.06.2024",
+		"/org/tree?as_of=2024-06-01T00:00:00Z",
+		"/employees/1?as_of=2024-02-30",
+	} {
+		resp, err := http.Get(ts.server.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected %d, got %d", path, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestUpdateDepartment_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Old Name"})
+
+	resp, err := patchJSON(ts.server.URL+"/departments/1", map[string]any{"name": "New Name"})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result dto.DepartmentResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if result.Name != "New Name" {
+		t.Errorf("expected 'New Name', got '%s'", result.Name)
+	}
+}
+
+func TestUpdateDepartment_NotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := patchJSON(ts.server.URL+"/departments/999", map[string]any{"name": "Test"})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestUpdateDepartment_SelfReference(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
+
+	resp, err := patchJSON(ts.server.URL+"/departments/1", map[string]any{"parent_id": 1})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestUpdateDepartment_CyclicReference(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 1})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "GrandChild", "parent_id": 2})
+
+	resp, err := patchJSON(ts.server.URL+"/departments/1", map[string]any{"parent_id": 3})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestUpdateDepartment_ParentNotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
+
+	resp, err := patchJSON(ts.server.URL+"/departments/1", map[string]any{"parent_id": 999})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestUpdateDepartment_DuplicateName(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept1"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept2"})
+
+	resp, err := patchJSON(ts.server.URL+"/departments/2", map[string]any{"name": "Dept1"})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestUpdateDepartment_MoveToAnotherParent(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent1"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent2"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 1})
+
+	resp, err := patchJSON(ts.server.URL+"/departments/3", map[string]any{"parent_id": 2})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestDeleteDepartment_Cascade(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "ToDelete"})
+
+	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=cascade")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+}
+
+func TestRestoreDepartment_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "ToDelete"})
+	resp, _ := deleteRequest(ts.server.URL + "/departments/1?mode=cascade")
+	resp.Body.Close()
+
+	resp, err := http.Get(ts.server.URL + "/trash/departments")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var trash dto.DepartmentListResponse
+	json.NewDecoder(resp.Body).Decode(&trash)
+	if len(trash.Items) != 1 || trash.Items[0].ID != 1 || trash.Items[0].DeletedAt == nil {
+		t.Fatalf("expected deleted department in trash, got %+v", trash.Items)
+	}
+
+	resp, err = http.Post(ts.server.URL+"/departments/1/restore", "application/json", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result dto.DepartmentResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if result.ID != 1 || result.DeletedAt != nil {
+		t.Errorf("unexpected restored department: %+v", result)
+	}
+	if _, ok := ts.deptRepo.departments[1]; !ok {
+		t.Error("expected department to be restored")
+	}
+}
+
+func TestRestoreDepartment_Errors(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Live"})
+
+	cases := []struct {
+		url  string
+		want int
+	}{
+		{"/departments/1/restore", http.StatusConflict},
+		{"/departments/999/restore", http.StatusNotFound},
+		{"/departments/abc/restore", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		resp, err := http.Post(ts.server.URL+tc.url, "application/json", nil)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s: expected %d, got %d", tc.url, tc.want, resp.StatusCode)
+		}
+	}
+}
+
+func TestListTrash_InvalidLimit(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	for _, path := range []string{"/trash/departments?limit=0", "/trash/employees?limit=abc"} {
+		resp, err := http.Get(ts.server.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected %d, got %d", path, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestListAudit_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	ts.audit.entries = []domain.AuditEntry{{
+		ID:        1,
+		Actor:     "alice",
+		Entity:    domain.AuditEntityDepartment,
+		EntityID:  7,
+		Operation: domain.AuditOperationCreate,
+		After:     json.RawMessage(`{"id":7,"name":"Backend"}`),
+		RequestID: "req-1",
+	}}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.server.URL+"/audit?entity=department&id=7&from=2024-01-01T00:00:00Z", nil)
+	req.Header.Set("X-Request-ID", "req-2")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if got := resp.Header.Get("X-Request-ID"); got != "req-2" {
+		t.Errorf("expected request id to be echoed, got %q", got)
+	}
+
+	query := ts.audit.lastQuery
+	if query.Entity != "department" || query.EntityID == nil || *query.EntityID != 7 ||
+		query.From == nil || query.To != nil || query.Limit != 20 {
+		t.Errorf("unexpected query: %+v", query)
+	}
+
+	var result dto.AuditListResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if len(result.Items) != 1 || result.Items[0].Actor != "alice" || string(result.Items[0].Before) != "null" {
+		t.Fatalf("unexpected response: %+v", result)
+	}
+	if string(result.Items[0].After) != `{"id":7,"name":"Backend"}` {
+		t.Errorf("unexpected after state: %s", result.Items[0].After)
+	}
+}
+
+func TestListAudit_InvalidQuery(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	for _, path := range []string{
+		"/audit?id=7",
+		"/audit?entity=team",
+		"/audit?entity=employee&id=abc",
+		"/audit?from=2024-01-01",
+		"/audit?limit=101",
+	} {
+		resp, err := http.Get(ts.server.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected %d, got %d", path, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestOrgDiff_Success(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	parentID, deptID := int64(1), int64(2)
+	ts.diff.diff = domain.OrgDiff{
+		Departments: []domain.DepartmentChange{{
+			Type:         domain.ChangeDepartmentMoved,
+			DepartmentID: 2,
+			Before:       &domain.Department{ID: 2, Name: "Backend", ParentID: &parentID},
+			After:        &domain.Department{ID: 2, Name: "Backend"},
+		}},
+		Employees: []domain.EmployeeChange{{
+			Type:           domain.ChangeEmployeeHired,
+			EmployeeID:     5,
+			FullName:       "John",
+			ToDepartmentID: &deptID,
+		}},
+	}
+
+	resp, err := http.Get(ts.server.URL + "/org/diff?from=2024-01-01&to=2024-06-01")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if q := ts.diff.lastQuery; *q.From != "2024-01-01" || *q.To != "2024-06-01" || q.FromSnapshotID != nil || q.ToSnapshotID != nil {
+		t.Errorf("unexpected query: %+v", ts.diff.lastQuery)
+	}
+
+	var body dto.OrgDiffResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+
+	if len(body.Departments) != 1 || body.Departments[0].Type != "moved" {
+		t.Fatalf("expected one moved department, got %+v", body.Departments)
+	}
+	moved := body.Departments[0]
+	if moved.Before == nil || moved.Before.ParentID == nil || *moved.Before.ParentID != 1 || moved.After == nil || moved.After.ParentID != nil {
+		t.Errorf("expected move from 1 to root, got before=%+v after=%+v", moved.Before, moved.After)
+	}
+	if len(body.Employees) != 1 || body.Employees[0].FromDepartmentID != nil || *body.Employees[0].ToDepartmentID != 2 {
+		t.Errorf("expected one hired employee, got %+v", body.Employees)
+	}
+}
+
+func TestOrgDiff_InvalidQuery(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	for _, query := range []string{
+		"",
+		"from=2024-01-01",
+		"to=2024-01-01",
+		"from=2024-01-01&to=june",
+		"from=2024-01-01&from_snapshot_id=1&to=2024-06-01",
+		"from_snapshot_id=abc&to=2024-06-01",
+		"from_snapshot_id=0&to_snapshot_id=1",
+	} {
+		resp, err := http.Get(ts.server.URL + "/org/diff?" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%q: expected %d, got %d", query, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestOrgDiff_Snapshots(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.server.URL + "/org/diff?from_snapshot_id=1&to=2024-06-01")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if q := ts.diff.lastQuery; q.From != nil || *q.FromSnapshotID != 1 || *q.To != "2024-06-01" {
+		t.Errorf("unexpected query: %+v", q)
+	}
+
+	var body map[string]any
+	json.NewDecoder(resp.Body).Decode(&body)
+	if _, ok := body["from"]; ok || body["from_snapshot_id"] != float64(1) || body["to"] != "2024-06-01" {
+		t.Errorf("unexpected sides in response: %v", body)
 	}
 }
 
-func TestCreateDepartment_DuplicateName(t *testing.T) {
+func TestSnapshots_CreateAndGet(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	var first, second dto.SnapshotResponse
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/snapshots", map[string]any{"name": "Q1"}), http.StatusCreated, &first)
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/snapshots", map[string]any{"name": "Q1"}), http.StatusCreated, &second)
+	if first.Version != 1 || second.Version != 2 {
+		t.Errorf("expected versions 1 and 2, got %d and %d", first.Version, second.Version)
+	}
 
-	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	resp, err := http.Get(ts.server.URL + "/snapshots?name=Q1")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
+	var list dto.SnapshotListResponse
+	decodeJSON(t, resp, http.StatusOK, &list)
+	if len(list.Items) != 2 || list.Items[0].ID != second.ID {
+		t.Errorf("expected newest snapshot first, got %+v", list.Items)
+	}
 
-	if resp.StatusCode != http.StatusConflict {
-		t.Errorf("expected %d, got %d", http.StatusConflict, resp.StatusCode)
+	resp, err = http.Get(ts.server.URL + "/snapshots/" + strconv.FormatInt(first.ID, 10))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var got dto.SnapshotResponse
+	decodeJSON(t, resp, http.StatusOK, &got)
+	if got.Name != "Q1" || got.Version != 1 {
+		t.Errorf("unexpected snapshot: %+v", got)
 	}
 }
 
-func TestCreateDepartment_SameNameDifferentParent(t *testing.T) {
+func TestSnapshots_Tree(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent1"})
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent2"})
+	mustPost(t, ts.server.URL+"/snapshots", map[string]any{"name": "Q1"})
+	rootID := int64(1)
+	ts.snapshot.trees[1] = []domain.Department{{
+		ID:        1,
+		Name:      "Company",
+		Children:  []domain.Department{{ID: 2, Name: "IT", ParentID: &rootID}},
+		Employees: []domain.Employee{{ID: 5, DepartmentID: 1, FullName: "John", Position: "CEO"}},
+	}}
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 1})
+	resp, err := http.Get(ts.server.URL + "/snapshots/1/tree?depth=3&include_employees=true")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var tree []dto.DepartmentResponse
+	decodeJSON(t, resp, http.StatusOK, &tree)
 
-	resp, err := postJSON(ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 2})
+	if ts.snapshot.lastTree.Depth != 3 || !ts.snapshot.lastTree.IncludeEmployees {
+		t.Errorf("unexpected tree query: %+v", ts.snapshot.lastTree)
+	}
+	if len(tree) != 1 || len(tree[0].Children) != 1 || tree[0].Children[0].Name != "IT" || len(tree[0].Employees) != 1 {
+		t.Errorf("unexpected tree: %+v", tree)
+	}
+
+	for path, status := range map[string]int{
+		"/snapshots/2/tree":          http.StatusNotFound,
+		"/snapshots/1/tree?depth=11": http.StatusBadRequest,
+		"/snapshots/abc/tree":        http.StatusBadRequest,
+		"/snapshots/1/other":         http.StatusNotFound,
+	} {
+		resp, err := http.Get(ts.server.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("%s: expected %d, got %d", path, status, resp.StatusCode)
+		}
+	}
+}
+
+func TestSnapshots_CreateInvalid(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp := mustPostResponse(t, ts.server.URL+"/snapshots", map[string]any{"name": ""})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestDeleteDepartment_NotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := deleteRequest(ts.server.URL + "/departments/999?mode=cascade")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		t.Errorf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
 	}
 }
 
-func TestCreateDepartment_InvalidJSON(t *testing.T) {
+func TestDeleteDepartment_InvalidMode(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp, err := http.Post(ts.server.URL+"/departments/", "application/json", bytes.NewBuffer([]byte("invalid")))
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
+
+	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=invalid")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
@@ -511,28 +2170,47 @@ func TestCreateDepartment_InvalidJSON(t *testing.T) {
 	}
 }
 
-func TestGetDepartment_Success(t *testing.T) {
+func TestDeleteDepartment_ReassignWithoutTarget(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
 
-	resp, err := http.Get(ts.server.URL + "/departments/1")
+	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=reassign")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
 	}
 }
 
-func TestGetDepartment_NotFound(t *testing.T) {
+func TestDeleteDepartment_ReassignToSelf(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp, err := http.Get(ts.server.URL + "/departments/999")
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
+
+	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=reassign&reassign_to_department_id=1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestDeleteDepartment_ReassignTargetNotFound(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
+
+	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=reassign&reassign_to_department_id=999")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
@@ -543,49 +2221,75 @@ func TestGetDepartment_NotFound(t *testing.T) {
 	}
 }
 
-func TestGetDepartment_InvalidID(t *testing.T) {
+func TestDeleteDepartment_ReassignSuccess(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp, err := http.Get(ts.server.URL + "/departments/abc")
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Target"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "ToDelete"})
+
+	mustPost(t, ts.server.URL+"/departments/2/employees/", map[string]any{"full_name": "John", "position": "Dev"})
+
+	resp, err := deleteRequest(ts.server.URL + "/departments/2?mode=reassign&reassign_to_department_id=1")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected %d, got %d", http.StatusNoContent, resp.StatusCode)
 	}
 }
 
-func TestUpdateDepartment_Success(t *testing.T) {
+func TestCreateEmployee_Success(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Old Name"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
 
-	resp, err := patchJSON(ts.server.URL+"/departments/1", map[string]any{"name": "New Name"})
+	resp, err := postJSON(ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+	})
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
 	}
+}
 
-	var result dto.DepartmentResponse
-	json.NewDecoder(resp.Body).Decode(&result)
-	if result.Name != "New Name" {
-		t.Errorf("expected 'New Name', got '%s'", result.Name)
+func TestCreateEmployee_WithHiredAt(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+
+	resp, err := postJSON(ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+		"hired_at":  "2024-01-15",
+	})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
 	}
 }
 
-func TestUpdateDepartment_NotFound(t *testing.T) {
+func TestCreateEmployee_DepartmentNotFound(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp, err := patchJSON(ts.server.URL+"/departments/999", map[string]any{"name": "Test"})
+	resp, err := postJSON(ts.server.URL+"/departments/999/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "Developer",
+	})
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
@@ -596,13 +2300,16 @@ func TestUpdateDepartment_NotFound(t *testing.T) {
 	}
 }
 
-func TestUpdateDepartment_SelfReference(t *testing.T) {
+func TestCreateEmployee_EmptyFullName(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
 
-	resp, err := patchJSON(ts.server.URL+"/departments/1", map[string]any{"parent_id": 1})
+	resp, err := postJSON(ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "",
+		"position":  "Developer",
+	})
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
@@ -613,330 +2320,748 @@ func TestUpdateDepartment_SelfReference(t *testing.T) {
 	}
 }
 
-func TestUpdateDepartment_CyclicReference(t *testing.T) {
+func TestCreateEmployee_EmptyPosition(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent"})
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 1})
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "GrandChild", "parent_id": 2})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
 
-	resp, err := patchJSON(ts.server.URL+"/departments/1", map[string]any{"parent_id": 3})
+	resp, err := postJSON(ts.server.URL+"/departments/1/employees/", map[string]any{
+		"full_name": "John Doe",
+		"position":  "",
+	})
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusConflict {
-		t.Errorf("expected %d, got %d", http.StatusConflict, resp.StatusCode)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
 	}
 }
 
-func TestUpdateDepartment_ParentNotFound(t *testing.T) {
+func TestCreateEmployee_MissingFields(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
 
-	resp, err := patchJSON(ts.server.URL+"/departments/1", map[string]any{"parent_id": 999})
+	resp, err := postJSON(ts.server.URL+"/departments/1/employees/", map[string]any{})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestCreateEmployee_InvalidDepartmentID(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := postJSON(ts.server.URL+"/departments/abc/employees/", map[string]any{
+		"full_name": "John",
+		"position":  "Dev",
+	})
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestDryRun_DeleteDoesNotDelete(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Company"})
+	ts.deptSvc.impact = &domain.DepartmentImpact{
+		AffectedDepartmentIDs: []int64{1},
+		DeletedDepartmentIDs:  []int64{1},
+		DeletedEmployees:      3,
+	}
+
+	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=cascade&dry_run=true")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var impact dto.DepartmentImpactResponse
+	decodeJSON(t, resp, http.StatusOK, &impact)
+	if !impact.DryRun || len(impact.DeletedDepartmentIDs) != 1 || impact.DeletedEmployees != 3 || impact.Department != nil {
+		t.Errorf("unexpected impact: %+v", impact)
+	}
+	if _, ok := ts.deptRepo.departments[1]; !ok {
+		t.Error("dry run must not delete the department")
+	}
+
+	resp, err = deleteRequest(ts.server.URL + "/departments/1?dry_run=true")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 without mode, got %d", resp.StatusCode)
+	}
+}
+
+func TestDryRun_CreateAndUpdate(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	ts.deptSvc.impact = &domain.DepartmentImpact{Department: &domain.Department{Name: "Company"}}
+
+	var impact dto.DepartmentImpactResponse
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/departments/?dry_run=true", map[string]any{"name": "Company"}), http.StatusOK, &impact)
+	if impact.Department == nil || impact.Department.Name != "Company" || impact.AffectedDepartmentIDs == nil {
+		t.Errorf("unexpected impact: %+v", impact)
+	}
+	if len(ts.deptRepo.departments) != 0 {
+		t.Errorf("dry run must not create departments, got %+v", ts.deptRepo.departments)
+	}
+
+	resp, err := patchJSON(ts.server.URL+"/departments/9?dry_run=true", map[string]any{"name": "IT"})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
 	if resp.StatusCode != http.StatusNotFound {
-		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+		t.Errorf("expected 404 for missing department, got %d", resp.StatusCode)
+	}
+}
+
+func TestMergeDepartment(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Team A"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Team B"})
+
+	tests := []struct {
+		name   string
+		url    string
+		body   map[string]any
+		status int
+	}{
+		{"missing target", "/departments/1/merge", map[string]any{}, http.StatusBadRequest},
+		{"unknown policy", "/departments/1/merge", map[string]any{"target_department_id": 2, "on_conflict": "skip"}, http.StatusBadRequest},
+		{"into itself", "/departments/1/merge", map[string]any{"target_department_id": 1}, http.StatusBadRequest},
+		{"target not found", "/departments/1/merge", map[string]any{"target_department_id": 99}, http.StatusNotFound},
+		{"source not found", "/departments/99/merge", map[string]any{"target_department_id": 1}, http.StatusNotFound},
+		{"dry run", "/departments/1/merge?dry_run=true", map[string]any{"target_department_id": 2}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := mustPostResponse(t, ts.server.URL+tt.url, tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+	if _, ok := ts.deptRepo.departments[1]; !ok {
+		t.Fatal("dry run must not remove the source department")
+	}
+
+	var result dto.DepartmentImpactResponse
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/departments/1/merge", map[string]any{"target_department_id": 2, "on_conflict": "suffix"}), http.StatusOK, &result)
+	if result.DryRun || result.Department == nil || result.Department.ID != 2 || len(result.DeletedDepartmentIDs) != 1 {
+		t.Errorf("unexpected merge result: %+v", result)
+	}
+	if _, ok := ts.deptRepo.departments[1]; ok {
+		t.Error("expected source department to be removed")
+	}
+}
+
+func TestCloneDepartment(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Office"})
+
+	var clone dto.DepartmentResponse
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/departments/1/clone", map[string]any{"name_suffix": " Berlin"}), http.StatusCreated, &clone)
+	if clone.ID != 2 || clone.Name != "Office Berlin" {
+		t.Errorf("unexpected clone: %+v", clone)
+	}
+
+	tests := []struct {
+		name   string
+		url    string
+		body   map[string]any
+		status int
+	}{
+		{"duplicate name", "/departments/1/clone", map[string]any{}, http.StatusConflict},
+		{"long prefix", "/departments/1/clone", map[string]any{"name_prefix": strings.Repeat("x", 101)}, http.StatusBadRequest},
+		{"source not found", "/departments/99/clone", map[string]any{"name_suffix": " 2"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := mustPostResponse(t, ts.server.URL+tt.url, tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
 	}
 }
 
-func TestUpdateDepartment_DuplicateName(t *testing.T) {
+func TestDepartmentHeadAndManagers(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept1"})
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept2"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Company"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Sales", "parent_id": 1})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{"full_name": "Anna", "position": "CEO"})
+	mustPost(t, ts.server.URL+"/departments/2/employees/", map[string]any{"full_name": "John", "position": "Dev"})
 
-	resp, err := patchJSON(ts.server.URL+"/departments/2", map[string]any{"name": "Dept1"})
+	resp, err := putJSON(ts.server.URL+"/departments/1/head", map[string]any{"employee_id": 1})
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
+	var dept dto.DepartmentResponse
+	decodeJSON(t, resp, http.StatusOK, &dept)
+	if dept.HeadEmployeeID == nil || *dept.HeadEmployeeID != 1 {
+		t.Fatalf("expected head 1, got %v", dept.HeadEmployeeID)
+	}
 
-	if resp.StatusCode != http.StatusConflict {
-		t.Errorf("expected %d, got %d", http.StatusConflict, resp.StatusCode)
+	resp, err = http.Get(ts.server.URL + "/employees/2/managers")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var managers []dto.ManagerResponse
+	decodeJSON(t, resp, http.StatusOK, &managers)
+	if len(managers) != 1 || managers[0].Employee.ID != 1 || managers[0].Department.Name != "Company" {
+		t.Fatalf("unexpected managers: %+v", managers)
+	}
+
+	resp, err = deleteRequest(ts.server.URL + "/departments/1/head")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	decodeJSON(t, resp, http.StatusOK, &dept)
+	if dept.HeadEmployeeID != nil {
+		t.Errorf("expected head to be removed, got %d", *dept.HeadEmployeeID)
+	}
+
+	tests := []struct {
+		name   string
+		url    string
+		body   map[string]any
+		status int
+	}{
+		{"missing employee_id", "/departments/1/head", map[string]any{}, http.StatusBadRequest},
+		{"employee not found", "/departments/1/head", map[string]any{"employee_id": 99}, http.StatusNotFound},
+		{"department not found", "/departments/99/head", map[string]any{"employee_id": 1}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := putJSON(ts.server.URL+tt.url, tt.body)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+
+	resp, err = http.Get(ts.server.URL + "/employees/99/managers")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown employee, got %d", resp.StatusCode)
 	}
 }
 
-func TestUpdateDepartment_MoveToAnotherParent(t *testing.T) {
+func TestReportsAndSpanOfControl(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent1"})
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Parent2"})
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Child", "parent_id": 1})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Company"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{"full_name": "Anna", "position": "CEO"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{"full_name": "John", "position": "Dev"})
+	resp, err := putJSON(ts.server.URL+"/departments/1/head", map[string]any{"employee_id": 1})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
 
-	resp, err := patchJSON(ts.server.URL+"/departments/3", map[string]any{"parent_id": 2})
+	resp, err = http.Get(ts.server.URL + "/employees/1/reports?recursive=true")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
+	var reports []dto.ReportResponse
+	decodeJSON(t, resp, http.StatusOK, &reports)
+	if len(reports) != 1 || reports[0].Employee.ID != 2 || reports[0].ManagerID != 1 || reports[0].Depth != 1 {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	if q := ts.management.reportQueries; len(q) != 1 || !q[0].Recursive {
+		t.Errorf("expected recursive query, got %+v", q)
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	resp, err = http.Get(ts.server.URL + "/org/span-of-control?threshold=5")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var span dto.SpanOfControlResponse
+	decodeJSON(t, resp, http.StatusOK, &span)
+	if span.Threshold != 5 || span.Items == nil {
+		t.Errorf("unexpected span of control: %+v", span)
+	}
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"unknown employee", "/employees/99/reports", http.StatusNotFound},
+		{"invalid threshold", "/org/span-of-control?threshold=many", http.StatusBadRequest},
+		{"negative threshold", "/org/span-of-control?threshold=-1", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(ts.server.URL + tt.url)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
 	}
 }
 
-func TestDeleteDepartment_Cascade(t *testing.T) {
+func TestDrafts_Workflow(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "ToDelete"})
+	var draft dto.DraftResponse
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/drafts", map[string]any{"name": "Q3 reorg"}), http.StatusCreated, &draft)
+	if draft.Status != domain.DraftStatusOpen || len(draft.Operations) != 0 {
+		t.Fatalf("unexpected draft: %+v", draft)
+	}
 
-	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=cascade")
+	base := ts.server.URL + "/drafts/" + strconv.FormatInt(draft.ID, 10)
+	var op dto.DraftOperationResponse
+	decodeJSON(t, mustPostResponse(t, base+"/operations", map[string]any{"type": "create", "ref": "platform", "name": "Platform", "parent_id": 1}), http.StatusCreated, &op)
+	if op.Position != 1 || op.Ref == nil || *op.Ref != "platform" || op.DepartmentRef != nil {
+		t.Errorf("unexpected operation: %+v", op)
+	}
+	mustPost(t, base+"/operations", map[string]any{"type": "move", "department_id": 2, "parent_ref": "platform"})
+
+	resp, err := deleteRequest(base + "/operations/2")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
-
+	resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		t.Errorf("expected %d, got %d", http.StatusNoContent, resp.StatusCode)
+		t.Errorf("expected 204, got %d", resp.StatusCode)
 	}
-}
 
-func TestDeleteDepartment_NotFound(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+	resp, err = http.Get(base)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	decodeJSON(t, resp, http.StatusOK, &draft)
+	if len(draft.Operations) != 1 {
+		t.Errorf("expected one operation left, got %+v", draft.Operations)
+	}
 
-	resp, err := deleteRequest(ts.server.URL + "/departments/999?mode=cascade")
+	resp, err = http.Get(base + "/preview?depth=2")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
+	var tree []dto.DepartmentResponse
+	decodeJSON(t, resp, http.StatusOK, &tree)
+	if len(tree) != 1 || tree[0].Name != "Company" {
+		t.Errorf("unexpected preview: %+v", tree)
+	}
 
-	if resp.StatusCode != http.StatusNotFound {
-		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	decodeJSON(t, mustPostResponse(t, base+"/apply", nil), http.StatusOK, &draft)
+	if draft.Status != domain.DraftStatusApplied || draft.ClosedAt == nil {
+		t.Errorf("expected applied draft, got %+v", draft)
+	}
+
+	resp = mustPostResponse(t, base+"/discard", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 for closed draft, got %d", resp.StatusCode)
 	}
 }
 
-func TestDeleteDepartment_InvalidMode(t *testing.T) {
+func TestDrafts_ApplyReportsFailedOperation(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
+	mustPost(t, ts.server.URL+"/drafts", map[string]any{"name": "reorg"})
+	ts.draft.applyErr = &domain.DraftOperationError{Position: 3, Type: domain.DraftOperationMove, Err: domain.ErrCyclicReference}
 
-	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=invalid")
-	if err != nil {
-		t.Fatalf("request failed: %v", err)
+	var errResp dto.ErrorResponse
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/drafts/1/apply", nil), http.StatusConflict, &errResp)
+	if errResp.Message != "operation 3 (move)" {
+		t.Errorf("expected failed operation in message, got %+v", errResp)
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+func TestDrafts_InvalidRequests(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.Close()
+
+	mustPost(t, ts.server.URL+"/drafts", map[string]any{"name": "reorg"})
+
+	tests := []struct {
+		name   string
+		url    string
+		body   map[string]any
+		status int
+	}{
+		{"empty name", "/drafts", map[string]any{"name": ""}, http.StatusBadRequest},
+		{"unknown type", "/drafts/1/operations", map[string]any{"type": "split"}, http.StatusBadRequest},
+		{"unknown mode", "/drafts/1/operations", map[string]any{"type": "delete", "department_id": 2, "mode": "drop"}, http.StatusBadRequest},
+		{"draft not found", "/drafts/9/operations", map[string]any{"type": "create", "name": "HR"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := mustPostResponse(t, ts.server.URL+tt.url, tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
 	}
 }
 
-func TestDeleteDepartment_ReassignWithoutTarget(t *testing.T) {
+func TestDelegations_Workflow(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
-
-	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=reassign")
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Sales"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{"full_name": "Anna", "position": "Head"})
+	mustPost(t, ts.server.URL+"/departments/1/employees/", map[string]any{"full_name": "Bob", "position": "Deputy"})
+	resp, err := putJSON(ts.server.URL+"/departments/1/head", map[string]any{"employee_id": 1})
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
+	resp.Body.Close()
 
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	body := map[string]any{"department_id": 1, "delegator_id": 1, "delegate_id": 2, "starts_on": "2030-07-01", "ends_on": "2030-07-14"}
+	var delegation dto.DelegationResponse
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/delegations", body), http.StatusCreated, &delegation)
+	if delegation.ID != 1 || delegation.StartsOn != "2030-07-01" || delegation.EndsOn != "2030-07-14" || delegation.RevokedAt != nil {
+		t.Fatalf("unexpected delegation: %+v", delegation)
 	}
-}
-
-func TestDeleteDepartment_ReassignToSelf(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
-
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
 
-	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=reassign&reassign_to_department_id=1")
+	resp, err = http.Get(ts.server.URL + "/delegations?department_id=1")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	var list dto.DelegationListResponse
+	decodeJSON(t, resp, http.StatusOK, &list)
+	if len(list.Items) != 1 || list.NextCursor != nil {
+		t.Errorf("unexpected list: %+v", list)
 	}
-}
 
-func TestDeleteDepartment_ReassignTargetNotFound(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/delegations/1/revoke", nil), http.StatusOK, &delegation)
+	if delegation.RevokedAt == nil || delegation.RevokedBy == nil {
+		t.Errorf("expected delegation to be revoked, got %+v", delegation)
+	}
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Dept"})
+	resp = mustPostResponse(t, ts.server.URL+"/delegations/1/revoke", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 for repeated revocation, got %d", resp.StatusCode)
+	}
 
-	resp, err := deleteRequest(ts.server.URL + "/departments/1?mode=reassign&reassign_to_department_id=999")
+	resp, err = http.Get(ts.server.URL + "/delegations/9")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
-
+	resp.Body.Close()
 	if resp.StatusCode != http.StatusNotFound {
-		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+		t.Errorf("expected 404, got %d", resp.StatusCode)
 	}
 }
 
-func TestDeleteDepartment_ReassignSuccess(t *testing.T) {
+func TestDelegations_InvalidRequests(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Target"})
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "ToDelete"})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "Sales"})
 
-	mustPost(t, ts.server.URL+"/departments/2/employees/", map[string]any{"full_name": "John", "position": "Dev"})
+	valid := func(overrides map[string]any) map[string]any {
+		body := map[string]any{"department_id": 1, "delegator_id": 1, "delegate_id": 2, "starts_on": "2030-07-01", "ends_on": "2030-07-14"}
+		for k, v := range overrides {
+			body[k] = v
+		}
+		return body
+	}
+
+	tests := []struct {
+		name   string
+		body   map[string]any
+		status int
+	}{
+		{"delegate is delegator", valid(map[string]any{"delegate_id": 1}), http.StatusBadRequest},
+		{"invalid date", valid(map[string]any{"starts_on": "01.07.2030"}), http.StatusBadRequest},
+		{"missing end", valid(map[string]any{"ends_on": ""}), http.StatusBadRequest},
+		{"department not found", valid(map[string]any{"department_id": 9}), http.StatusNotFound},
+		{"delegator is not the head", valid(nil), http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := mustPostResponse(t, ts.server.URL+"/delegations", tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
 
-	resp, err := deleteRequest(ts.server.URL + "/departments/2?mode=reassign&reassign_to_department_id=1")
+	resp, err := http.Get(ts.server.URL + "/delegations?active_on=tomorrow")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusNoContent {
-		t.Errorf("expected %d, got %d", http.StatusNoContent, resp.StatusCode)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid active_on, got %d", resp.StatusCode)
 	}
 }
 
-func TestCreateEmployee_Success(t *testing.T) {
+func TestPositions_Workflow(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	var position dto.PositionResponse
+	body := map[string]any{"title": "Backend Developer", "job_family": "Engineering", "grade": 5}
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/positions", body), http.StatusCreated, &position)
+	if position.ID != 1 || position.Title != "Backend Developer" || position.JobFamily == nil || *position.JobFamily != "Engineering" || position.Grade == nil || *position.Grade != 5 {
+		t.Fatalf("unexpected position: %+v", position)
+	}
 
-	resp, err := postJSON(ts.server.URL+"/departments/1/employees/", map[string]any{
-		"full_name": "John Doe",
-		"position":  "Developer",
-	})
+	resp := mustPostResponse(t, ts.server.URL+"/positions", map[string]any{"title": "backend developer"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 for duplicate title, got %d", resp.StatusCode)
+	}
+
+	mustPost(t, ts.server.URL+"/positions", map[string]any{"title": "Recruiter", "job_family": "HR"})
+
+	resp, err := http.Get(ts.server.URL + "/positions?job_family=Engineering")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated {
-		t.Errorf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
+	var list dto.PositionListResponse
+	decodeJSON(t, resp, http.StatusOK, &list)
+	if len(list.Items) != 1 || list.Items[0].ID != 1 || list.NextCursor != nil {
+		t.Errorf("unexpected list: %+v", list)
 	}
-}
 
-func TestCreateEmployee_WithHiredAt(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
+	resp, err = patchJSON(ts.server.URL+"/positions/1", map[string]any{"title": "Senior Backend Developer", "grade": 6})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	decodeJSON(t, resp, http.StatusOK, &position)
+	if position.Title != "Senior Backend Developer" || *position.Grade != 6 || *position.JobFamily != "Engineering" {
+		t.Errorf("unexpected position after update: %+v", position)
+	}
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	ts.positions.used[1] = true
+	resp, err = deleteRequest(ts.server.URL + "/positions/1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 for position in use, got %d", resp.StatusCode)
+	}
 
-	resp, err := postJSON(ts.server.URL+"/departments/1/employees/", map[string]any{
-		"full_name": "John Doe",
-		"position":  "Developer",
-		"hired_at":  "2024-01-15",
-	})
+	resp, err = deleteRequest(ts.server.URL + "/positions/2")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", resp.StatusCode)
+	}
 
-	if resp.StatusCode != http.StatusCreated {
-		t.Errorf("expected %d, got %d", http.StatusCreated, resp.StatusCode)
+	resp, err = http.Get(ts.server.URL + "/positions/2")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", resp.StatusCode)
 	}
 }
 
-func TestCreateEmployee_DepartmentNotFound(t *testing.T) {
+func TestPositions_InvalidRequests(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp, err := postJSON(ts.server.URL+"/departments/999/employees/", map[string]any{
-		"full_name": "John Doe",
-		"position":  "Developer",
-	})
-	if err != nil {
-		t.Fatalf("request failed: %v", err)
+	tests := []struct {
+		name   string
+		body   map[string]any
+		status int
+	}{
+		{"missing title", map[string]any{"job_family": "Engineering"}, http.StatusBadRequest},
+		{"zero grade", map[string]any{"title": "Analyst", "grade": 0}, http.StatusBadRequest},
+		{"job family too long", map[string]any{"title": "Analyst", "job_family": strings.Repeat("x", 101)}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := mustPostResponse(t, ts.server.URL+"/positions", tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNotFound {
-		t.Errorf("expected %d, got %d", http.StatusNotFound, resp.StatusCode)
+	for _, url := range []string{"/positions?limit=0", "/positions/abc", "/positions/1/employees"} {
+		resp, err := http.Get(ts.server.URL + url)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest && resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: expected 400 or 404, got %d", url, resp.StatusCode)
+		}
 	}
 }
 
-func TestCreateEmployee_EmptyFullName(t *testing.T) {
+func TestCreateEmployee_PositionReference(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
 	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
 
-	resp, err := postJSON(ts.server.URL+"/departments/1/employees/", map[string]any{
-		"full_name": "",
-		"position":  "Developer",
-	})
+	var emp dto.EmployeeResponse
+	body := map[string]any{"full_name": "Anna", "position_id": 3}
+	decodeJSON(t, mustPostResponse(t, ts.server.URL+"/departments/1/employees/", body), http.StatusCreated, &emp)
+	if emp.PositionID == nil || *emp.PositionID != 3 {
+		t.Errorf("expected position_id 3, got %+v", emp)
+	}
+
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{"no position", map[string]any{"full_name": "Bob"}},
+		{"position and position_id", map[string]any{"full_name": "Bob", "position": "Dev", "position_id": 3}},
+		{"invalid position_id", map[string]any{"full_name": "Bob", "position_id": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := mustPostResponse(t, ts.server.URL+"/departments/1/employees/", tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", resp.StatusCode)
+			}
+		})
+	}
+
+	resp, err := patchJSON(ts.server.URL+"/employees/1", map[string]any{"position": "Dev", "position_id": 3})
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
-
+	resp.Body.Close()
 	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+		t.Errorf("expected 400 for update with position and position_id, got %d", resp.StatusCode)
 	}
 }
 
-func TestCreateEmployee_EmptyPosition(t *testing.T) {
+func TestHeadcount_Workflow(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
 	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
 
-	resp, err := postJSON(ts.server.URL+"/departments/1/employees/", map[string]any{
-		"full_name": "John Doe",
-		"position":  "",
-	})
+	resp, err := putJSON(ts.server.URL+"/departments/1/headcount/5", map[string]any{"approved": 3})
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	var slot dto.HeadcountSlotResponse
+	decodeJSON(t, resp, http.StatusOK, &slot)
+	if slot.DepartmentID != 1 || slot.PositionID != 5 || slot.Approved != 3 || slot.Open != 3 {
+		t.Errorf("unexpected slot: %+v", slot)
 	}
-}
-
-func TestCreateEmployee_MissingFields(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Close()
 
-	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+	ts.headcount.slots[0].Filled = 1
+	resp, err = http.Get(ts.server.URL + "/departments/1/vacancies")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var vacancies dto.VacanciesResponse
+	decodeJSON(t, resp, http.StatusOK, &vacancies)
+	if vacancies.Approved != 3 || vacancies.Filled != 1 || vacancies.Open != 2 || len(vacancies.Items) != 1 || vacancies.Items[0].Open != 2 {
+		t.Errorf("unexpected vacancies: %+v", vacancies)
+	}
 
-	resp, err := postJSON(ts.server.URL+"/departments/1/employees/", map[string]any{})
+	resp, err = deleteRequest(ts.server.URL + "/departments/1/headcount/5")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", resp.StatusCode)
+	}
 
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	resp, err = deleteRequest(ts.server.URL + "/departments/1/headcount/5")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for missing slot, got %d", resp.StatusCode)
 	}
 }
 
-func TestCreateEmployee_InvalidDepartmentID(t *testing.T) {
+func TestHeadcount_InvalidRequests(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.Close()
 
-	resp, err := postJSON(ts.server.URL+"/departments/abc/employees/", map[string]any{
-		"full_name": "John",
-		"position":  "Dev",
-	})
+	mustPost(t, ts.server.URL+"/departments/", map[string]any{"name": "IT"})
+
+	tests := []struct {
+		name   string
+		url    string
+		body   map[string]any
+		status int
+	}{
+		{"missing approved", "/departments/1/headcount/5", map[string]any{}, http.StatusBadRequest},
+		{"negative approved", "/departments/1/headcount/5", map[string]any{"approved": -1}, http.StatusBadRequest},
+		{"invalid position id", "/departments/1/headcount/abc", map[string]any{"approved": 1}, http.StatusBadRequest},
+		{"department not found", "/departments/9/headcount/5", map[string]any{"approved": 1}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := putJSON(ts.server.URL+tt.url, tt.body)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+
+	resp, err := http.Get(ts.server.URL + "/departments/9/vacancies")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
 	}
 }
 
@@ -1026,7 +3151,17 @@ func BenchmarkCreateDepartment(b *testing.B) {
 	deptService := &mockDepartmentService{deptRepo: deptRepo, empRepo: empRepo}
 	empService := &mockEmployeeService{empRepo: empRepo, deptRepo: deptRepo}
 	deptHandler := handler.NewDepartmentHandler(deptService, empService, logger)
-	router := handler.NewRouter(deptHandler, logger)
+	empHandler := handler.NewEmployeeHandler(empService, logger)
+	trashHandler := handler.NewTrashHandler(&mockTrashService{deptRepo: deptRepo, empRepo: empRepo}, logger)
+	auditHandler := handler.NewAuditHandler(&mockAuditService{}, logger)
+	diffHandler := handler.NewDiffHandler(&mockDiffService{}, logger)
+	snapshotHandler := handler.NewSnapshotHandler(newMockSnapshotService(), logger)
+	draftHandler := handler.NewDraftHandler(newMockDraftService(), logger)
+	managementHandler := handler.NewManagementHandler(&mockManagementService{deptRepo: deptRepo, empRepo: empRepo}, logger)
+	delegationHandler := handler.NewDelegationHandler(&mockDelegationService{deptRepo: deptRepo, delegations: make(map[int64]*domain.Delegation)}, logger)
+	positionHandler := handler.NewPositionHandler(newMockPositionService(), logger)
+	headcountHandler := handler.NewHeadcountHandler(&mockHeadcountService{deptRepo: deptRepo}, logger)
+	router := handler.NewRouter(deptHandler, empHandler, trashHandler, auditHandler, diffHandler, snapshotHandler, draftHandler, managementHandler, delegationHandler, positionHandler, headcountHandler, logger)
 	server := httptest.NewServer(router.Setup())
 	defer server.Close()
 
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+type HeadcountHandler struct {
+	baseHandler
+	headcountService service.HeadcountService
+}
+
+func NewHeadcountHandler(headcountService service.HeadcountService, logger *slog.Logger) *HeadcountHandler {
+	return &HeadcountHandler{
+		baseHandler:      newBaseHandler(logger),
+		headcountService: headcountService,
+	}
+}
+
+func (h *HeadcountHandler) SetSlot(w http.ResponseWriter, r *http.Request) {
+	departmentID, positionID, ok := h.extractSlotIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.SetHeadcountSlotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	slot, err := h.headcountService.SetSlot(r.Context(), departmentID, positionID, &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toSlotResponse(slot))
+}
+
+func (h *HeadcountHandler) RemoveSlot(w http.ResponseWriter, r *http.Request) {
+	departmentID, positionID, ok := h.extractSlotIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.headcountService.RemoveSlot(r.Context(), departmentID, positionID); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HeadcountHandler) GetVacancies(w http.ResponseWriter, r *http.Request) {
+	id, err := extractPathID(r, "/departments/")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid department id", err.Error())
+		return
+	}
+
+	vacancies, err := h.headcountService.GetVacancies(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.VacanciesResponse{
+		DepartmentID: vacancies.DepartmentID,
+		Approved:     vacancies.Approved,
+		Filled:       vacancies.Filled,
+		Open:         vacancies.Open,
+		Items:        make([]dto.HeadcountSlotResponse, len(vacancies.Slots)),
+	}
+	for i := range vacancies.Slots {
+		resp.Items[i] = h.toSlotResponse(&vacancies.Slots[i])
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// extractSlotIDs разбирает путь /departments/{id}/headcount/{position_id}; при ошибке
+// отвечает 400 и возвращает ok = false
+func (h *HeadcountHandler) extractSlotIDs(w http.ResponseWriter, r *http.Request) (departmentID, positionID int64, ok bool) {
+	departmentID, err := extractPathID(r, "/departments/")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid department id", err.Error())
+		return 0, 0, false
+	}
+
+	// /departments/{id}/headcount/{positionID}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	positionID, err = strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid position id", err.Error())
+		return 0, 0, false
+	}
+
+	return departmentID, positionID, true
+}
+
+func (h *HeadcountHandler) toSlotResponse(slot *domain.HeadcountSlot) dto.HeadcountSlotResponse {
+	return dto.HeadcountSlotResponse{
+		DepartmentID:  slot.DepartmentID,
+		PositionID:    slot.PositionID,
+		PositionTitle: slot.PositionTitle,
+		Approved:      slot.Approved,
+		Filled:        slot.Filled,
+		Open:          slot.Open(),
+		UpdatedAt:     slot.UpdatedAt,
+	}
+}
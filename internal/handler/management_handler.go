@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+type ManagementHandler struct {
+	baseHandler
+	managementService service.ManagementService
+}
+
+func NewManagementHandler(managementService service.ManagementService, logger *slog.Logger) *ManagementHandler {
+	return &ManagementHandler{
+		baseHandler:       newBaseHandler(logger),
+		managementService: managementService,
+	}
+}
+
+func (h *ManagementHandler) GetManagers(w http.ResponseWriter, r *http.Request) {
+	id, err := extractPathID(r, "/employees/")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid employee id", err.Error())
+		return
+	}
+
+	managers, err := h.managementService.GetManagers(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := make([]dto.ManagerResponse, len(managers))
+	for i, manager := range managers {
+		resp[i] = h.toManagerResponse(&manager)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *ManagementHandler) GetReports(w http.ResponseWriter, r *http.Request) {
+	id, err := extractPathID(r, "/employees/")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid employee id", err.Error())
+		return
+	}
+
+	query := dto.ReportsQuery{Recursive: r.URL.Query().Get("recursive") == "true"}
+
+	reports, err := h.managementService.GetReports(r.Context(), id, &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := make([]dto.ReportResponse, len(reports))
+	for i, report := range reports {
+		resp[i] = dto.ReportResponse{
+			Employee:  h.toEmployeeResponse(&report.Employee),
+			ManagerID: report.ManagerID,
+			Depth:     report.Depth,
+		}
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *ManagementHandler) SpanOfControl(w http.ResponseWriter, r *http.Request) {
+	var query dto.SpanOfControlQuery
+	if thresholdStr := r.URL.Query().Get("threshold"); thresholdStr != "" {
+		threshold, err := strconv.Atoi(thresholdStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid query parameters", "invalid threshold: "+err.Error())
+			return
+		}
+		query.Threshold = &threshold
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	spans, threshold, err := h.managementService.GetSpanOfControl(r.Context(), &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.SpanOfControlResponse{Threshold: threshold, Items: make([]dto.ManagerSpanResponse, len(spans))}
+	for i, span := range spans {
+		resp.Items[i] = dto.ManagerSpanResponse{
+			Manager:       h.toEmployeeResponse(&span.Manager),
+			DirectReports: span.DirectReports,
+		}
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *ManagementHandler) toManagerResponse(manager *domain.Manager) dto.ManagerResponse {
+	resp := dto.ManagerResponse{
+		Department: dto.DepartmentPathItem{ID: manager.Department.ID, Name: manager.Department.Name},
+		Employee:   h.toEmployeeResponse(&manager.Employee),
+	}
+	if manager.Delegation != nil {
+		resp.ActingForEmployeeID = &manager.Delegation.DelegatorID
+		resp.DelegationID = &manager.Delegation.ID
+	}
+	return resp
+}
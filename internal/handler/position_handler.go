@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+type PositionHandler struct {
+	baseHandler
+	positionService service.PositionService
+}
+
+func NewPositionHandler(positionService service.PositionService, logger *slog.Logger) *PositionHandler {
+	return &PositionHandler{
+		baseHandler:     newBaseHandler(logger),
+		positionService: positionService,
+	}
+}
+
+func (h *PositionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreatePositionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	position, err := h.positionService.Create(r.Context(), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, h.toPositionResponse(position))
+}
+
+func (h *PositionHandler) List(w http.ResponseWriter, r *http.Request) {
+	query, err := h.parseListQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	positions, nextCursor, err := h.positionService.List(r.Context(), &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.PositionListResponse{
+		Items: make([]dto.PositionResponse, len(positions)),
+	}
+	for i, position := range positions {
+		resp.Items[i] = h.toPositionResponse(&position)
+	}
+	if nextCursor != "" {
+		resp.NextCursor = &nextCursor
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *PositionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid position id", err.Error())
+		return
+	}
+
+	position, err := h.positionService.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toPositionResponse(position))
+}
+
+func (h *PositionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid position id", err.Error())
+		return
+	}
+
+	var req dto.UpdatePositionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	position, err := h.positionService.Update(r.Context(), id, &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toPositionResponse(position))
+}
+
+func (h *PositionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid position id", err.Error())
+		return
+	}
+
+	if err := h.positionService.Delete(r.Context(), id); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PositionHandler) extractID(r *http.Request) (int64, error) {
+	return extractPathID(r, "/positions/")
+}
+
+func (h *PositionHandler) parseListQuery(r *http.Request) (dto.ListPositionsQuery, error) {
+	values := r.URL.Query()
+	query := dto.ListPositionsQuery{
+		Limit:     20,
+		Cursor:    values.Get("cursor"),
+		Title:     values.Get("title"),
+		JobFamily: values.Get("job_family"),
+	}
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit: %w", err)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+func (h *PositionHandler) toPositionResponse(position *domain.Position) dto.PositionResponse {
+	return dto.PositionResponse{
+		ID:        position.ID,
+		Title:     position.Title,
+		JobFamily: position.JobFamily,
+		Grade:     position.Grade,
+		CreatedAt: position.CreatedAt,
+		UpdatedAt: position.UpdatedAt,
+	}
+}
@@ -10,17 +10,50 @@ import (
 
 // Router настраивает маршруты API
 type Router struct {
-	mux     *http.ServeMux
-	logger  *slog.Logger
-	deptHandler *DepartmentHandler
+	mux         *http.ServeMux
+	logger      *slog.Logger
+	deptHandler  *DepartmentHandler
+	empHandler   *EmployeeHandler
+	trashHandler *TrashHandler
+	auditHandler *AuditHandler
+	diffHandler  *DiffHandler
+	snapshotHandler *SnapshotHandler
+	draftHandler    *DraftHandler
+	managementHandler *ManagementHandler
+	delegationHandler *DelegationHandler
+	positionHandler   *PositionHandler
+	headcountHandler  *HeadcountHandler
 }
 
 // NewRouter создаёт новый роутер
-func NewRouter(deptHandler *DepartmentHandler, logger *slog.Logger) *Router {
+func NewRouter(
+	deptHandler *DepartmentHandler,
+	empHandler *EmployeeHandler,
+	trashHandler *TrashHandler,
+	auditHandler *AuditHandler,
+	diffHandler *DiffHandler,
+	snapshotHandler *SnapshotHandler,
+	draftHandler *DraftHandler,
+	managementHandler *ManagementHandler,
+	delegationHandler *DelegationHandler,
+	positionHandler *PositionHandler,
+	headcountHandler *HeadcountHandler,
+	logger *slog.Logger,
+) *Router {
 	return &Router{
-		mux:         http.NewServeMux(),
-		logger:      logger,
-		deptHandler: deptHandler,
+		mux:          http.NewServeMux(),
+		logger:       logger,
+		deptHandler:  deptHandler,
+		empHandler:   empHandler,
+		trashHandler: trashHandler,
+		auditHandler: auditHandler,
+		diffHandler:  diffHandler,
+		snapshotHandler: snapshotHandler,
+		draftHandler:    draftHandler,
+		managementHandler: managementHandler,
+		delegationHandler: delegationHandler,
+		positionHandler:   positionHandler,
+		headcountHandler:  headcountHandler,
 	}
 }
 
@@ -28,6 +61,18 @@ func NewRouter(deptHandler *DepartmentHandler, logger *slog.Logger) *Router {
 func (r *Router) Setup() http.Handler {
 	// Регистрируем обработчики
 	r.mux.HandleFunc("/departments/", r.departmentsRouter)
+	r.mux.HandleFunc("/employees/", r.employeesRouter)
+	r.mux.HandleFunc("/org/", r.orgRouter)
+	r.mux.HandleFunc("/trash/", r.trashRouter)
+	r.mux.HandleFunc("/audit", r.auditRouter)
+	r.mux.HandleFunc("/snapshots", r.snapshotsRouter)
+	r.mux.HandleFunc("/snapshots/", r.snapshotsRouter)
+	r.mux.HandleFunc("/drafts", r.draftsRouter)
+	r.mux.HandleFunc("/drafts/", r.draftsRouter)
+	r.mux.HandleFunc("/delegations", r.delegationsRouter)
+	r.mux.HandleFunc("/delegations/", r.delegationsRouter)
+	r.mux.HandleFunc("/positions", r.positionsRouter)
+	r.mux.HandleFunc("/positions/", r.positionsRouter)
 	
 	// Health check
 	r.mux.HandleFunc("/health", func(w http.ResponseWriter, req *http.Request) {
@@ -37,7 +82,9 @@ func (r *Router) Setup() http.Handler {
 	
 	// Применяем middleware
 	handler := middleware.ContentType(r.mux)
+	handler = middleware.Actor(handler)
 	handler = middleware.Logger(r.logger)(handler)
+	handler = middleware.RequestID(handler)
 	handler = middleware.Recoverer(r.logger)(handler)
 	
 	return handler
@@ -48,9 +95,17 @@ func (r *Router) departmentsRouter(w http.ResponseWriter, req *http.Request) {
 	path := strings.TrimPrefix(req.URL.Path, "/departments")
 	path = strings.Trim(path, "/")
 	
-	// POST /departments/ - создание подразделения
-	if path == "" && req.Method == http.MethodPost {
-		r.deptHandler.Create(w, req)
+	if path == "" {
+		switch req.Method {
+		case http.MethodGet:
+			// GET /departments/ - список корневых подразделений
+			r.deptHandler.List(w, req)
+		case http.MethodPost:
+			// POST /departments/ - создание подразделения
+			r.deptHandler.Create(w, req)
+		default:
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		}
 		return
 	}
 	
@@ -72,15 +127,411 @@ func (r *Router) departmentsRouter(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	
+	if len(parts) == 2 && parts[1] == "restore" {
+		// /departments/{id}/restore
+		if req.Method == http.MethodPost {
+			r.deptHandler.Restore(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "merge" {
+		// /departments/{id}/merge
+		if req.Method == http.MethodPost {
+			r.deptHandler.Merge(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "clone" {
+		// /departments/{id}/clone
+		if req.Method == http.MethodPost {
+			r.deptHandler.Clone(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "head" {
+		// /departments/{id}/head
+		switch req.Method {
+		case http.MethodPut:
+			r.deptHandler.SetHead(w, req)
+		case http.MethodDelete:
+			r.deptHandler.RemoveHead(w, req)
+		default:
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "vacancies" {
+		// /departments/{id}/vacancies
+		if req.Method == http.MethodGet {
+			r.headcountHandler.GetVacancies(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(parts) == 3 && parts[1] == "headcount" {
+		// /departments/{id}/headcount/{position_id}
+		switch req.Method {
+		case http.MethodPut:
+			r.headcountHandler.SetSlot(w, req)
+		case http.MethodDelete:
+			r.headcountHandler.RemoveSlot(w, req)
+		default:
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "ancestors" {
+		// /departments/{id}/ancestors
+		if req.Method == http.MethodGet {
+			r.deptHandler.GetAncestors(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
 	if len(parts) == 2 && parts[1] == "employees" {
 		// /departments/{id}/employees/
-		if req.Method == http.MethodPost {
+		switch req.Method {
+		case http.MethodGet:
+			r.deptHandler.ListEmployees(w, req)
+		case http.MethodPost:
 			r.deptHandler.CreateEmployee(w, req)
+		default:
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	
+	http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+}
+
+// employeesRouter обрабатывает все запросы к /employees/
+func (r *Router) employeesRouter(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/employees")
+	path = strings.Trim(path, "/")
+
+	parts := strings.Split(path, "/")
+
+	if len(parts) == 1 && parts[0] != "" {
+		// /employees/{id}
+		switch req.Method {
+		case http.MethodGet:
+			r.empHandler.GetByID(w, req)
+		case http.MethodPatch:
+			r.empHandler.Update(w, req)
+		case http.MethodDelete:
+			r.empHandler.Delete(w, req)
+		default:
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "transfer" {
+		// /employees/{id}/transfer
+		if req.Method == http.MethodPost {
+			r.empHandler.Transfer(w, req)
 			return
 		}
 		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
 		return
 	}
-	
+
+	if len(parts) == 2 && parts[1] == "restore" {
+		// /employees/{id}/restore
+		if req.Method == http.MethodPost {
+			r.empHandler.Restore(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "history" {
+		// /employees/{id}/history
+		if req.Method == http.MethodGet {
+			r.empHandler.GetHistory(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "managers" {
+		// /employees/{id}/managers
+		if req.Method == http.MethodGet {
+			r.managementHandler.GetManagers(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "reports" {
+		// /employees/{id}/reports
+		if req.Method == http.MethodGet {
+			r.managementHandler.GetReports(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
 	http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
 }
+
+// orgRouter обрабатывает все запросы к /org/
+func (r *Router) orgRouter(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/org")
+	path = strings.Trim(path, "/")
+
+	if path == "tree" {
+		// /org/tree
+		if req.Method == http.MethodGet {
+			r.deptHandler.GetOrgTree(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if path == "diff" {
+		// /org/diff
+		if req.Method == http.MethodGet {
+			r.diffHandler.Diff(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if path == "span-of-control" {
+		// /org/span-of-control
+		if req.Method == http.MethodGet {
+			r.managementHandler.SpanOfControl(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+}
+
+// trashRouter обрабатывает все запросы к /trash/
+func (r *Router) trashRouter(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/trash")
+	path = strings.Trim(path, "/")
+
+	var handle http.HandlerFunc
+	switch path {
+	case "departments":
+		// /trash/departments
+		handle = r.trashHandler.ListDepartments
+	case "employees":
+		// /trash/employees
+		handle = r.trashHandler.ListEmployees
+	default:
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+
+	if req.Method != http.MethodGet {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	handle(w, req)
+}
+
+// auditRouter обрабатывает запросы к /audit
+func (r *Router) auditRouter(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	r.auditHandler.List(w, req)
+}
+
+// snapshotsRouter обрабатывает все запросы к /snapshots
+func (r *Router) snapshotsRouter(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/snapshots")
+	path = strings.Trim(path, "/")
+
+	if path == "" {
+		switch req.Method {
+		case http.MethodGet:
+			// GET /snapshots - список снимков
+			r.snapshotHandler.List(w, req)
+		case http.MethodPost:
+			// POST /snapshots - создание снимка
+			r.snapshotHandler.Create(w, req)
+		default:
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 || (len(parts) == 2 && parts[1] != "tree") {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+
+	if req.Method != http.MethodGet {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(parts) == 2 {
+		// /snapshots/{id}/tree
+		r.snapshotHandler.GetTree(w, req)
+		return
+	}
+	// /snapshots/{id}
+	r.snapshotHandler.GetByID(w, req)
+}
+
+// draftsRouter обрабатывает все запросы к /drafts
+func (r *Router) draftsRouter(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/drafts")
+	path = strings.Trim(path, "/")
+
+	if path == "" {
+		// POST /drafts - создание черновика
+		if req.Method == http.MethodPost {
+			r.draftHandler.Create(w, req)
+			return
+		}
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(path, "/")
+
+	var handle http.HandlerFunc
+	method := http.MethodPost
+	switch {
+	case len(parts) == 1:
+		// GET /drafts/{id}
+		handle, method = r.draftHandler.GetByID, http.MethodGet
+	case len(parts) == 2 && parts[1] == "operations":
+		// POST /drafts/{id}/operations
+		handle = r.draftHandler.AddOperation
+	case len(parts) == 3 && parts[1] == "operations":
+		// DELETE /drafts/{id}/operations/{operationID}
+		handle, method = r.draftHandler.RemoveOperation, http.MethodDelete
+	case len(parts) == 2 && parts[1] == "preview":
+		// GET /drafts/{id}/preview
+		handle, method = r.draftHandler.Preview, http.MethodGet
+	case len(parts) == 2 && parts[1] == "apply":
+		// POST /drafts/{id}/apply
+		handle = r.draftHandler.Apply
+	case len(parts) == 2 && parts[1] == "discard":
+		// POST /drafts/{id}/discard
+		handle = r.draftHandler.Discard
+	default:
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+
+	if req.Method != method {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	handle(w, req)
+}
+
+// delegationsRouter обрабатывает все запросы к /delegations
+func (r *Router) delegationsRouter(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/delegations")
+	path = strings.Trim(path, "/")
+
+	if path == "" {
+		switch req.Method {
+		case http.MethodGet:
+			// GET /delegations - список делегирований
+			r.delegationHandler.List(w, req)
+		case http.MethodPost:
+			// POST /delegations - создание делегирования
+			r.delegationHandler.Create(w, req)
+		default:
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	parts := strings.Split(path, "/")
+
+	var handle http.HandlerFunc
+	method := http.MethodGet
+	switch {
+	case len(parts) == 1:
+		// GET /delegations/{id}
+		handle = r.delegationHandler.GetByID
+	case len(parts) == 2 && parts[1] == "revoke":
+		// POST /delegations/{id}/revoke
+		handle, method = r.delegationHandler.Revoke, http.MethodPost
+	default:
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+
+	if req.Method != method {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	handle(w, req)
+}
+
+// positionsRouter обрабатывает все запросы к /positions
+func (r *Router) positionsRouter(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/positions")
+	path = strings.Trim(path, "/")
+
+	if path == "" {
+		switch req.Method {
+		case http.MethodGet:
+			// GET /positions - справочник должностей
+			r.positionHandler.List(w, req)
+		case http.MethodPost:
+			// POST /positions - создание должности
+			r.positionHandler.Create(w, req)
+		default:
+			http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	if strings.Contains(path, "/") {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		// GET /positions/{id}
+		r.positionHandler.GetByID(w, req)
+	case http.MethodPatch:
+		// PATCH /positions/{id}
+		r.positionHandler.Update(w, req)
+	case http.MethodDelete:
+		// DELETE /positions/{id}
+		r.positionHandler.Delete(w, req)
+	default:
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+	}
+}
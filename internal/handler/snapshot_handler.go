@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+type SnapshotHandler struct {
+	baseHandler
+	snapshotService service.SnapshotService
+}
+
+func NewSnapshotHandler(snapshotService service.SnapshotService, logger *slog.Logger) *SnapshotHandler {
+	return &SnapshotHandler{
+		baseHandler:     newBaseHandler(logger),
+		snapshotService: snapshotService,
+	}
+}
+
+func (h *SnapshotHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateSnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	snap, err := h.snapshotService.Create(r.Context(), &req)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, h.toSnapshotResponse(snap))
+}
+
+func (h *SnapshotHandler) List(w http.ResponseWriter, r *http.Request) {
+	query, err := h.parseListQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	snapshots, nextCursor, err := h.snapshotService.List(r.Context(), &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.SnapshotListResponse{
+		Items: make([]dto.SnapshotResponse, len(snapshots)),
+	}
+	for i, snap := range snapshots {
+		resp.Items[i] = h.toSnapshotResponse(&snap)
+	}
+	if nextCursor != "" {
+		resp.NextCursor = &nextCursor
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *SnapshotHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid snapshot id", err.Error())
+		return
+	}
+
+	snap, err := h.snapshotService.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toSnapshotResponse(snap))
+}
+
+func (h *SnapshotHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+	id, err := h.extractID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid snapshot id", err.Error())
+		return
+	}
+
+	query, err := h.parseTreeQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	forest, err := h.snapshotService.GetTree(r.Context(), id, &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := make([]dto.DepartmentResponse, len(forest))
+	for i, dept := range forest {
+		resp[i] = h.toDepartmentResponseWithChildren(&dept, query.IncludeEmployees)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *SnapshotHandler) extractID(r *http.Request) (int64, error) {
+	return extractPathID(r, "/snapshots/")
+}
+
+func (h *SnapshotHandler) parseListQuery(r *http.Request) (dto.ListSnapshotsQuery, error) {
+	values := r.URL.Query()
+	query := dto.ListSnapshotsQuery{
+		Limit:  20,
+		Cursor: values.Get("cursor"),
+		Name:   values.Get("name"),
+	}
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit: %w", err)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+func (h *SnapshotHandler) parseTreeQuery(r *http.Request) (dto.SnapshotTreeQuery, error) {
+	values := r.URL.Query()
+	query := dto.SnapshotTreeQuery{
+		Depth: 5,
+	}
+
+	if depthStr := values.Get("depth"); depthStr != "" {
+		depth, err := strconv.Atoi(depthStr)
+		if err != nil {
+			return query, fmt.Errorf("invalid depth: %w", err)
+		}
+		query.Depth = depth
+	}
+
+	query.IncludeEmployees = values.Get("include_employees") == "true"
+
+	return query, nil
+}
+
+func (h *SnapshotHandler) toSnapshotResponse(snap *domain.Snapshot) dto.SnapshotResponse {
+	return dto.SnapshotResponse{
+		ID:        snap.ID,
+		Name:      snap.Name,
+		Version:   snap.Version,
+		CreatedBy: snap.CreatedBy,
+		CreatedAt: snap.CreatedAt,
+	}
+}
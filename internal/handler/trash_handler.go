@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+type TrashHandler struct {
+	baseHandler
+	trashService service.TrashService
+}
+
+func NewTrashHandler(trashService service.TrashService, logger *slog.Logger) *TrashHandler {
+	return &TrashHandler{
+		baseHandler:  newBaseHandler(logger),
+		trashService: trashService,
+	}
+}
+
+func (h *TrashHandler) ListDepartments(w http.ResponseWriter, r *http.Request) {
+	query, err := h.parseListQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	departments, nextCursor, err := h.trashService.ListDepartments(r.Context(), &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.DepartmentListResponse{
+		Items: make([]dto.DepartmentResponse, len(departments)),
+	}
+	for i, dept := range departments {
+		resp.Items[i] = h.toDepartmentResponse(&dept)
+	}
+	if nextCursor != "" {
+		resp.NextCursor = &nextCursor
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *TrashHandler) ListEmployees(w http.ResponseWriter, r *http.Request) {
+	query, err := h.parseListQuery(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		h.respondError(w, http.StatusBadRequest, "validation error", err.Error())
+		return
+	}
+
+	employees, nextCursor, err := h.trashService.ListEmployees(r.Context(), &query)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := dto.EmployeeListResponse{
+		Items: make([]dto.EmployeeResponse, len(employees)),
+	}
+	for i, emp := range employees {
+		resp.Items[i] = h.toEmployeeResponse(&emp)
+	}
+	if nextCursor != "" {
+		resp.NextCursor = &nextCursor
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *TrashHandler) parseListQuery(r *http.Request) (dto.ListTrashQuery, error) {
+	values := r.URL.Query()
+	query := dto.ListTrashQuery{
+		Limit:  20,
+		Cursor: values.Get("cursor"),
+	}
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit: %w", err)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
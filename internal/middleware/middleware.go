@@ -1,9 +1,25 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/org-structure-api/internal/domain"
+)
+
+const (
+	// RequestIDHeader - заголовок с ID запроса; принимается от клиента и возвращается в ответе
+	RequestIDHeader = "X-Request-ID"
+	// ActorHeader - заголовок с идентификатором пользователя, который выставляет
+	// аутентифицирующий прокси перед API
+	ActorHeader = "X-Auth-User"
+
+	// maxRequestIDLength и maxActorLength соответствуют схеме audit_log
+	maxRequestIDLength = 100
+	maxActorLength     = 200
 )
 
 // responseWriter обёртка для захвата статус-кода
@@ -37,6 +53,7 @@ func Logger(logger *slog.Logger) func(http.Handler) http.Handler {
 				slog.Int("status", wrapped.statusCode),
 				slog.Duration("duration", time.Since(start)),
 				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("request_id", domain.RequestIDFromContext(r.Context())),
 			)
 		})
 	}
@@ -67,3 +84,35 @@ func ContentType(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// RequestID middleware присваивает запросу ID: берёт его из заголовка X-Request-ID
+// или генерирует новый, кладёт в контекст и возвращает в ответе
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(domain.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// Actor middleware кладёт в контекст пользователя из заголовка X-Auth-User
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := r.Header.Get(ActorHeader)
+		if actor == "" || len(actor) > maxActorLength {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(domain.WithActor(r.Context(), actor)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// AuditRepository определяет интерфейс для работы с журналом аудита.
+// Журнал только дополняется: записи не изменяются и не удаляются
+type AuditRepository interface {
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+	List(ctx context.Context, filter AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// AuditFilter - параметры выборки журнала аудита; записи отсортированы
+// от новых к старым. Пустые поля не ограничивают выборку
+type AuditFilter struct {
+	Entity   string
+	EntityID *int64
+	From     *time.Time
+	To       *time.Time
+	After    *Cursor
+	Limit    int
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository создаёт новый экземпляр репозитория
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *auditRepository) List(ctx context.Context, filter AuditFilter) ([]domain.AuditEntry, error) {
+	query := r.db.WithContext(ctx).Model(&domain.AuditEntry{})
+
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("occurred_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("occurred_at < ?", *filter.To)
+	}
+	// ID растёт вместе с временем записи, поэтому курсора по ID достаточно
+	if filter.After != nil {
+		query = query.Where("id < ?", filter.After.ID)
+	}
+
+	var entries []domain.AuditEntry
+	err := query.Order("id DESC").Limit(filter.Limit).Find(&entries).Error
+	return entries, err
+}
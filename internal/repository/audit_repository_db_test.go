@@ -0,0 +1,64 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+)
+
+func TestAuditLog_DB(t *testing.T) {
+	db := openTestDB(t)
+	repo := repository.NewAuditRepository(db)
+	ctx := context.Background()
+
+	for _, entry := range []domain.AuditEntry{
+		{Actor: "alice", Entity: domain.AuditEntityDepartment, EntityID: 1, Operation: domain.AuditOperationCreate, After: json.RawMessage(`{"id":1}`), RequestID: "r1"},
+		{Actor: "alice", Entity: domain.AuditEntityEmployee, EntityID: 1, Operation: domain.AuditOperationCreate, After: json.RawMessage(`{"id":1}`), RequestID: "r2"},
+		{Actor: "bob", Entity: domain.AuditEntityDepartment, EntityID: 1, Operation: domain.AuditOperationDelete, Before: json.RawMessage(`{"id":1}`), RequestID: "r3"},
+	} {
+		if err := repo.Create(ctx, &entry); err != nil {
+			t.Fatalf("create audit entry: %v", err)
+		}
+	}
+
+	entityID := int64(1)
+	entries, err := repo.List(ctx, repository.AuditFilter{Entity: domain.AuditEntityDepartment, EntityID: &entityID, Limit: 10})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Operation != domain.AuditOperationDelete || entries[1].Operation != domain.AuditOperationCreate {
+		t.Fatalf("expected delete and create newest first, got %+v", entries)
+	}
+	if entries[0].After != nil || string(entries[0].Before) != `{"id": 1}` {
+		t.Errorf("unexpected states: before=%s after=%s", entries[0].Before, entries[0].After)
+	}
+
+	entries, err = repo.List(ctx, repository.AuditFilter{After: &repository.Cursor{ID: entries[0].ID}, Limit: 10})
+	if err != nil {
+		t.Fatalf("list after cursor: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected 2 older entries, got %d", len(entries))
+	}
+
+	future := time.Now().Add(time.Hour)
+	entries, err = repo.List(ctx, repository.AuditFilter{From: &future, Limit: 10})
+	if err != nil {
+		t.Fatalf("list from: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries after %v, got %d", future, len(entries))
+	}
+
+	// Журнал только дополняется
+	if err := db.Exec("UPDATE audit_log SET actor = 'mallory'").Error; err == nil {
+		t.Error("expected update of audit_log to be rejected")
+	}
+	if err := db.Exec("DELETE FROM audit_log").Error; err == nil {
+		t.Error("expected delete from audit_log to be rejected")
+	}
+}
@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/repository"
+	"github.com/org-structure-api/internal/service"
+)
+
+// TestConcurrentMoves_Stress параллельно перемещает и переименовывает подразделения
+// и проверяет, что в итоге в дереве нет циклов и повторяющихся имён у одного родителя
+func TestConcurrentMoves_Stress(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	deptRepo := repository.NewDepartmentRepository(db)
+	empRepo := repository.NewEmployeeRepository(db)
+	svc := service.NewDepartmentService(deptRepo, empRepo, repository.NewTemporalRepository(db), repository.NewTxManager(db), service.EmployeePolicy{})
+
+	const departmentsCount = 30
+	ids := make([]int64, 0, departmentsCount)
+	for i := range departmentsCount {
+		req := &dto.CreateDepartmentRequest{Name: "Dept " + strconv.Itoa(i)}
+		if i > 0 {
+			parentID := ids[rand.IntN(len(ids))]
+			req.ParentID = &parentID
+		}
+		dept, err := svc.Create(ctx, req)
+		if err != nil {
+			t.Fatalf("failed to create department: %v", err)
+		}
+		ids = append(ids, dept.ID)
+	}
+
+	names := []string{"Alpha", "Beta", "Gamma"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for range 16 {
+		wg.Go(func() {
+			for range 50 {
+				id := ids[rand.IntN(len(ids))]
+				req := &dto.UpdateDepartmentRequest{}
+				if rand.IntN(3) == 0 {
+					req.Name = &names[rand.IntN(len(names))]
+				} else {
+					parentID := ids[rand.IntN(len(ids))]
+					req.ParentID = &parentID
+				}
+
+				_, err := svc.Update(ctx, id, req)
+				switch {
+				case err == nil,
+					errors.Is(err, domain.ErrCyclicReference),
+					errors.Is(err, domain.ErrSelfReference),
+					errors.Is(err, domain.ErrDuplicateDepartmentName):
+				default:
+					errs <- err
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var departments []domain.Department
+	if err := db.Find(&departments).Error; err != nil {
+		t.Fatalf("failed to load departments: %v", err)
+	}
+
+	parents := make(map[int64]*int64, len(departments))
+	for _, dept := range departments {
+		parents[dept.ID] = dept.ParentID
+	}
+
+	type key struct {
+		parent int64
+		name   string
+	}
+	seen := make(map[key]bool)
+	for _, dept := range departments {
+		var parent int64
+		if dept.ParentID != nil {
+			parent = *dept.ParentID
+		}
+		k := key{parent, dept.Name}
+		if seen[k] {
+			t.Errorf("duplicate name %q under parent %d", dept.Name, parent)
+		}
+		seen[k] = true
+
+		steps := 0
+		for p := dept.ParentID; p != nil; p = parents[*p] {
+			if steps++; steps > len(departments) {
+				t.Fatalf("cycle detected starting from department %d", dept.ID)
+			}
+		}
+	}
+}
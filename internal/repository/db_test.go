@@ -0,0 +1,64 @@
+package repository_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// openTestDB подключается к PostgreSQL из TEST_DATABASE_DSN, применяет миграции
+// и очищает таблицы. Если переменная не задана, тест пропускается
+func openTestDB(tb testing.TB) *gorm.DB {
+	tb.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		tb.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		tb.Fatalf("failed to connect to database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		tb.Fatalf("failed to get sql.DB: %v", err)
+	}
+	tb.Cleanup(func() { sqlDB.Close() })
+
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("postgres"); err != nil {
+		tb.Fatalf("failed to set dialect: %v", err)
+	}
+	if err := goose.Up(sqlDB, "../../cmd/api/migrations"); err != nil {
+		tb.Fatalf("failed to run migrations: %v", err)
+	}
+
+	if err := db.Exec("TRUNCATE departments, employees, positions, audit_log, snapshots, reorg_drafts RESTART IDENTITY CASCADE").Error; err != nil {
+		tb.Fatalf("failed to truncate tables: %v", err)
+	}
+
+	return db
+}
+
+// countQueries подсчитывает запросы, выполненные через db
+func countQueries(tb testing.TB, db *gorm.DB) *int {
+	tb.Helper()
+
+	var count int
+	inc := func(*gorm.DB) { count++ }
+	if err := db.Callback().Query().After("gorm:query").Register("test:count_query", inc); err != nil {
+		tb.Fatalf("failed to register callback: %v", err)
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("test:count_row", inc); err != nil {
+		tb.Fatalf("failed to register callback: %v", err)
+	}
+	return &count
+}
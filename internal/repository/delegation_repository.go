@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DelegationRepository определяет интерфейс для работы с делегированием полномочий руководителей
+type DelegationRepository interface {
+	Create(ctx context.Context, delegation *domain.Delegation) error
+	GetByID(ctx context.Context, id int64) (*domain.Delegation, error)
+	// GetByIDForUpdate загружает делегирование и блокирует его до конца транзакции
+	GetByIDForUpdate(ctx context.Context, id int64) (*domain.Delegation, error)
+	List(ctx context.Context, filter DelegationFilter) ([]domain.Delegation, error)
+	// GetActive возвращает делегирования подразделений departmentIDs, действующие в дату date,
+	// кроме делегирований удалённым сотрудникам
+	GetActive(ctx context.Context, departmentIDs []int64, date time.Time) ([]domain.Delegation, error)
+	// HasOverlap сообщает, есть ли у подразделения неотозванное делегирование от delegatorID,
+	// период которого пересекается с периодом from-to. Делегирования прежних руководителей
+	// не действуют и не учитываются
+	HasOverlap(ctx context.Context, departmentID, delegatorID int64, from, to time.Time) (bool, error)
+	// Revoke отзывает делегирование и заполняет RevokedBy и RevokedAt
+	Revoke(ctx context.Context, delegation *domain.Delegation, revokedBy string) error
+}
+
+// DelegationFilter - параметры выборки делегирований; делегирования отсортированы от новых к старым
+type DelegationFilter struct {
+	DepartmentID *int64
+	// EmployeeID - делегирующий или заместитель
+	EmployeeID *int64
+	// ActiveOn - только делегирования, действующие в эту дату
+	ActiveOn *time.Time
+	After    *Cursor
+	Limit    int
+}
+
+type delegationRepository struct {
+	db *gorm.DB
+}
+
+// NewDelegationRepository создаёт новый экземпляр репозитория
+func NewDelegationRepository(db *gorm.DB) DelegationRepository {
+	return &delegationRepository{db: db}
+}
+
+func (r *delegationRepository) Create(ctx context.Context, delegation *domain.Delegation) error {
+	return r.db.WithContext(ctx).Create(delegation).Error
+}
+
+func (r *delegationRepository) GetByID(ctx context.Context, id int64) (*domain.Delegation, error) {
+	return r.get(r.db.WithContext(ctx), id)
+}
+
+func (r *delegationRepository) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Delegation, error) {
+	return r.get(r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func (r *delegationRepository) get(query *gorm.DB, id int64) (*domain.Delegation, error) {
+	var delegation domain.Delegation
+	err := query.First(&delegation, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, domain.ErrDelegationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+func (r *delegationRepository) List(ctx context.Context, filter DelegationFilter) ([]domain.Delegation, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Delegation{})
+
+	if filter.DepartmentID != nil {
+		query = query.Where("department_id = ?", *filter.DepartmentID)
+	}
+	if filter.EmployeeID != nil {
+		query = query.Where("delegator_id = ? OR delegate_id = ?", *filter.EmployeeID, *filter.EmployeeID)
+	}
+	if filter.ActiveOn != nil {
+		query = query.Where("revoked_at IS NULL AND starts_on <= ? AND ends_on >= ?", *filter.ActiveOn, *filter.ActiveOn)
+	}
+	if filter.After != nil {
+		query = query.Where("id < ?", filter.After.ID)
+	}
+
+	var delegations []domain.Delegation
+	err := query.Order("id DESC").Limit(filter.Limit).Find(&delegations).Error
+	return delegations, err
+}
+
+func (r *delegationRepository) GetActive(ctx context.Context, departmentIDs []int64, date time.Time) ([]domain.Delegation, error) {
+	var delegations []domain.Delegation
+	if len(departmentIDs) == 0 {
+		return delegations, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Joins("JOIN employees ON employees.id = delegations.delegate_id AND employees.deleted_at IS NULL").
+		Where("delegations.department_id IN ?", departmentIDs).
+		Where("delegations.revoked_at IS NULL AND delegations.starts_on <= ? AND delegations.ends_on >= ?", date, date).
+		Order("delegations.id").
+		Find(&delegations).Error
+	return delegations, err
+}
+
+func (r *delegationRepository) HasOverlap(ctx context.Context, departmentID, delegatorID int64, from, to time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Delegation{}).
+		Where("department_id = ? AND delegator_id = ? AND revoked_at IS NULL", departmentID, delegatorID).
+		Where("starts_on <= ? AND ends_on >= ?", to, from).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *delegationRepository) Revoke(ctx context.Context, delegation *domain.Delegation, revokedBy string) error {
+	revokedAt := time.Now()
+	err := r.db.WithContext(ctx).Model(&domain.Delegation{}).
+		Where("id = ?", delegation.ID).
+		Updates(map[string]any{"revoked_by": revokedBy, "revoked_at": revokedAt}).Error
+	if err != nil {
+		return err
+	}
+
+	delegation.RevokedBy = &revokedBy
+	delegation.RevokedAt = &revokedAt
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+)
+
+func TestDelegations_DB(t *testing.T) {
+	db := openTestDB(t)
+	depts := repository.NewDepartmentRepository(db)
+	emps := repository.NewEmployeeRepository(db)
+	delegations := repository.NewDelegationRepository(db)
+	ctx := context.Background()
+
+	sales := &domain.Department{Name: "Sales"}
+	if err := depts.Create(ctx, sales); err != nil {
+		t.Fatalf("create department: %v", err)
+	}
+	var ids []int64
+	for _, name := range []string{"Anna", "Bob"} {
+		emp := &domain.Employee{DepartmentID: sales.ID, FullName: name, Position: "Dev"}
+		if err := emps.Create(ctx, emp); err != nil {
+			t.Fatalf("create employee: %v", err)
+		}
+		ids = append(ids, emp.ID)
+	}
+	head, deputy := ids[0], ids[1]
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	delegation := &domain.Delegation{
+		DepartmentID: sales.ID, DelegatorID: head, DelegateID: deputy,
+		StartsOn: today, EndsOn: today.AddDate(0, 0, 7), CreatedBy: "alice",
+	}
+	if err := delegations.Create(ctx, delegation); err != nil {
+		t.Fatalf("create delegation: %v", err)
+	}
+
+	for _, tt := range []struct {
+		from, to time.Time
+		want     bool
+	}{
+		{today.AddDate(0, 0, 7), today.AddDate(0, 0, 9), true},
+		{today.AddDate(0, 0, 8), today.AddDate(0, 0, 9), false},
+		{today.AddDate(0, 0, -3), today.AddDate(0, 0, -1), false},
+	} {
+		overlap, err := delegations.HasOverlap(ctx, sales.ID, head, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("has overlap: %v", err)
+		}
+		if overlap != tt.want {
+			t.Errorf("overlap with %v-%v: expected %v, got %v", tt.from, tt.to, tt.want, overlap)
+		}
+	}
+	if overlap, err := delegations.HasOverlap(ctx, sales.ID, deputy, today, today); err != nil || overlap {
+		t.Errorf("delegation of another head must not overlap, got %v, %v", overlap, err)
+	}
+
+	active, err := delegations.GetActive(ctx, []int64{sales.ID}, today)
+	if err != nil {
+		t.Fatalf("get active: %v", err)
+	}
+	if len(active) != 1 || active[0].ID != delegation.ID {
+		t.Fatalf("expected the delegation to be active today, got %+v", active)
+	}
+
+	list, err := delegations.List(ctx, repository.DelegationFilter{EmployeeID: &deputy, ActiveOn: &today, Limit: 10})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 1 {
+		t.Errorf("expected the delegation in the deputy's list, got %+v", list)
+	}
+
+	// Делегирование удалённому заместителю не действует
+	if err := emps.Delete(ctx, deputy); err != nil {
+		t.Fatalf("delete deputy: %v", err)
+	}
+	if active, err = delegations.GetActive(ctx, []int64{sales.ID}, today); err != nil || len(active) != 0 {
+		t.Errorf("expected no active delegations after the deputy is deleted, got %+v, %v", active, err)
+	}
+
+	if err := delegations.Revoke(ctx, delegation, "bob"); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	revoked, err := delegations.GetByID(ctx, delegation.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if revoked.RevokedAt == nil || revoked.RevokedBy == nil || *revoked.RevokedBy != "bob" {
+		t.Errorf("expected revocation to be stored, got %+v", revoked)
+	}
+	if overlap, err := delegations.HasOverlap(ctx, sales.ID, head, today, today); err != nil || overlap {
+		t.Errorf("revoked delegation must not overlap, got %v, %v", overlap, err)
+	}
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"github.com/org-structure-api/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // DepartmentRepository определяет интерфейс для работы с подразделениями
@@ -18,8 +21,31 @@ type DepartmentRepository interface {
 	ExistsByNameAndParent(ctx context.Context, name string, parentID *int64, excludeID *int64) (bool, error)
 	IsDescendant(ctx context.Context, ancestorID, descendantID int64) (bool, error)
 	GetAllDescendantIDs(ctx context.Context, id int64) ([]int64, error)
+	GetByIDForUpdate(ctx context.Context, id int64) (*domain.Department, error)
+	GetChildren(ctx context.Context, parentID int64) ([]domain.Department, error)
+	DetachChildren(ctx context.Context, parentID int64) error
+	LockForMove(ctx context.Context, id, newParentID int64) error
+	LockName(ctx context.Context, name string, parentID *int64) error
+	GetAncestorIDs(ctx context.Context, id int64) ([]int64, error)
+	GetAncestors(ctx context.Context, id int64) ([]domain.Department, error)
+	GetForest(ctx context.Context, depth int, includeEmployees bool) ([]domain.Department, error)
+	ListRoots(ctx context.Context, filter DepartmentFilter) ([]domain.Department, error)
+	GetWithDeletedForUpdate(ctx context.Context, id int64) (*domain.Department, error)
+	Restore(ctx context.Context, id int64, deletedAt time.Time) ([]domain.Department, []domain.Employee, error)
+	ListDeleted(ctx context.Context, filter TrashFilter) ([]domain.Department, error)
+	Purge(ctx context.Context, deletedBefore time.Time) ([]domain.Department, error)
 }
 
+// DepartmentFilter - параметры выборки списка подразделений
+type DepartmentFilter struct {
+	Name  string
+	After *Cursor
+	Limit int
+}
+
+// maxHierarchyDepth ограничивает обход предков на случай повреждённых данных с циклом
+const maxHierarchyDepth = 10_000
+
 type departmentRepository struct {
 	db *gorm.DB
 }
@@ -30,7 +56,19 @@ func NewDepartmentRepository(db *gorm.DB) DepartmentRepository {
 }
 
 func (r *departmentRepository) Create(ctx context.Context, dept *domain.Department) error {
-	return r.db.WithContext(ctx).Create(dept).Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(dept).Error; err != nil {
+			return err
+		}
+		if err := insertClosure(tx, dept.ID, dept.ParentID); err != nil {
+			return err
+		}
+		return syncDepartmentVersions(tx, versionDate(), "id = ?", dept.ID)
+	})
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateDepartmentName
+	}
+	return err
 }
 
 func (r *departmentRepository) GetByID(ctx context.Context, id int64) (*domain.Department, error) {
@@ -45,80 +83,375 @@ func (r *departmentRepository) GetByID(ctx context.Context, id int64) (*domain.D
 	return &dept, nil
 }
 
-func (r *departmentRepository) GetByIDWithChildren(ctx context.Context, id int64, depth int, includeEmployees bool) (*domain.Department, error) {
+// GetByIDForUpdate загружает подразделение и блокирует строку до конца транзакции
+func (r *departmentRepository) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Department, error) {
 	var dept domain.Department
-
-	query := r.db.WithContext(ctx)
-
-	if includeEmployees {
-		query = query.Preload("Employees", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at ASC")
-		})
-	}
-
-	err := query.First(&dept, id).Error
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&dept, id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrDepartmentNotFound
 		}
 		return nil, err
 	}
+	return &dept, nil
+}
+
+// GetByIDWithChildren загружает поддерево глубиной depth фиксированным числом запросов:
+// один запрос к таблице замыкания для подразделений и, при includeEmployees, один
+// запрос для сотрудников. Дерево собирается в памяти
+func (r *departmentRepository) GetByIDWithChildren(ctx context.Context, id int64, depth int, includeEmployees bool) (*domain.Department, error) {
+	departments, employees, err := r.loadSubtrees(ctx, "c.ancestor_id = ?", []any{id}, depth, includeEmployees)
+	if err != nil {
+		return nil, err
+	}
+	if len(departments) == 0 {
+		return nil, domain.ErrDepartmentNotFound
+	}
 
-	// Рекурсивно загружаем дочерние подразделения
-	if depth > 0 {
-		if err := r.loadChildren(ctx, &dept, depth, includeEmployees); err != nil {
-			return nil, err
+	return buildDepartmentTree(id, departments, employees), nil
+}
+
+// GetForest загружает все корневые подразделения с поддеревьями глубиной depth
+func (r *departmentRepository) GetForest(ctx context.Context, depth int, includeEmployees bool) ([]domain.Department, error) {
+	departments, employees, err := r.loadSubtrees(ctx, "c.ancestor_id IN (SELECT id FROM departments WHERE parent_id IS NULL)", nil, depth, includeEmployees)
+	if err != nil {
+		return nil, err
+	}
+
+	var rootIDs []int64
+	for _, dept := range departments {
+		if dept.ParentID == nil {
+			rootIDs = append(rootIDs, dept.ID)
 		}
 	}
 
-	return &dept, nil
+	return buildDepartmentForest(rootIDs, departments, employees), nil
+}
+
+// loadSubtrees загружает плоским списком подразделения-корни, выбранные условием
+// rootCond на строки замыкания c, и их потомков до глубины depth, а при
+// includeEmployees - и сотрудников этих подразделений
+func (r *departmentRepository) loadSubtrees(ctx context.Context, rootCond string, args []any, depth int, includeEmployees bool) ([]domain.Department, []domain.Employee, error) {
+	query := `
+		SELECT d.id, d.name, d.parent_id, d.created_at, d.head_employee_id
+		FROM department_closure c
+		INNER JOIN departments d ON d.id = c.descendant_id
+		WHERE ` + rootCond + ` AND c.depth <= ? AND d.deleted_at IS NULL
+		ORDER BY d.id
+	`
+
+	var departments []domain.Department
+	err := r.db.WithContext(ctx).Raw(query, append(args, max(depth, 0))...).Scan(&departments).Error
+	if err != nil || len(departments) == 0 || !includeEmployees {
+		return departments, nil, err
+	}
+
+	ids := make([]int64, len(departments))
+	for i, dept := range departments {
+		ids[i] = dept.ID
+	}
+
+	var employees []domain.Employee
+	err = r.db.WithContext(ctx).
+		Where("department_id IN ?", ids).
+		Order("created_at ASC, id ASC").
+		Find(&employees).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return departments, employees, nil
 }
 
-func (r *departmentRepository) loadChildren(ctx context.Context, dept *domain.Department, depth int, includeEmployees bool) error {
-	if depth <= 0 {
+// buildDepartmentTree собирает дерево с корнем rootID из плоских списков подразделений
+// и сотрудников. Порядок детей и сотрудников сохраняется таким, как во входных срезах
+func buildDepartmentTree(rootID int64, departments []domain.Department, employees []domain.Employee) *domain.Department {
+	forest := buildDepartmentForest([]int64{rootID}, departments, employees)
+	if len(forest) == 0 {
 		return nil
 	}
+	return &forest[0]
+}
+
+// buildDepartmentForest собирает деревья с корнями rootIDs (в заданном порядке)
+func buildDepartmentForest(rootIDs []int64, departments []domain.Department, employees []domain.Employee) []domain.Department {
+	indexByID := make(map[int64]int, len(departments))
+	childrenIdx := make(map[int64][]int, len(departments))
+	for i, dept := range departments {
+		indexByID[dept.ID] = i
+		if dept.ParentID != nil {
+			childrenIdx[*dept.ParentID] = append(childrenIdx[*dept.ParentID], i)
+		}
+	}
 
-	query := r.db.WithContext(ctx).Where("parent_id = ?", dept.ID)
+	employeesByDept := make(map[int64][]domain.Employee)
+	for _, emp := range employees {
+		employeesByDept[emp.DepartmentID] = append(employeesByDept[emp.DepartmentID], emp)
+	}
+
+	var build func(i int) domain.Department
+	build = func(i int) domain.Department {
+		dept := departments[i]
+		dept.Employees = employeesByDept[dept.ID]
 
-	if includeEmployees {
-		query = query.Preload("Employees", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at ASC")
-		})
+		if idx := childrenIdx[dept.ID]; len(idx) > 0 {
+			dept.Children = make([]domain.Department, len(idx))
+			for j, childIdx := range idx {
+				dept.Children[j] = build(childIdx)
+			}
+		}
+		return dept
 	}
 
-	var children []domain.Department
-	if err := query.Find(&children).Error; err != nil {
-		return err
+	forest := make([]domain.Department, 0, len(rootIDs))
+	for _, id := range rootIDs {
+		if i, ok := indexByID[id]; ok {
+			forest = append(forest, build(i))
+		}
 	}
+	return forest
+}
 
-	for i := range children {
-		if err := r.loadChildren(ctx, &children[i], depth-1, includeEmployees); err != nil {
+// ListRoots возвращает страницу корневых подразделений, отсортированных по (name, id)
+func (r *departmentRepository) ListRoots(ctx context.Context, filter DepartmentFilter) ([]domain.Department, error) {
+	query := r.db.WithContext(ctx).Where("parent_id IS NULL")
+
+	if filter.Name != "" {
+		query = query.Where("name ILIKE ?", "%"+escapeLike(filter.Name)+"%")
+	}
+	if filter.After != nil {
+		query = query.Where("(name, id) > (?, ?)", filter.After.Value, filter.After.ID)
+	}
+
+	var departments []domain.Department
+	err := query.Order("name ASC, id ASC").Limit(filter.Limit).Find(&departments).Error
+	return departments, err
+}
+
+// GetChildren возвращает прямые дочерние подразделения в порядке возрастания ID
+func (r *departmentRepository) GetChildren(ctx context.Context, parentID int64) ([]domain.Department, error) {
+	var children []domain.Department
+	err := r.db.WithContext(ctx).
+		Where("parent_id = ?", parentID).
+		Order("id ASC").
+		Find(&children).Error
+	return children, err
+}
+
+// DetachChildren делает прямых детей корневыми, чтобы удаление родителя
+// не затронуло их через ON DELETE CASCADE
+func (r *departmentRepository) DetachChildren(ctx context.Context, parentID int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var childIDs []int64
+		if err := tx.Model(&domain.Department{}).Where("parent_id = ?", parentID).Pluck("id", &childIDs).Error; err != nil {
+			return err
+		}
+		if err := detachChildrenClosure(tx, parentID); err != nil {
 			return err
 		}
-	}
 
-	dept.Children = children
-	return nil
+		err := tx.Model(&domain.Department{}).
+			Where("parent_id = ?", parentID).
+			Update("parent_id", nil).Error
+		if err != nil {
+			return err
+		}
+		return syncDepartmentVersions(tx, versionDate(), "id IN ?", childIDs)
+	})
 }
 
+// Update сохраняет подразделение; при смене родителя переносит поддерево
+// в таблице замыкания, при смене имени или родителя начинает новую версию
 func (r *departmentRepository) Update(ctx context.Context, dept *domain.Department) error {
-	return r.db.WithContext(ctx).Save(dept).Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current domain.Department
+		if err := tx.Select("name", "parent_id").First(&current, dept.ID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return domain.ErrDepartmentNotFound
+			}
+			return err
+		}
+
+		if err := tx.Save(dept).Error; err != nil {
+			return err
+		}
+
+		moved := !domain.SameParent(current.ParentID, dept.ParentID)
+		if moved {
+			if err := moveClosure(tx, dept.ID, dept.ParentID); err != nil {
+				return err
+			}
+		}
+		if !moved && current.Name == dept.Name {
+			return nil
+		}
+		return syncDepartmentVersions(tx, versionDate(), "id = ?", dept.ID)
+	})
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateDepartmentName
+	}
+	return err
 }
 
 func (r *departmentRepository) Delete(ctx context.Context, id int64) error {
-	result := r.db.WithContext(ctx).Delete(&domain.Department{}, id)
-	if result.Error != nil {
-		return result.Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&domain.Department{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrDepartmentNotFound
+		}
+		return syncDepartmentVersions(tx, versionDate(), "id = ?", id)
+	})
+}
+
+// DeleteCascade мягко удаляет подразделение вместе со всеми потомками и их
+// сотрудниками. Все записи получают одну метку deleted_at, по которой Restore
+// восстанавливает ровно то, что было удалено этой операцией
+func (r *departmentRepository) DeleteCascade(ctx context.Context, id int64) error {
+	deletedAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Department{}).
+			Where("id IN ("+subtreeIDsQuery+")", id).
+			Update("deleted_at", deletedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrDepartmentNotFound
+		}
+
+		err := tx.Model(&domain.Employee{}).
+			Where("department_id IN ("+subtreeIDsQuery+")", id).
+			Update("deleted_at", deletedAt).Error
+		if err != nil {
+			return err
+		}
+
+		date := versionDate()
+		if err := syncDepartmentVersions(tx, date, "deleted_at = ? AND id IN ("+subtreeIDsQuery+")", deletedAt, id); err != nil {
+			return err
+		}
+		return syncEmployeeAssignments(tx, date, "deleted_at = ? AND department_id IN ("+subtreeIDsQuery+")", deletedAt, id)
+	})
+}
+
+// subtreeIDsQuery выбирает ID подразделения и всех его потомков, включая удалённых
+const subtreeIDsQuery = "SELECT descendant_id FROM department_closure WHERE ancestor_id = ?"
+
+// GetWithDeletedForUpdate загружает подразделение независимо от того, удалено ли оно,
+// и блокирует строку до конца транзакции
+func (r *departmentRepository) GetWithDeletedForUpdate(ctx context.Context, id int64) (*domain.Department, error) {
+	var dept domain.Department
+	err := r.db.WithContext(ctx).Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&dept, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrDepartmentNotFound
+		}
+		return nil, err
 	}
-	if result.RowsAffected == 0 {
-		return domain.ErrDepartmentNotFound
+	return &dept, nil
+}
+
+// Restore восстанавливает подразделение и те записи его поддерева (подразделения и
+// сотрудников), которые были удалены вместе с ним, то есть имеют метку deletedAt.
+// Возвращает восстановленные записи в состоянии до восстановления
+func (r *departmentRepository) Restore(ctx context.Context, id int64, deletedAt time.Time) ([]domain.Department, []domain.Employee, error) {
+	var departments []domain.Department
+	var employees []domain.Employee
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("id IN ("+subtreeIDsQuery+") AND deleted_at = ?", id, deletedAt).
+			Order("id").
+			Find(&departments).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().
+			Where("department_id IN ("+subtreeIDsQuery+") AND deleted_at = ?", id, deletedAt).
+			Order("id").
+			Find(&employees).Error
+		if err != nil {
+			return err
+		}
+
+		deptIDs := make([]int64, len(departments))
+		for i := range departments {
+			deptIDs[i] = departments[i].ID
+		}
+		empIDs := make([]int64, len(employees))
+		for i := range employees {
+			empIDs[i] = employees[i].ID
+		}
+
+		err = tx.Unscoped().Model(&domain.Department{}).
+			Where("id IN ?", deptIDs).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&domain.Employee{}).
+			Where("id IN ?", empIDs).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		date := versionDate()
+		if err := syncDepartmentVersions(tx, date, "id IN ?", deptIDs); err != nil {
+			return err
+		}
+		return syncEmployeeAssignments(tx, date, "id IN ?", empIDs)
+	})
+	if isUniqueViolation(err) {
+		return nil, nil, domain.ErrDuplicateDepartmentName
 	}
-	return nil
+	if err != nil {
+		return nil, nil, err
+	}
+	return departments, employees, nil
 }
 
-func (r *departmentRepository) DeleteCascade(ctx context.Context, id int64) error {
-	return r.Delete(ctx, id)
+// ListDeleted возвращает страницу удалённых подразделений
+func (r *departmentRepository) ListDeleted(ctx context.Context, filter TrashFilter) ([]domain.Department, error) {
+	query, err := applyTrashFilter(r.db.WithContext(ctx), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var departments []domain.Department
+	err = query.Find(&departments).Error
+	return departments, err
+}
+
+// Purge окончательно удаляет подразделения, удалённые раньше deletedBefore, и
+// возвращает их. Их удалённые потомки и сотрудники, а также версии удаляются через
+// ON DELETE CASCADE. История на прошлые даты при этом усекается: назначения других
+// сотрудников в эти подразделения и версии подразделений, бывших их дочерними,
+// удаляются, чтобы чтение на дату не ссылалось на несуществующие подразделения
+func (r *departmentRepository) Purge(ctx context.Context, deletedBefore time.Time) ([]domain.Department, error) {
+	var departments []domain.Department
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Clauses(clause.Returning{}).
+			Where("deleted_at < ?", deletedBefore).
+			Delete(&departments).Error
+		if err != nil || len(departments) == 0 {
+			return err
+		}
+
+		ids := departmentIDs(departments)
+		if err := tx.Where("department_id IN ?", ids).Delete(&domain.EmployeeAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("parent_id IN ?", ids).Delete(&domain.DepartmentVersion{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return departments, nil
 }
 
 func (r *departmentRepository) ExistsByNameAndParent(ctx context.Context, name string, parentID *int64, excludeID *int64) (bool, error) {
@@ -139,48 +472,123 @@ func (r *departmentRepository) ExistsByNameAndParent(ctx context.Context, name s
 	return count > 0, err
 }
 
+// IsDescendant проверяет, является ли descendantID потомком ancestorID, одним
+// обращением по первичному ключу таблицы замыкания
 func (r *departmentRepository) IsDescendant(ctx context.Context, ancestorID, descendantID int64) (bool, error) {
-	// Рекурсивно проверяем, является ли descendantID потомком ancestorID
-	descendants, err := r.GetAllDescendantIDs(ctx, ancestorID)
-	if err != nil {
-		return false, err
-	}
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM department_closure
+			WHERE ancestor_id = ? AND descendant_id = ? AND depth > 0
+		)
+	`
+
+	var exists bool
+	err := r.db.WithContext(ctx).Raw(query, ancestorID, descendantID).Scan(&exists).Error
+	return exists, err
+}
+
+// LockForMove блокирует строку перемещаемого подразделения и строки всей цепочки
+// предков нового родителя (включая его самого). Перемещение меняет предков только
+// внутри своего поддерева, поэтому параллельные перемещения, способные вместе
+// образовать цикл, обязательно пересекаются по заблокированным строкам.
+// Цепочка перечитывается после блокировки, пока не перестанет меняться
+func (r *departmentRepository) LockForMove(ctx context.Context, id, newParentID int64) error {
+	locked := make(map[int64]bool)
+	for {
+		chain, err := r.GetAncestorIDs(ctx, newParentID)
+		if err != nil {
+			return err
+		}
 
-	for _, id := range descendants {
-		if id == descendantID {
-			return true, nil
+		var toLock []int64
+		for _, chainID := range append(chain, id) {
+			if !locked[chainID] {
+				toLock = append(toLock, chainID)
+			}
+		}
+		if len(toLock) == 0 {
+			return nil
+		}
+
+		// Блокируем в порядке возрастания ID, чтобы уменьшить вероятность взаимоблокировок
+		var lockedIDs []int64
+		err = r.db.WithContext(ctx).
+			Model(&domain.Department{}).
+			Where("id IN ?", toLock).
+			Order("id").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Pluck("id", &lockedIDs).Error
+		if err != nil {
+			return err
+		}
+
+		for _, chainID := range toLock {
+			locked[chainID] = true
 		}
 	}
-	return false, nil
 }
 
-func (r *departmentRepository) GetAllDescendantIDs(ctx context.Context, id int64) ([]int64, error) {
-	var result []int64
+// LockName берёт транзакционную advisory-блокировку на пару (родитель, имя), чтобы
+// проверка уникальности имени и запись выполнялись атомарно. Ограничение UNIQUE
+// в БД не защищает корневые подразделения, так как NULL-значения parent_id различны
+func (r *departmentRepository) LockName(ctx context.Context, name string, parentID *int64) error {
+	parent := "root"
+	if parentID != nil {
+		parent = strconv.FormatInt(*parentID, 10)
+	}
+	key := "department_name:" + parent + ":" + name
+	return r.db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", key).Error
+}
 
-	// Используем рекурсивный CTE для PostgreSQL
+// GetAncestorIDs возвращает ID подразделения и всех его предков, начиная с него самого
+func (r *departmentRepository) GetAncestorIDs(ctx context.Context, id int64) ([]int64, error) {
 	query := `
-		WITH RECURSIVE descendants AS (
-			SELECT id FROM departments WHERE parent_id = $1
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id, 0 AS level FROM departments WHERE id = $1
 			UNION ALL
-			SELECT d.id FROM departments d
-			INNER JOIN descendants ds ON d.parent_id = ds.id
+			SELECT d.id, d.parent_id, c.level + 1 FROM departments d
+			INNER JOIN chain c ON d.id = c.parent_id
+			WHERE c.level < $2
 		)
-		SELECT id FROM descendants
+		SELECT id FROM chain ORDER BY level
 	`
 
-	rows, err := r.db.WithContext(ctx).Raw(query, id).Rows()
-	if err != nil {
+	var ids []int64
+	err := r.db.WithContext(ctx).Raw(query, id, maxHierarchyDepth).Scan(&ids).Error
+	return ids, err
+}
+
+// GetAncestors возвращает цепочку подразделений от корня до id включительно
+func (r *departmentRepository) GetAncestors(ctx context.Context, id int64) ([]domain.Department, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id, name, parent_id, created_at, head_employee_id, 0 AS level FROM departments WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT d.id, d.name, d.parent_id, d.created_at, d.head_employee_id, c.level + 1 FROM departments d
+			INNER JOIN chain c ON d.id = c.parent_id
+			WHERE c.level < $2
+		)
+		SELECT id, name, parent_id, created_at, head_employee_id FROM chain ORDER BY level DESC
+	`
+
+	var chain []domain.Department
+	if err := r.db.WithContext(ctx).Raw(query, id, maxHierarchyDepth).Scan(&chain).Error; err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var descendantID int64
-		if err := rows.Scan(&descendantID); err != nil {
-			return nil, err
-		}
-		result = append(result, descendantID)
+	if len(chain) == 0 {
+		return nil, domain.ErrDepartmentNotFound
 	}
+	return chain, nil
+}
 
-	return result, rows.Err()
+// GetAllDescendantIDs возвращает ID всех неудалённых потомков подразделения (без него самого)
+func (r *departmentRepository) GetAllDescendantIDs(ctx context.Context, id int64) ([]int64, error) {
+	var result []int64
+	err := r.db.WithContext(ctx).
+		Table("department_closure c").
+		Joins("INNER JOIN departments d ON d.id = c.descendant_id").
+		Where("c.ancestor_id = ? AND c.depth > 0 AND d.deleted_at IS NULL", id).
+		Order("c.descendant_id").
+		Pluck("c.descendant_id", &result).Error
+	return result, err
 }
@@ -0,0 +1,382 @@
+package repository_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// seedTree создаёт дерево из n подразделений (не более fanout детей у каждого)
+// и employeesPerDept сотрудников в каждом. Возвращает ID корня
+func seedTree(tb testing.TB, db *gorm.DB, n, fanout, employeesPerDept int) int64 {
+	tb.Helper()
+
+	root := domain.Department{Name: "Root"}
+	if err := db.Create(&root).Error; err != nil {
+		tb.Fatalf("failed to create root: %v", err)
+	}
+
+	level := []domain.Department{root}
+	created := 1
+	for created < n && len(level) > 0 {
+		var next []domain.Department
+		for _, parent := range level {
+			for i := 0; i < fanout && created+len(next) < n; i++ {
+				parentID := parent.ID
+				next = append(next, domain.Department{Name: "Dept " + strconv.Itoa(i), ParentID: &parentID})
+			}
+		}
+		if err := db.CreateInBatches(next, 1000).Error; err != nil {
+			tb.Fatalf("failed to create departments: %v", err)
+		}
+		created += len(next)
+		level = next
+	}
+
+	if employeesPerDept > 0 {
+		err := db.Exec(`
+			INSERT INTO employees (department_id, full_name, position)
+			SELECT d.id, 'Employee ' || g, 'Developer' FROM departments d, generate_series(1, ?) g
+		`, employeesPerDept).Error
+		if err != nil {
+			tb.Fatalf("failed to create employees: %v", err)
+		}
+	}
+
+	// Подразделения вставлены в обход репозитория, поэтому замыкание строим отдельно
+	if _, err := repository.NewHierarchyRepository(db).Rebuild(context.Background()); err != nil {
+		tb.Fatalf("failed to rebuild closure: %v", err)
+	}
+
+	return root.ID
+}
+
+// loadSubtreeNPlusOne - прежняя реализация загрузки поддерева (запрос на каждое
+// подразделение), используется как точка отсчёта в бенчмарке
+func loadSubtreeNPlusOne(ctx context.Context, db *gorm.DB, dept *domain.Department, depth int) error {
+	if depth <= 0 {
+		return nil
+	}
+
+	var children []domain.Department
+	err := db.WithContext(ctx).
+		Where("parent_id = ?", dept.ID).
+		Preload("Employees", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Find(&children).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range children {
+		if err := loadSubtreeNPlusOne(ctx, db, &children[i], depth-1); err != nil {
+			return err
+		}
+	}
+
+	dept.Children = children
+	return nil
+}
+
+func countTree(dept *domain.Department) (departments, employees int) {
+	departments, employees = 1, len(dept.Employees)
+	for i := range dept.Children {
+		d, e := countTree(&dept.Children[i])
+		departments += d
+		employees += e
+	}
+	return departments, employees
+}
+
+func TestGetByIDWithChildren_DB(t *testing.T) {
+	db := openTestDB(t)
+	rootID := seedTree(t, db, 111, 10, 2)
+	queries := countQueries(t, db)
+
+	repo := repository.NewDepartmentRepository(db)
+	dept, err := repo.GetByIDWithChildren(context.Background(), rootID, 2, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d, e := countTree(dept); d != 111 || e != 222 {
+		t.Errorf("expected 111 departments and 222 employees, got %d and %d", d, e)
+	}
+	if *queries != 2 {
+		t.Errorf("expected 2 queries, got %d", *queries)
+	}
+
+	dept, err = repo.GetByIDWithChildren(context.Background(), rootID, 1, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d, e := countTree(dept); d != 11 || e != 0 {
+		t.Errorf("expected 11 departments without employees, got %d and %d", d, e)
+	}
+
+	if _, err := repo.GetByIDWithChildren(context.Background(), -1, 1, true); err != domain.ErrDepartmentNotFound {
+		t.Errorf("expected ErrDepartmentNotFound, got %v", err)
+	}
+}
+
+func BenchmarkGetByIDWithChildren(b *testing.B) {
+	db := openTestDB(b)
+	rootID := seedTree(b, db, 10_000, 10, 3)
+	queries := countQueries(b, db)
+	ctx := context.Background()
+
+	b.Run("recursive-cte", func(b *testing.B) {
+		repo := repository.NewDepartmentRepository(db)
+		*queries = 0
+		for b.Loop() {
+			if _, err := repo.GetByIDWithChildren(ctx, rootID, 5, true); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(*queries)/float64(b.N), "queries/op")
+	})
+
+	b.Run("n+1", func(b *testing.B) {
+		*queries = 0
+		for b.Loop() {
+			var root domain.Department
+			if err := db.Preload("Employees").First(&root, rootID).Error; err != nil {
+				b.Fatal(err)
+			}
+			if err := loadSubtreeNPlusOne(ctx, db, &root, 5); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(*queries)/float64(b.N), "queries/op")
+	})
+}
+
+func TestIsDescendant_DB(t *testing.T) {
+	db := openTestDB(t)
+	repo := repository.NewDepartmentRepository(db)
+	ctx := context.Background()
+
+	// root -> a -> b -> c, root -> x
+	create := func(name string, parentID *int64) int64 {
+		dept := &domain.Department{Name: name, ParentID: parentID}
+		if err := repo.Create(ctx, dept); err != nil {
+			t.Fatalf("failed to create department: %v", err)
+		}
+		return dept.ID
+	}
+	root := create("root", nil)
+	a := create("a", &root)
+	b := create("b", &a)
+	c := create("c", &b)
+	x := create("x", &root)
+
+	cases := []struct {
+		ancestor, descendant int64
+		want                 bool
+	}{
+		{root, c, true},
+		{a, c, true},
+		{b, c, true},
+		{c, a, false},
+		{x, c, false},
+		{a, x, false},
+		{a, a, false},
+	}
+
+	chain, err := repo.GetAncestors(ctx, c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chain) != 4 || chain[0].ID != root || chain[3].ID != c {
+		t.Errorf("expected chain root..c, got %+v", chain)
+	}
+
+	for _, tc := range cases {
+		got, err := repo.IsDescendant(ctx, tc.ancestor, tc.descendant)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("IsDescendant(%d, %d) = %v, want %v", tc.ancestor, tc.descendant, got, tc.want)
+		}
+	}
+}
+
+func TestGetForestAndListRoots_DB(t *testing.T) {
+	db := openTestDB(t)
+	repo := repository.NewDepartmentRepository(db)
+	ctx := context.Background()
+
+	create := func(name string, parentID *int64) int64 {
+		dept := &domain.Department{Name: name, ParentID: parentID}
+		if err := repo.Create(ctx, dept); err != nil {
+			t.Fatalf("failed to create department: %v", err)
+		}
+		return dept.ID
+	}
+	company := create("Company", nil)
+	it := create("IT", &company)
+	create("Backend", &it)
+	create("Holding", nil)
+	create("Partners_1", nil)
+
+	forest, err := repo.GetForest(ctx, 1, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(forest) != 3 || forest[0].ID != company {
+		t.Fatalf("expected 3 roots starting with Company, got %+v", forest)
+	}
+	if len(forest[0].Children) != 1 || len(forest[0].Children[0].Children) != 0 {
+		t.Errorf("expected Company subtree limited to depth 1, got %+v", forest[0].Children)
+	}
+
+	page, err := repo.ListRoots(ctx, repository.DepartmentFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 3 || page[0].Name != "Company" || page[2].Name != "Partners_1" {
+		t.Errorf("unexpected roots page: %+v", page)
+	}
+
+	page, err = repo.ListRoots(ctx, repository.DepartmentFilter{
+		After: &repository.Cursor{Value: "Company", ID: company},
+		Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 2 || page[0].Name != "Holding" {
+		t.Errorf("unexpected page after cursor: %+v", page)
+	}
+
+	page, err = repo.ListRoots(ctx, repository.DepartmentFilter{Name: "s_", Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].Name != "Partners_1" {
+		t.Errorf("expected LIKE wildcards to be escaped, got %+v", page)
+	}
+}
+
+func TestSoftDeleteAndRestore_DB(t *testing.T) {
+	db := openTestDB(t)
+	repo := repository.NewDepartmentRepository(db)
+	empRepo := repository.NewEmployeeRepository(db)
+	ctx := context.Background()
+
+	create := func(name string, parentID *int64) int64 {
+		dept := &domain.Department{Name: name, ParentID: parentID}
+		if err := repo.Create(ctx, dept); err != nil {
+			t.Fatalf("failed to create department: %v", err)
+		}
+		return dept.ID
+	}
+	root := create("root", nil)
+	it := create("IT", &root)
+	backend := create("Backend", &it)
+	emp := &domain.Employee{DepartmentID: backend, FullName: "Alice", Position: "Developer"}
+	if err := empRepo.Create(ctx, emp); err != nil {
+		t.Fatalf("failed to create employee: %v", err)
+	}
+
+	if err := repo.DeleteCascade(ctx, it); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tree, err := repo.GetByIDWithChildren(ctx, root, 5, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tree.Children) != 0 {
+		t.Errorf("expected deleted subtree to be hidden, got %+v", tree.Children)
+	}
+	if _, err := empRepo.GetByID(ctx, emp.ID); err != domain.ErrEmployeeNotFound {
+		t.Errorf("expected deleted employee to be hidden, got %v", err)
+	}
+
+	// Имя удалённого подразделения можно занять
+	replacement := create("IT", &root)
+
+	deleted, err := repo.GetWithDeletedForUpdate(ctx, it)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := repo.Restore(ctx, it, deleted.DeletedAt.Time); err != domain.ErrDuplicateDepartmentName {
+		t.Fatalf("expected ErrDuplicateDepartmentName, got %v", err)
+	}
+
+	if err := repo.Delete(ctx, replacement); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	restoredDepts, restoredEmps, err := repo.Restore(ctx, it, deleted.DeletedAt.Time)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(restoredDepts) != 2 || len(restoredEmps) != 1 {
+		t.Errorf("expected 2 restored departments and 1 employee, got %d and %d", len(restoredDepts), len(restoredEmps))
+	}
+
+	tree, err = repo.GetByIDWithChildren(ctx, it, 5, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d, e := countTree(tree); d != 2 || e != 1 {
+		t.Errorf("expected restored subtree with 2 departments and 1 employee, got %d and %d", d, e)
+	}
+
+	trash, err := repo.ListDeleted(ctx, repository.TrashFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != replacement {
+		t.Fatalf("expected only the replacement in trash, got %+v", trash)
+	}
+
+	purged, err := repo.Purge(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(purged) != 1 || purged[0].ID != replacement {
+		t.Errorf("expected the replacement to be purged, got %+v", purged)
+	}
+	if _, err := repo.GetWithDeletedForUpdate(ctx, replacement); err != domain.ErrDepartmentNotFound {
+		t.Errorf("expected purged department to be gone, got %v", err)
+	}
+}
+
+func TestDepartmentHeadClearedOnEmployeeDelete_DB(t *testing.T) {
+	db := openTestDB(t)
+	repo := repository.NewDepartmentRepository(db)
+	empRepo := repository.NewEmployeeRepository(db)
+	ctx := context.Background()
+
+	dept := &domain.Department{Name: "Sales"}
+	if err := repo.Create(ctx, dept); err != nil {
+		t.Fatalf("failed to create department: %v", err)
+	}
+	emp := &domain.Employee{DepartmentID: dept.ID, FullName: "Alice", Position: "Head of Sales"}
+	if err := empRepo.Create(ctx, emp); err != nil {
+		t.Fatalf("failed to create employee: %v", err)
+	}
+
+	dept.HeadEmployeeID = &emp.ID
+	if err := repo.Update(ctx, dept); err != nil {
+		t.Fatalf("failed to set head: %v", err)
+	}
+	if err := empRepo.Delete(ctx, emp.ID); err != nil {
+		t.Fatalf("failed to delete employee: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, dept.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.HeadEmployeeID != nil {
+		t.Errorf("expected head to be cleared, got %d", *got.HeadEmployeeID)
+	}
+}
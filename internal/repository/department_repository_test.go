@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+)
+
+func TestBuildDepartmentTree(t *testing.T) {
+	parent := func(id int64) *int64 { return &id }
+
+	departments := []domain.Department{
+		{ID: 1, Name: "Company"},
+		{ID: 2, Name: "IT", ParentID: parent(1)},
+		{ID: 3, Name: "HR", ParentID: parent(1)},
+		{ID: 4, Name: "Backend", ParentID: parent(2)},
+	}
+	employees := []domain.Employee{
+		{ID: 1, DepartmentID: 4, FullName: "Alice"},
+		{ID: 2, DepartmentID: 1, FullName: "CEO"},
+		{ID: 3, DepartmentID: 4, FullName: "Bob"},
+	}
+
+	root := buildDepartmentTree(1, departments, employees)
+	if root == nil {
+		t.Fatal("expected root department")
+	}
+
+	if len(root.Employees) != 1 || root.Employees[0].FullName != "CEO" {
+		t.Errorf("unexpected root employees: %+v", root.Employees)
+	}
+	if len(root.Children) != 2 || root.Children[0].Name != "IT" || root.Children[1].Name != "HR" {
+		t.Fatalf("unexpected root children: %+v", root.Children)
+	}
+
+	backend := root.Children[0].Children
+	if len(backend) != 1 || backend[0].Name != "Backend" {
+		t.Fatalf("unexpected IT children: %+v", backend)
+	}
+	if len(backend[0].Employees) != 2 || backend[0].Employees[0].FullName != "Alice" || backend[0].Employees[1].FullName != "Bob" {
+		t.Errorf("expected employees to keep input order, got %+v", backend[0].Employees)
+	}
+}
+
+func TestBuildDepartmentTree_RootNotFound(t *testing.T) {
+	if root := buildDepartmentTree(42, []domain.Department{{ID: 1}}, nil); root != nil {
+		t.Errorf("expected nil, got %+v", root)
+	}
+}
+
+func TestBuildDepartmentForest(t *testing.T) {
+	parent := func(id int64) *int64 { return &id }
+
+	departments := []domain.Department{
+		{ID: 1, Name: "Company"},
+		{ID: 2, Name: "Holding"},
+		{ID: 3, Name: "IT", ParentID: parent(1)},
+		{ID: 4, Name: "Partners", ParentID: parent(2)},
+	}
+	employees := []domain.Employee{
+		{ID: 1, DepartmentID: 4, FullName: "Alice"},
+	}
+
+	forest := buildDepartmentForest([]int64{2, 1, 42}, departments, employees)
+	if len(forest) != 2 || forest[0].Name != "Holding" || forest[1].Name != "Company" {
+		t.Fatalf("expected roots in requested order, got %+v", forest)
+	}
+	if len(forest[0].Children) != 1 || len(forest[0].Children[0].Employees) != 1 {
+		t.Errorf("unexpected Holding subtree: %+v", forest[0].Children)
+	}
+	if len(forest[1].Children) != 1 || forest[1].Children[0].Name != "IT" {
+		t.Errorf("unexpected Company subtree: %+v", forest[1].Children)
+	}
+}
+
+// generateTree строит плоский список из n подразделений, где у каждого не более fanout детей
+func generateTree(n, fanout int) []domain.Department {
+	departments := make([]domain.Department, n)
+	for i := range departments {
+		departments[i] = domain.Department{ID: int64(i + 1), Name: "Dept", CreatedAt: time.Now()}
+		if i > 0 {
+			parentID := int64((i-1)/fanout + 1)
+			departments[i].ParentID = &parentID
+		}
+	}
+	return departments
+}
+
+func BenchmarkBuildDepartmentTree(b *testing.B) {
+	departments := generateTree(10_000, 10)
+
+	employees := make([]domain.Employee, 0, len(departments)*5)
+	for _, dept := range departments {
+		for range 5 {
+			employees = append(employees, domain.Employee{ID: int64(len(employees) + 1), DepartmentID: dept.ID})
+		}
+	}
+
+	b.ResetTimer()
+	for b.Loop() {
+		buildDepartmentTree(1, departments, employees)
+	}
+}
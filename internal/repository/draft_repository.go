@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DraftRepository определяет интерфейс для работы с черновиками реорганизации
+type DraftRepository interface {
+	Create(ctx context.Context, draft *domain.Draft) error
+	// GetByID загружает черновик вместе с операциями в порядке позиций
+	GetByID(ctx context.Context, id int64) (*domain.Draft, error)
+	// GetByIDForUpdate загружает черновик с операциями и блокирует его до конца транзакции
+	GetByIDForUpdate(ctx context.Context, id int64) (*domain.Draft, error)
+	// AddOperation добавляет операцию в конец черновика и заполняет её ID и Position
+	AddOperation(ctx context.Context, op *domain.DraftOperation) error
+	DeleteOperation(ctx context.Context, draftID, id int64) error
+	// Close переводит черновик в состояние status и запоминает время закрытия
+	Close(ctx context.Context, draft *domain.Draft, status string) error
+}
+
+type draftRepository struct {
+	db *gorm.DB
+}
+
+// NewDraftRepository создаёт новый экземпляр репозитория
+func NewDraftRepository(db *gorm.DB) DraftRepository {
+	return &draftRepository{db: db}
+}
+
+func (r *draftRepository) Create(ctx context.Context, draft *domain.Draft) error {
+	return r.db.WithContext(ctx).Omit("Operations").Create(draft).Error
+}
+
+func (r *draftRepository) GetByID(ctx context.Context, id int64) (*domain.Draft, error) {
+	return r.get(r.db.WithContext(ctx), id)
+}
+
+func (r *draftRepository) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Draft, error) {
+	return r.get(r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func (r *draftRepository) get(query *gorm.DB, id int64) (*domain.Draft, error) {
+	var draft domain.Draft
+	err := query.
+		Preload("Operations", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		First(&draft, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrDraftNotFound
+		}
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (r *draftRepository) AddOperation(ctx context.Context, op *domain.DraftOperation) error {
+	// Позиции не переиспользуются после удаления операций; конкурентные вставки
+	// упорядочены блокировкой черновика, которую держит вызывающая транзакция
+	err := r.db.WithContext(ctx).Model(&domain.DraftOperation{}).
+		Where("draft_id = ?", op.DraftID).
+		Select("COALESCE(MAX(position), 0) + 1").
+		Scan(&op.Position).Error
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(op).Error
+}
+
+func (r *draftRepository) DeleteOperation(ctx context.Context, draftID, id int64) error {
+	result := r.db.WithContext(ctx).Where("draft_id = ?", draftID).Delete(&domain.DraftOperation{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrDraftOperationNotFound
+	}
+	return nil
+}
+
+func (r *draftRepository) Close(ctx context.Context, draft *domain.Draft, status string) error {
+	closedAt := time.Now()
+	err := r.db.WithContext(ctx).Model(&domain.Draft{}).
+		Where("id = ?", draft.ID).
+		Updates(map[string]any{"status": status, "closed_at": closedAt}).Error
+	if err != nil {
+		return err
+	}
+
+	draft.Status = status
+	draft.ClosedAt = &closedAt
+	return nil
+}
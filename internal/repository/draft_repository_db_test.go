@@ -0,0 +1,67 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+)
+
+func TestDrafts_DB(t *testing.T) {
+	db := openTestDB(t)
+	drafts := repository.NewDraftRepository(db)
+	ctx := context.Background()
+
+	draft := &domain.Draft{Name: "Q3 reorg", Status: domain.DraftStatusOpen, CreatedBy: "alice"}
+	if err := drafts.Create(ctx, draft); err != nil {
+		t.Fatalf("create draft: %v", err)
+	}
+
+	var ops []*domain.DraftOperation
+	for _, name := range []string{"Platform", "Data", "Infra"} {
+		op := &domain.DraftOperation{DraftID: draft.ID, Type: domain.DraftOperationCreate, Name: name}
+		if err := drafts.AddOperation(ctx, op); err != nil {
+			t.Fatalf("add operation: %v", err)
+		}
+		ops = append(ops, op)
+	}
+	if ops[0].Position != 1 || ops[2].Position != 3 {
+		t.Fatalf("expected positions 1..3, got %d and %d", ops[0].Position, ops[2].Position)
+	}
+
+	if err := drafts.DeleteOperation(ctx, draft.ID, ops[1].ID); err != nil {
+		t.Fatalf("delete operation: %v", err)
+	}
+	if err := drafts.DeleteOperation(ctx, draft.ID+1, ops[0].ID); err != domain.ErrDraftOperationNotFound {
+		t.Errorf("expected operation not found in another draft, got %v", err)
+	}
+
+	// Позиции удалённых операций не переиспользуются
+	last := &domain.DraftOperation{DraftID: draft.ID, Type: domain.DraftOperationCreate, Name: "Ops"}
+	if err := drafts.AddOperation(ctx, last); err != nil {
+		t.Fatalf("add operation: %v", err)
+	}
+	if last.Position != 4 {
+		t.Errorf("expected position 4, got %d", last.Position)
+	}
+
+	if err := drafts.Close(ctx, draft, domain.DraftStatusApplied); err != nil {
+		t.Fatalf("close draft: %v", err)
+	}
+
+	got, err := drafts.GetByID(ctx, draft.ID)
+	if err != nil {
+		t.Fatalf("get draft: %v", err)
+	}
+	if got.Status != domain.DraftStatusApplied || got.ClosedAt == nil {
+		t.Errorf("expected applied draft, got %+v", got)
+	}
+	if len(got.Operations) != 3 || got.Operations[0].Name != "Platform" || got.Operations[2].Name != "Ops" {
+		t.Errorf("expected operations in position order, got %+v", got.Operations)
+	}
+
+	if _, err := drafts.GetByID(ctx, draft.ID+1); err != domain.ErrDraftNotFound {
+		t.Errorf("expected draft not found, got %v", err)
+	}
+}
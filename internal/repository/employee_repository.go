@@ -2,19 +2,60 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/org-structure-api/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // EmployeeRepository определяет интерфейс для работы с сотрудниками
 type EmployeeRepository interface {
 	Create(ctx context.Context, emp *domain.Employee) error
 	GetByID(ctx context.Context, id int64) (*domain.Employee, error)
+	// GetByIDs возвращает найденных сотрудников из ids в порядке возрастания ID
+	GetByIDs(ctx context.Context, ids []int64) ([]domain.Employee, error)
 	GetByDepartmentID(ctx context.Context, departmentID int64) ([]domain.Employee, error)
+	// CountByDepartmentIDs возвращает число сотрудников в подразделениях departmentIDs
+	CountByDepartmentIDs(ctx context.Context, departmentIDs []int64) (int64, error)
 	Update(ctx context.Context, emp *domain.Employee) error
 	Delete(ctx context.Context, id int64) error
-	ReassignToDepartment(ctx context.Context, fromDeptID, toDeptID int64) error
+	Transfer(ctx context.Context, emp *domain.Employee, effectiveDate time.Time) error
+	ReassignToDepartment(ctx context.Context, fromDeptID, toDeptID int64, effectiveDate time.Time) error
+	List(ctx context.Context, filter EmployeeFilter) ([]domain.Employee, error)
+	GetWithDeletedForUpdate(ctx context.Context, id int64) (*domain.Employee, error)
+	Restore(ctx context.Context, id int64) error
+	ListDeleted(ctx context.Context, filter TrashFilter) ([]domain.Employee, error)
+	Purge(ctx context.Context, deletedBefore time.Time) ([]domain.Employee, error)
+}
+
+// EmployeeFilter - параметры выборки списка сотрудников
+type EmployeeFilter struct {
+	DepartmentIDs []int64
+	Position      string
+	PositionID    *int64
+	HiredFrom     *time.Time
+	HiredTo       *time.Time
+	SortField     string
+	Desc          bool
+	// AsOf - дата, на которую выбираются сотрудники по назначениям в DepartmentIDs;
+	// nil означает текущее состояние
+	AsOf  *time.Time
+	After *Cursor
+	Limit int
+}
+
+// noHireDateSortValue - значение, которым при сортировке заменяется отсутствующая
+// дата найма: такие сотрудники идут так, будто наняты в самом конце
+const noHireDateSortValue = "9999-12-31"
+
+// employeeSortExpressions задаёт SQL-выражения для допустимых полей сортировки
+var employeeSortExpressions = map[string]string{
+	"full_name":  "full_name",
+	"position":   "position",
+	"hired_at":   "COALESCE(hired_at, DATE '" + noHireDateSortValue + "')",
+	"created_at": "created_at",
 }
 
 type employeeRepository struct {
@@ -27,7 +68,12 @@ func NewEmployeeRepository(db *gorm.DB) EmployeeRepository {
 }
 
 func (r *employeeRepository) Create(ctx context.Context, emp *domain.Employee) error {
-	return r.db.WithContext(ctx).Create(emp).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(emp).Error; err != nil {
+			return err
+		}
+		return syncEmployeeAssignments(tx, versionDate(), "id = ?", emp.ID)
+	})
 }
 
 func (r *employeeRepository) GetByID(ctx context.Context, id int64) (*domain.Employee, error) {
@@ -42,6 +88,12 @@ func (r *employeeRepository) GetByID(ctx context.Context, id int64) (*domain.Emp
 	return &emp, nil
 }
 
+func (r *employeeRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.Employee, error) {
+	var employees []domain.Employee
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("id ASC").Find(&employees).Error
+	return employees, err
+}
+
 func (r *employeeRepository) GetByDepartmentID(ctx context.Context, departmentID int64) ([]domain.Employee, error) {
 	var employees []domain.Employee
 	err := r.db.WithContext(ctx).
@@ -51,24 +103,205 @@ func (r *employeeRepository) GetByDepartmentID(ctx context.Context, departmentID
 	return employees, err
 }
 
+func (r *employeeRepository) CountByDepartmentIDs(ctx context.Context, departmentIDs []int64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Employee{}).
+		Where("department_id IN ?", departmentIDs).
+		Count(&count).Error
+	return count, err
+}
+
+// Update сохраняет сотрудника; смена подразделения начинает новое назначение с текущей даты
 func (r *employeeRepository) Update(ctx context.Context, emp *domain.Employee) error {
-	return r.db.WithContext(ctx).Save(emp).Error
+	return r.save(ctx, emp, versionDate())
+}
+
+// Transfer сохраняет сотрудника, переведённого в другое подразделение, и начинает
+// новое назначение с даты effectiveDate
+func (r *employeeRepository) Transfer(ctx context.Context, emp *domain.Employee, effectiveDate time.Time) error {
+	return r.save(ctx, emp, effectiveDate)
+}
+
+func (r *employeeRepository) save(ctx context.Context, emp *domain.Employee, date time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current domain.Employee
+		if err := tx.Select("department_id").First(&current, emp.ID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return domain.ErrEmployeeNotFound
+			}
+			return err
+		}
+
+		if err := tx.Save(emp).Error; err != nil {
+			return err
+		}
+
+		if current.DepartmentID == emp.DepartmentID {
+			return nil
+		}
+		return syncEmployeeAssignments(tx, date, "id = ?", emp.ID)
+	})
 }
 
 func (r *employeeRepository) Delete(ctx context.Context, id int64) error {
-	result := r.db.WithContext(ctx).Delete(&domain.Employee{}, id)
-	if result.Error != nil {
-		return result.Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&domain.Employee{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrEmployeeNotFound
+		}
+		return syncEmployeeAssignments(tx, versionDate(), "id = ?", id)
+	})
+}
+
+// ReassignToDepartment переводит всех сотрудников fromDeptID в toDeptID с даты effectiveDate
+func (r *employeeRepository) ReassignToDepartment(ctx context.Context, fromDeptID, toDeptID int64, effectiveDate time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Model(&domain.Employee{}).Where("department_id = ?", fromDeptID).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&domain.Employee{}).
+			Where("id IN ?", ids).
+			Update("department_id", toDeptID).Error
+		if err != nil {
+			return err
+		}
+		return syncEmployeeAssignments(tx, effectiveDate, "id IN ?", ids)
+	})
+}
+
+// GetWithDeletedForUpdate загружает сотрудника независимо от того, удалён ли он,
+// и блокирует строку до конца транзакции
+func (r *employeeRepository) GetWithDeletedForUpdate(ctx context.Context, id int64) (*domain.Employee, error) {
+	var emp domain.Employee
+	err := r.db.WithContext(ctx).Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&emp, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrEmployeeNotFound
+		}
+		return nil, err
 	}
-	if result.RowsAffected == 0 {
-		return domain.ErrEmployeeNotFound
+	return &emp, nil
+}
+
+// Restore снимает отметку об удалении с сотрудника
+func (r *employeeRepository) Restore(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Model(&domain.Employee{}).
+			Where("id = ?", id).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		return syncEmployeeAssignments(tx, versionDate(), "id = ?", id)
+	})
+}
+
+// ListDeleted возвращает страницу удалённых сотрудников
+func (r *employeeRepository) ListDeleted(ctx context.Context, filter TrashFilter) ([]domain.Employee, error) {
+	query, err := applyTrashFilter(r.db.WithContext(ctx), filter)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	var employees []domain.Employee
+	err = query.Find(&employees).Error
+	return employees, err
 }
 
-func (r *employeeRepository) ReassignToDepartment(ctx context.Context, fromDeptID, toDeptID int64) error {
-	return r.db.WithContext(ctx).
-		Model(&domain.Employee{}).
-		Where("department_id = ?", fromDeptID).
-		Update("department_id", toDeptID).Error
+// Purge окончательно удаляет сотрудников, удалённых раньше deletedBefore, и возвращает их
+func (r *employeeRepository) Purge(ctx context.Context, deletedBefore time.Time) ([]domain.Employee, error) {
+	var employees []domain.Employee
+	err := r.db.WithContext(ctx).Unscoped().
+		Clauses(clause.Returning{}).
+		Where("deleted_at < ?", deletedBefore).
+		Delete(&employees).Error
+	return employees, err
+}
+
+// List возвращает страницу сотрудников с фильтрацией и keyset-пагинацией по (поле сортировки, id)
+func (r *employeeRepository) List(ctx context.Context, filter EmployeeFilter) ([]domain.Employee, error) {
+	sortExpr, ok := employeeSortExpressions[filter.SortField]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", filter.SortField)
+	}
+
+	query := r.db.WithContext(ctx)
+	if filter.AsOf != nil {
+		// Подменяем таблицу сотрудников их состоянием на дату: подразделение берётся
+		// из назначения, удалённые позже сотрудники включаются
+		asOf := r.db.Unscoped().Model(&domain.Employee{}).
+			Select("employees.id, a.department_id, employees.full_name, employees.position, employees.position_id, employees.hired_at, employees.created_at").
+			Joins("INNER JOIN employee_assignments a ON a.employee_id = employees.id").
+			Where("a.valid_from <= ? AND (a.valid_to IS NULL OR a.valid_to > ?)", *filter.AsOf, *filter.AsOf)
+		query = query.Unscoped().Table("(?) AS employees", asOf)
+	}
+	query = query.Where("department_id IN ?", filter.DepartmentIDs)
+
+	if filter.Position != "" {
+		query = query.Where("position ILIKE ?", "%"+escapeLike(filter.Position)+"%")
+	}
+	if filter.PositionID != nil {
+		query = query.Where("position_id = ?", *filter.PositionID)
+	}
+	if filter.HiredFrom != nil {
+		query = query.Where("hired_at >= ?", *filter.HiredFrom)
+	}
+	if filter.HiredTo != nil {
+		query = query.Where("hired_at <= ?", *filter.HiredTo)
+	}
+
+	direction, op := "ASC", ">"
+	if filter.Desc {
+		direction, op = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		value, err := parseEmployeeSortValue(filter.SortField, filter.After.Value)
+		if err != nil {
+			return nil, domain.ErrInvalidCursor
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortExpr, op), value, filter.After.ID)
+	}
+
+	var employees []domain.Employee
+	err := query.
+		Order(fmt.Sprintf("%s %s, id %s", sortExpr, direction, direction)).
+		Limit(filter.Limit).
+		Find(&employees).Error
+	return employees, err
+}
+
+// EmployeeSortValue возвращает значение поля сортировки сотрудника для курсора
+func EmployeeSortValue(emp *domain.Employee, field string) string {
+	switch field {
+	case "full_name":
+		return emp.FullName
+	case "position":
+		return emp.Position
+	case "hired_at":
+		if emp.HiredAt == nil {
+			return noHireDateSortValue
+		}
+		return emp.HiredAt.Format("2006-01-02")
+	default:
+		return emp.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// parseEmployeeSortValue приводит значение из курсора к типу поля сортировки
+func parseEmployeeSortValue(field, value string) (any, error) {
+	switch field {
+	case "hired_at":
+		return time.Parse("2006-01-02", value)
+	case "created_at":
+		return time.Parse(time.RFC3339Nano, value)
+	default:
+		return value, nil
+	}
 }
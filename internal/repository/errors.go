@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Коды ошибок PostgreSQL, см. https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation      = "23505"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+func pgErrorCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// isUniqueViolation сообщает, нарушено ли ограничение уникальности
+func isUniqueViolation(err error) bool {
+	return pgErrorCode(err) == pgUniqueViolation
+}
+
+// isRetryable сообщает, можно ли повторить транзакцию, завершившуюся ошибкой err
+func isRetryable(err error) bool {
+	code := pgErrorCode(err)
+	return code == pgSerializationFailure || code == pgDeadlockDetected
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&pgconn.PgError{Code: pgSerializationFailure}, true},
+		{&pgconn.PgError{Code: pgDeadlockDetected}, true},
+		{fmt.Errorf("commit: %w", &pgconn.PgError{Code: pgDeadlockDetected}), true},
+		{&pgconn.PgError{Code: pgUniqueViolation}, false},
+		{errors.New("connection reset"), false},
+		{nil, false},
+	}
+
+	for _, tc := range cases {
+		if got := isRetryable(tc.err); got != tc.want {
+			t.Errorf("isRetryable(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
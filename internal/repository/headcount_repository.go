@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/org-structure-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HeadcountRepository определяет интерфейс для работы с утверждённой численностью
+type HeadcountRepository interface {
+	// Save создаёт или обновляет число утверждённых мест должности в подразделении
+	Save(ctx context.Context, slot *domain.HeadcountSlot) error
+	// Get возвращает места должности в подразделении с числом занятых
+	Get(ctx context.Context, departmentID, positionID int64) (*domain.HeadcountSlot, error)
+	// List возвращает места подразделений departmentIDs с числом занятых,
+	// упорядоченные по подразделению и названию должности
+	List(ctx context.Context, departmentIDs []int64) ([]domain.HeadcountSlot, error)
+	Delete(ctx context.Context, departmentID, positionID int64) error
+}
+
+type headcountRepository struct {
+	db *gorm.DB
+}
+
+// NewHeadcountRepository создаёт новый экземпляр репозитория
+func NewHeadcountRepository(db *gorm.DB) HeadcountRepository {
+	return &headcountRepository{db: db}
+}
+
+func (r *headcountRepository) Save(ctx context.Context, slot *domain.HeadcountSlot) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "department_id"}, {Name: "position_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"approved", "updated_at"}),
+		}).
+		Create(slot).Error
+}
+
+func (r *headcountRepository) Get(ctx context.Context, departmentID, positionID int64) (*domain.HeadcountSlot, error) {
+	var slot domain.HeadcountSlot
+	err := r.query(ctx).
+		Where("headcount_slots.department_id = ? AND headcount_slots.position_id = ?", departmentID, positionID).
+		First(&slot).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, domain.ErrHeadcountSlotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+func (r *headcountRepository) List(ctx context.Context, departmentIDs []int64) ([]domain.HeadcountSlot, error) {
+	var slots []domain.HeadcountSlot
+	if len(departmentIDs) == 0 {
+		return slots, nil
+	}
+
+	err := r.query(ctx).
+		Where("headcount_slots.department_id IN ?", departmentIDs).
+		Order("headcount_slots.department_id, lower(positions.title), headcount_slots.position_id").
+		Find(&slots).Error
+	return slots, err
+}
+
+// query выбирает места с названием должности и числом занятых
+func (r *headcountRepository) query(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&domain.HeadcountSlot{}).
+		Select(`headcount_slots.*, positions.title AS position_title,
+			(SELECT COUNT(*) FROM employees e
+			 WHERE e.department_id = headcount_slots.department_id
+			   AND e.position_id = headcount_slots.position_id
+			   AND e.deleted_at IS NULL) AS filled`).
+		Joins("JOIN positions ON positions.id = headcount_slots.position_id")
+}
+
+func (r *headcountRepository) Delete(ctx context.Context, departmentID, positionID int64) error {
+	result := r.db.WithContext(ctx).
+		Where("department_id = ? AND position_id = ?", departmentID, positionID).
+		Delete(&domain.HeadcountSlot{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrHeadcountSlotNotFound
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+)
+
+func TestHeadcount_DB(t *testing.T) {
+	db := openTestDB(t)
+	depts := repository.NewDepartmentRepository(db)
+	emps := repository.NewEmployeeRepository(db)
+	positions := repository.NewPositionRepository(db)
+	headcount := repository.NewHeadcountRepository(db)
+	ctx := context.Background()
+
+	it := &domain.Department{Name: "IT"}
+	if err := depts.Create(ctx, it); err != nil {
+		t.Fatalf("create department: %v", err)
+	}
+	developer, _, err := positions.GetOrCreateByTitle(ctx, "Developer")
+	if err != nil {
+		t.Fatalf("create position: %v", err)
+	}
+	analyst, _, err := positions.GetOrCreateByTitle(ctx, "Analyst")
+	if err != nil {
+		t.Fatalf("create position: %v", err)
+	}
+
+	for _, approved := range []int{1, 3} {
+		if err := headcount.Save(ctx, &domain.HeadcountSlot{DepartmentID: it.ID, PositionID: developer.ID, Approved: approved}); err != nil {
+			t.Fatalf("save slot: %v", err)
+		}
+	}
+	if err := headcount.Save(ctx, &domain.HeadcountSlot{DepartmentID: it.ID, PositionID: analyst.ID, Approved: 1}); err != nil {
+		t.Fatalf("save slot: %v", err)
+	}
+
+	var ids []int64
+	for _, name := range []string{"Anna", "Bob"} {
+		emp := &domain.Employee{DepartmentID: it.ID, FullName: name, Position: developer.Title, PositionID: &developer.ID}
+		if err := emps.Create(ctx, emp); err != nil {
+			t.Fatalf("create employee: %v", err)
+		}
+		ids = append(ids, emp.ID)
+	}
+	// Удалённые сотрудники места не занимают
+	if err := emps.Delete(ctx, ids[1]); err != nil {
+		t.Fatalf("delete employee: %v", err)
+	}
+
+	slot, err := headcount.Get(ctx, it.ID, developer.ID)
+	if err != nil {
+		t.Fatalf("get slot: %v", err)
+	}
+	if slot.Approved != 3 || slot.Filled != 1 || slot.PositionTitle != "Developer" || slot.Open() != 2 {
+		t.Errorf("unexpected slot: %+v", slot)
+	}
+
+	slots, err := headcount.List(ctx, []int64{it.ID})
+	if err != nil {
+		t.Fatalf("list slots: %v", err)
+	}
+	if len(slots) != 2 || slots[0].PositionID != analyst.ID || slots[1].PositionID != developer.ID {
+		t.Fatalf("expected slots ordered by position title, got %+v", slots)
+	}
+
+	// Должность без сотрудников, но с местами в плане занята и не удаляется
+	if used, err := positions.IsUsed(ctx, analyst.ID); err != nil || !used {
+		t.Errorf("expected position with headcount to be used, got %v, %v", used, err)
+	}
+	if err := positions.Delete(ctx, analyst.ID); err == nil {
+		t.Error("expected foreign key to keep position with headcount")
+	}
+
+	if err := headcount.Delete(ctx, it.ID, analyst.ID); err != nil {
+		t.Fatalf("delete slot: %v", err)
+	}
+	if _, err := headcount.Get(ctx, it.ID, analyst.ID); !errors.Is(err, domain.ErrHeadcountSlotNotFound) {
+		t.Errorf("expected slot not found, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Таблица department_closure хранит для каждого подразделения все пары
+// (предок, потомок) с расстоянием между ними, включая пару (id, id) с depth = 0.
+// Она дублирует иерархию из parent_id и поддерживается departmentRepository
+// при создании, перемещении и удалении подразделений
+
+// HierarchyRepository обслуживает таблицу замыкания иерархии подразделений
+type HierarchyRepository interface {
+	// Rebuild пересобирает таблицу замыкания из parent_id и возвращает число строк
+	Rebuild(ctx context.Context) (int64, error)
+	// Check сравнивает таблицу замыкания с иерархией из parent_id и возвращает
+	// не более limit расхождений
+	Check(ctx context.Context, limit int) ([]ClosureMismatch, error)
+}
+
+// ClosureMismatch - расхождение таблицы замыкания с parent_id. ExpectedDepth
+// равен nil для лишней строки, ActualDepth - для отсутствующей
+type ClosureMismatch struct {
+	AncestorID    int64
+	DescendantID  int64
+	ExpectedDepth *int
+	ActualDepth   *int
+}
+
+type hierarchyRepository struct {
+	db *gorm.DB
+}
+
+// NewHierarchyRepository создаёт новый репозиторий обслуживания иерархии
+func NewHierarchyRepository(db *gorm.DB) HierarchyRepository {
+	return &hierarchyRepository{db: db}
+}
+
+// expectedClosureCTE строит эталонное замыкание по parent_id
+const expectedClosureCTE = `
+	WITH RECURSIVE expected AS (
+		SELECT id AS ancestor_id, id AS descendant_id, 0 AS depth FROM departments
+		UNION ALL
+		SELECT e.ancestor_id, d.id, e.depth + 1 FROM departments d
+		INNER JOIN expected e ON d.parent_id = e.descendant_id
+		WHERE e.depth < ?
+	)
+`
+
+func (r *hierarchyRepository) Rebuild(ctx context.Context) (int64, error) {
+	var rows int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Блокируем изменения иерархии на время пересборки; чтение остаётся доступным
+		if err := tx.Exec("LOCK TABLE departments, department_closure IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM department_closure").Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`INSERT INTO department_closure (ancestor_id, descendant_id, depth) `+
+			expectedClosureCTE+`SELECT ancestor_id, descendant_id, depth FROM expected`, maxHierarchyDepth)
+		rows = result.RowsAffected
+		return result.Error
+	})
+	return rows, err
+}
+
+func (r *hierarchyRepository) Check(ctx context.Context, limit int) ([]ClosureMismatch, error) {
+	query := expectedClosureCTE + `
+		SELECT
+			COALESCE(e.ancestor_id, c.ancestor_id) AS ancestor_id,
+			COALESCE(e.descendant_id, c.descendant_id) AS descendant_id,
+			e.depth AS expected_depth,
+			c.depth AS actual_depth
+		FROM expected e
+		FULL OUTER JOIN department_closure c
+			ON c.ancestor_id = e.ancestor_id AND c.descendant_id = e.descendant_id
+		WHERE e.depth IS DISTINCT FROM c.depth
+		ORDER BY 1, 2
+		LIMIT ?
+	`
+
+	var mismatches []ClosureMismatch
+	err := r.db.WithContext(ctx).Raw(query, maxHierarchyDepth, limit).Scan(&mismatches).Error
+	return mismatches, err
+}
+
+// insertClosure добавляет строки замыкания для нового листового подразделения id
+func insertClosure(tx *gorm.DB, id int64, parentID *int64) error {
+	err := tx.Exec("INSERT INTO department_closure (ancestor_id, descendant_id, depth) VALUES (?, ?, 0)", id, id).Error
+	if err != nil || parentID == nil {
+		return err
+	}
+
+	return tx.Exec(`
+		INSERT INTO department_closure (ancestor_id, descendant_id, depth)
+		SELECT ancestor_id, ?, depth + 1 FROM department_closure WHERE descendant_id = ?
+	`, id, *parentID).Error
+}
+
+// moveClosure переносит поддерево id под newParentID (или делает его корневым):
+// удаляет связи поддерева со старыми предками и добавляет связи с новыми
+func moveClosure(tx *gorm.DB, id int64, newParentID *int64) error {
+	err := tx.Exec(`
+		DELETE FROM department_closure c
+		USING department_closure sub
+		WHERE sub.ancestor_id = ?
+			AND c.descendant_id = sub.descendant_id
+			AND c.ancestor_id IN (SELECT ancestor_id FROM department_closure WHERE descendant_id = ? AND depth > 0)
+	`, id, id).Error
+	if err != nil || newParentID == nil {
+		return err
+	}
+
+	return tx.Exec(`
+		INSERT INTO department_closure (ancestor_id, descendant_id, depth)
+		SELECT p.ancestor_id, sub.descendant_id, p.depth + sub.depth + 1
+		FROM department_closure p
+		CROSS JOIN department_closure sub
+		WHERE p.descendant_id = ? AND sub.ancestor_id = ?
+	`, *newParentID, id).Error
+}
+
+// detachChildrenClosure отрывает поддеревья всех прямых детей parentID от parentID
+// и его предков. Вызывается до обнуления parent_id у детей
+func detachChildrenClosure(tx *gorm.DB, parentID int64) error {
+	return tx.Exec(`
+		DELETE FROM department_closure c
+		USING department_closure sub, departments d
+		WHERE d.parent_id = ?
+			AND sub.ancestor_id = d.id
+			AND c.descendant_id = sub.descendant_id
+			AND c.ancestor_id IN (SELECT ancestor_id FROM department_closure WHERE descendant_id = ?)
+	`, parentID, parentID).Error
+}
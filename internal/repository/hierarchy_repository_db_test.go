@@ -0,0 +1,91 @@
+package repository_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+)
+
+func TestClosureSync_DB(t *testing.T) {
+	db := openTestDB(t)
+	repo := repository.NewDepartmentRepository(db)
+	hierarchy := repository.NewHierarchyRepository(db)
+	ctx := context.Background()
+
+	create := func(name string, parentID *int64) *domain.Department {
+		dept := &domain.Department{Name: name, ParentID: parentID}
+		if err := repo.Create(ctx, dept); err != nil {
+			t.Fatalf("failed to create department: %v", err)
+		}
+		return dept
+	}
+	assertConsistent := func(step string) {
+		t.Helper()
+		mismatches, err := hierarchy.Check(ctx, 10)
+		if err != nil {
+			t.Fatalf("%s: check failed: %v", step, err)
+		}
+		if len(mismatches) != 0 {
+			t.Fatalf("%s: closure is out of sync: %+v", step, mismatches)
+		}
+	}
+
+	// root -> a -> b -> c, root -> x
+	root := create("root", nil)
+	a := create("a", &root.ID)
+	b := create("b", &a.ID)
+	c := create("c", &b.ID)
+	x := create("x", &root.ID)
+	assertConsistent("create")
+
+	b.ParentID = &x.ID
+	if err := repo.Update(ctx, b); err != nil {
+		t.Fatalf("failed to move department: %v", err)
+	}
+	assertConsistent("move")
+
+	descendants, err := repo.GetAllDescendantIDs(ctx, x.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int64{b.ID, c.ID}; !slices.Equal(descendants, want) {
+		t.Errorf("expected descendants of x %v, got %v", want, descendants)
+	}
+	if ok, _ := repo.IsDescendant(ctx, a.ID, c.ID); ok {
+		t.Error("expected c to leave the a subtree after the move")
+	}
+
+	if err := repo.DetachChildren(ctx, x.ID); err != nil {
+		t.Fatalf("failed to detach children: %v", err)
+	}
+	assertConsistent("detach")
+
+	if err := repo.Delete(ctx, root.ID); err != nil {
+		t.Fatalf("failed to delete department: %v", err)
+	}
+	assertConsistent("delete")
+
+	if err := db.Exec("DELETE FROM department_closure WHERE descendant_id = ?", c.ID).Error; err != nil {
+		t.Fatalf("failed to corrupt closure: %v", err)
+	}
+	mismatches, err := hierarchy.Check(ctx, 10)
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if len(mismatches) != 2 {
+		t.Fatalf("expected 2 missing rows, got %+v", mismatches)
+	}
+	for _, m := range mismatches {
+		if m.DescendantID != c.ID || m.ExpectedDepth == nil || m.ActualDepth != nil {
+			t.Errorf("unexpected mismatch: %+v", m)
+		}
+	}
+
+	if _, err := hierarchy.Rebuild(ctx); err != nil {
+		t.Fatalf("rebuild failed: %v", err)
+	}
+	assertConsistent("rebuild")
+}
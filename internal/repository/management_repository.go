@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// ManagementRepository определяет интерфейс запросов по линии подчинения. Линия задаётся
+// руководителями подразделений: члены подразделения и руководители дочерних подразделений
+// подчиняются руководителю подразделения, а подразделения без руководителя прозрачны -
+// их члены и дочерние руководители подчиняются ближайшему руководителю выше
+type ManagementRepository interface {
+	// GetReports возвращает подчинённых руководителя до уровня maxDepth (0 - без ограничения),
+	// упорядоченных по уровню и ФИО; каждый подчинённый указан один раз на ближайшем уровне
+	GetReports(ctx context.Context, managerID int64, maxDepth int) ([]ReportLine, error)
+	// GetSpans возвращает руководителей, у которых прямых подчинённых больше threshold,
+	// по убыванию их числа
+	GetSpans(ctx context.Context, threshold int) ([]ManagerSpan, error)
+}
+
+// ReportLine - подчинённый и его непосредственный руководитель
+type ReportLine struct {
+	EmployeeID int64
+	ManagerID  int64
+	Depth      int
+}
+
+// ManagerSpan - руководитель и число его прямых подчинённых
+type ManagerSpan struct {
+	ManagerID     int64
+	DirectReports int
+}
+
+type managementRepository struct {
+	db *gorm.DB
+}
+
+// NewManagementRepository создаёт новый экземпляр репозитория
+func NewManagementRepository(db *gorm.DB) ManagementRepository {
+	return &managementRepository{db: db}
+}
+
+// managementEdgesCTE обходит подразделения вниз от тех, что удовлетворяют условию start,
+// и строит рёбра подчинения edges(manager_id, employee_id, depth). В walk для каждого
+// подразделения хранятся руководитель его членов, руководитель этого руководителя
+// (только там, где руководитель сменился) и уровень относительно стартовых подразделений.
+// Параметры: аргументы start, затем дважды ограничение уровня (0 - без ограничения)
+func managementEdgesCTE(start string) string {
+	return `
+		RECURSIVE walk AS (
+			SELECT id AS department_id, head_employee_id AS manager_id, NULL::bigint AS reports_to, 0 AS level
+			FROM departments
+			WHERE deleted_at IS NULL AND ` + start + `
+			UNION ALL
+			SELECT c.id,
+				COALESCE(c.head_employee_id, w.manager_id),
+				CASE WHEN c.head_employee_id IS DISTINCT FROM w.manager_id AND c.head_employee_id IS NOT NULL THEN w.manager_id END,
+				CASE WHEN c.head_employee_id IS DISTINCT FROM w.manager_id AND c.head_employee_id IS NOT NULL THEN w.level + 1 ELSE w.level END
+			FROM departments c
+			JOIN walk w ON c.parent_id = w.department_id
+			WHERE c.deleted_at IS NULL AND (? = 0 OR w.level < ?)
+		),
+		edges AS (
+			SELECT w.manager_id, e.id AS employee_id, w.level + 1 AS depth
+			FROM walk w
+			JOIN employees e ON e.department_id = w.department_id AND e.deleted_at IS NULL
+			WHERE w.manager_id IS NOT NULL AND e.id <> w.manager_id
+			UNION ALL
+			SELECT w.reports_to, w.manager_id, w.level
+			FROM walk w
+			WHERE w.reports_to IS NOT NULL
+		)
+	`
+}
+
+func (r *managementRepository) GetReports(ctx context.Context, managerID int64, maxDepth int) ([]ReportLine, error) {
+	query := `
+		WITH ` + managementEdgesCTE("head_employee_id = ?") + `,
+		reports AS (
+			SELECT DISTINCT ON (employee_id) employee_id, manager_id, depth
+			FROM edges
+			WHERE employee_id <> ?
+			ORDER BY employee_id, depth, manager_id
+		)
+		SELECT reports.employee_id, reports.manager_id, reports.depth
+		FROM reports
+		JOIN employees e ON e.id = reports.employee_id
+		WHERE ? = 0 OR reports.depth <= ?
+		ORDER BY reports.depth, e.full_name, e.id
+	`
+
+	var lines []ReportLine
+	err := r.db.WithContext(ctx).
+		Raw(query, managerID, maxDepth, maxDepth, managerID, maxDepth, maxDepth).
+		Scan(&lines).Error
+	return lines, err
+}
+
+func (r *managementRepository) GetSpans(ctx context.Context, threshold int) ([]ManagerSpan, error) {
+	query := `
+		WITH ` + managementEdgesCTE("parent_id IS NULL") + `
+		SELECT manager_id, COUNT(DISTINCT employee_id) AS direct_reports
+		FROM edges
+		GROUP BY manager_id
+		HAVING COUNT(DISTINCT employee_id) > ?
+		ORDER BY direct_reports DESC, manager_id
+	`
+
+	var spans []ManagerSpan
+	err := r.db.WithContext(ctx).Raw(query, 0, 0, threshold).Scan(&spans).Error
+	return spans, err
+}
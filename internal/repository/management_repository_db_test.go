@@ -0,0 +1,88 @@
+package repository_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+)
+
+func TestManagementLines_DB(t *testing.T) {
+	db := openTestDB(t)
+	depts := repository.NewDepartmentRepository(db)
+	emps := repository.NewEmployeeRepository(db)
+	management := repository.NewManagementRepository(db)
+	ctx := context.Background()
+
+	create := func(name string, parentID *int64) *domain.Department {
+		dept := &domain.Department{Name: name, ParentID: parentID}
+		if err := depts.Create(ctx, dept); err != nil {
+			t.Fatalf("create department: %v", err)
+		}
+		return dept
+	}
+	// Company (Anna) -> Sales (Bob) -> Team (без руководителя) -> Squad (Fay); Company -> Ops
+	company := create("Company", nil)
+	sales := create("Sales", &company.ID)
+	team := create("Team", &sales.ID)
+	squad := create("Squad", &team.ID)
+	ops := create("Ops", &company.ID)
+
+	ids := make(map[string]int64)
+	for _, e := range []struct {
+		name string
+		dept int64
+	}{
+		{"Anna", company.ID}, {"Bob", company.ID}, {"Carl", sales.ID}, {"Dina", sales.ID},
+		{"Eve", team.ID}, {"Fay", squad.ID}, {"Gus", squad.ID}, {"Hal", ops.ID},
+	} {
+		emp := &domain.Employee{DepartmentID: e.dept, FullName: e.name, Position: "Dev"}
+		if err := emps.Create(ctx, emp); err != nil {
+			t.Fatalf("create employee: %v", err)
+		}
+		ids[e.name] = emp.ID
+	}
+
+	for dept, head := range map[*domain.Department]string{company: "Anna", sales: "Bob", squad: "Fay"} {
+		id := ids[head]
+		dept.HeadEmployeeID = &id
+		if err := depts.Update(ctx, dept); err != nil {
+			t.Fatalf("set head: %v", err)
+		}
+	}
+
+	direct, err := management.GetReports(ctx, ids["Anna"], 1)
+	if err != nil {
+		t.Fatalf("get direct reports: %v", err)
+	}
+	want := []repository.ReportLine{
+		{EmployeeID: ids["Bob"], ManagerID: ids["Anna"], Depth: 1},
+		{EmployeeID: ids["Hal"], ManagerID: ids["Anna"], Depth: 1},
+	}
+	if !slices.Equal(direct, want) {
+		t.Errorf("expected direct reports %v, got %v", want, direct)
+	}
+
+	all, err := management.GetReports(ctx, ids["Anna"], 0)
+	if err != nil {
+		t.Fatalf("get all reports: %v", err)
+	}
+	if len(all) != 7 || all[6] != (repository.ReportLine{EmployeeID: ids["Gus"], ManagerID: ids["Fay"], Depth: 3}) {
+		t.Errorf("unexpected recursive reports: %v", all)
+	}
+
+	spans, err := management.GetSpans(ctx, 0)
+	if err != nil {
+		t.Fatalf("get spans: %v", err)
+	}
+	wantSpans := []repository.ManagerSpan{
+		{ManagerID: ids["Bob"], DirectReports: 4},
+		{ManagerID: ids["Anna"], DirectReports: 2},
+		{ManagerID: ids["Fay"], DirectReports: 1},
+	}
+	if !slices.Equal(spans, wantSpans) {
+		t.Errorf("expected spans %v, got %v", wantSpans, spans)
+	}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// Cursor - позиция для keyset-пагинации: значение поля сортировки
+// и ID последней записи предыдущей страницы
+type Cursor struct {
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// TrashFilter - параметры выборки удалённых записей; записи отсортированы
+// от удалённых последними к удалённым первыми
+type TrashFilter struct {
+	After *Cursor
+	Limit int
+}
+
+// DeletedAtCursor возвращает курсор корзины, указывающий на запись с заданными
+// временем удаления и ID
+func DeletedAtCursor(deletedAt time.Time, id int64) Cursor {
+	return Cursor{Value: deletedAt.Format(time.RFC3339Nano), ID: id}
+}
+
+// applyTrashFilter ограничивает запрос удалёнными записями и применяет пагинацию
+func applyTrashFilter(query *gorm.DB, filter TrashFilter) (*gorm.DB, error) {
+	query = query.Unscoped().Where("deleted_at IS NOT NULL")
+
+	if filter.After != nil {
+		after, err := time.Parse(time.RFC3339Nano, filter.After.Value)
+		if err != nil {
+			return nil, domain.ErrInvalidCursor
+		}
+		query = query.Where("(deleted_at, id) < (?, ?)", after, filter.After.ID)
+	}
+
+	return query.Order("deleted_at DESC, id DESC").Limit(filter.Limit), nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/org-structure-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PositionRepository определяет интерфейс для работы со справочником должностей
+type PositionRepository interface {
+	Create(ctx context.Context, position *domain.Position) error
+	GetByID(ctx context.Context, id int64) (*domain.Position, error)
+	// GetByIDForUpdate загружает должность и блокирует её до конца транзакции
+	GetByIDForUpdate(ctx context.Context, id int64) (*domain.Position, error)
+	// GetByTitle возвращает должность с названием title без учёта регистра
+	GetByTitle(ctx context.Context, title string) (*domain.Position, error)
+	// GetOrCreateByTitle возвращает должность с названием title без учёта регистра,
+	// создавая её, если такой нет; created сообщает, что должность создана
+	GetOrCreateByTitle(ctx context.Context, title string) (position *domain.Position, created bool, err error)
+	List(ctx context.Context, filter PositionFilter) ([]domain.Position, error)
+	// Update сохраняет должность и переносит её название в поле position сотрудников,
+	// включая удалённых; возвращает сотрудников, у которых поле изменилось
+	Update(ctx context.Context, position *domain.Position) ([]domain.Employee, error)
+	Delete(ctx context.Context, id int64) error
+	// IsUsed сообщает, указана ли должность хотя бы у одного сотрудника, включая удалённых,
+	// или в плане численности какого-либо подразделения
+	IsUsed(ctx context.Context, id int64) (bool, error)
+}
+
+// PositionFilter - параметры выборки должностей; должности отсортированы по названию
+type PositionFilter struct {
+	// Title - подстрока названия без учёта регистра
+	Title     string
+	JobFamily string
+	After     *Cursor
+	Limit     int
+}
+
+type positionRepository struct {
+	db *gorm.DB
+}
+
+// NewPositionRepository создаёт новый экземпляр репозитория
+func NewPositionRepository(db *gorm.DB) PositionRepository {
+	return &positionRepository{db: db}
+}
+
+func (r *positionRepository) Create(ctx context.Context, position *domain.Position) error {
+	err := r.db.WithContext(ctx).Create(position).Error
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicatePositionTitle
+	}
+	return err
+}
+
+func (r *positionRepository) GetByID(ctx context.Context, id int64) (*domain.Position, error) {
+	return r.get(r.db.WithContext(ctx), id)
+}
+
+func (r *positionRepository) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Position, error) {
+	return r.get(r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func (r *positionRepository) get(query *gorm.DB, id int64) (*domain.Position, error) {
+	var position domain.Position
+	err := query.First(&position, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, domain.ErrPositionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+func (r *positionRepository) GetByTitle(ctx context.Context, title string) (*domain.Position, error) {
+	var position domain.Position
+	err := r.db.WithContext(ctx).Where("lower(title) = lower(?)", title).First(&position).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, domain.ErrPositionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+func (r *positionRepository) GetOrCreateByTitle(ctx context.Context, title string) (*domain.Position, bool, error) {
+	db := r.db.WithContext(ctx)
+
+	// ON CONFLICT не даёт конкурирующим запросам создать одну должность дважды
+	result := db.Exec(`
+		INSERT INTO positions (title) VALUES (?)
+		ON CONFLICT ((lower(title))) DO NOTHING
+	`, title)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+
+	var position domain.Position
+	if err := db.Where("lower(title) = lower(?)", title).First(&position).Error; err != nil {
+		return nil, false, err
+	}
+	return &position, result.RowsAffected > 0, nil
+}
+
+func (r *positionRepository) List(ctx context.Context, filter PositionFilter) ([]domain.Position, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Position{})
+
+	if filter.Title != "" {
+		query = query.Where("title ILIKE ?", "%"+escapeLike(filter.Title)+"%")
+	}
+	if filter.JobFamily != "" {
+		query = query.Where("job_family = ?", filter.JobFamily)
+	}
+	if filter.After != nil {
+		query = query.Where("(lower(title), id) > (lower(?), ?)", filter.After.Value, filter.After.ID)
+	}
+
+	var positions []domain.Position
+	err := query.Order("lower(title), id").Limit(filter.Limit).Find(&positions).Error
+	return positions, err
+}
+
+func (r *positionRepository) Update(ctx context.Context, position *domain.Position) ([]domain.Employee, error) {
+	var renamed []domain.Employee
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(position).Error; err != nil {
+			if isUniqueViolation(err) {
+				return domain.ErrDuplicatePositionTitle
+			}
+			return err
+		}
+
+		return tx.Unscoped().Model(&renamed).
+			Clauses(clause.Returning{}).
+			Where("position_id = ? AND position <> ?", position.ID, position.Title).
+			Update("position", position.Title).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return renamed, nil
+}
+
+func (r *positionRepository) Delete(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Delete(&domain.Position{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPositionNotFound
+	}
+	return nil
+}
+
+func (r *positionRepository) IsUsed(ctx context.Context, id int64) (bool, error) {
+	var used bool
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT EXISTS (SELECT 1 FROM employees WHERE position_id = ?)
+			OR EXISTS (SELECT 1 FROM headcount_slots WHERE position_id = ?)
+	`, id, id).Scan(&used).Error
+	return used, err
+}
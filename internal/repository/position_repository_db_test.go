@@ -0,0 +1,103 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+)
+
+func TestPositions_DB(t *testing.T) {
+	db := openTestDB(t)
+	depts := repository.NewDepartmentRepository(db)
+	emps := repository.NewEmployeeRepository(db)
+	positions := repository.NewPositionRepository(db)
+	ctx := context.Background()
+
+	developer, created, err := positions.GetOrCreateByTitle(ctx, "Developer")
+	if err != nil || !created {
+		t.Fatalf("get or create: %v, created %v", err, created)
+	}
+	again, created, err := positions.GetOrCreateByTitle(ctx, "DEVELOPER")
+	if err != nil {
+		t.Fatalf("get or create: %v", err)
+	}
+	if created || again.ID != developer.ID || again.Title != "Developer" {
+		t.Errorf("expected existing position %d, got %+v", developer.ID, again)
+	}
+	if found, err := positions.GetByTitle(ctx, "developer"); err != nil || found.ID != developer.ID {
+		t.Errorf("expected position %d by title, got %+v, %v", developer.ID, found, err)
+	}
+	if _, err := positions.GetByTitle(ctx, "Designer"); !errors.Is(err, domain.ErrPositionNotFound) {
+		t.Errorf("expected position not found, got %v", err)
+	}
+	if err := positions.Create(ctx, &domain.Position{Title: "developer"}); !errors.Is(err, domain.ErrDuplicatePositionTitle) {
+		t.Errorf("expected duplicate title, got %v", err)
+	}
+
+	it := &domain.Department{Name: "IT"}
+	if err := depts.Create(ctx, it); err != nil {
+		t.Fatalf("create department: %v", err)
+	}
+	emp := &domain.Employee{DepartmentID: it.ID, FullName: "Anna", Position: developer.Title, PositionID: &developer.ID}
+	if err := emps.Create(ctx, emp); err != nil {
+		t.Fatalf("create employee: %v", err)
+	}
+	if err := emps.Delete(ctx, emp.ID); err != nil {
+		t.Fatalf("delete employee: %v", err)
+	}
+
+	used, err := positions.IsUsed(ctx, developer.ID)
+	if err != nil {
+		t.Fatalf("is used: %v", err)
+	}
+	if !used {
+		t.Error("expected position of a deleted employee to be used")
+	}
+
+	developer.Title = "Software Engineer"
+	renamed, err := positions.Update(ctx, developer)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if len(renamed) != 1 || renamed[0].ID != emp.ID || renamed[0].Position != "Software Engineer" {
+		t.Errorf("expected renamed employee %d, got %+v", emp.ID, renamed)
+	}
+	var title string
+	if err := db.Raw("SELECT position FROM employees WHERE id = ?", emp.ID).Scan(&title).Error; err != nil {
+		t.Fatalf("select employee: %v", err)
+	}
+	if title != "Software Engineer" {
+		t.Errorf("expected employee position to be renamed, got %q", title)
+	}
+
+	for _, title := range []string{"analyst", "Tester"} {
+		if err := positions.Create(ctx, &domain.Position{Title: title}); err != nil {
+			t.Fatalf("create position: %v", err)
+		}
+	}
+	page, err := positions.List(ctx, repository.PositionFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page) != 2 || page[0].Title != "analyst" || page[1].Title != "Software Engineer" {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+	last := page[1]
+	page, err = positions.List(ctx, repository.PositionFilter{After: &repository.Cursor{Value: last.Title, ID: last.ID}, Limit: 2})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page) != 1 || page[0].Title != "Tester" {
+		t.Errorf("unexpected second page: %+v", page)
+	}
+
+	if err := positions.Delete(ctx, page[0].ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := positions.GetByID(ctx, page[0].ID); !errors.Is(err, domain.ErrPositionNotFound) {
+		t.Errorf("expected position not found, got %v", err)
+	}
+}
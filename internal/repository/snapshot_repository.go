@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/org-structure-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// SnapshotRepository определяет интерфейс для работы со снимками структуры.
+// Снимки только создаются: изменение и удаление запрещены триггерами
+type SnapshotRepository interface {
+	// Create сохраняет снимок текущих подразделений и сотрудников под именем snap.Name
+	// со следующей для этого имени версией; заполняет ID, Version и CreatedAt
+	Create(ctx context.Context, snap *domain.Snapshot) error
+	GetByID(ctx context.Context, id int64) (*domain.Snapshot, error)
+	List(ctx context.Context, filter SnapshotFilter) ([]domain.Snapshot, error)
+	// GetForest возвращает корневые подразделения снимка с поддеревьями глубиной depth
+	GetForest(ctx context.Context, id int64, depth int, includeEmployees bool) ([]domain.Department, error)
+	// GetState возвращает все подразделения и сотрудников снимка
+	GetState(ctx context.Context, id int64) (*domain.OrgState, error)
+}
+
+// SnapshotFilter - параметры выборки снимков; снимки отсортированы от новых к старым
+type SnapshotFilter struct {
+	Name  string
+	After *Cursor
+	Limit int
+}
+
+type snapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewSnapshotRepository создаёт новый экземпляр репозитория
+func NewSnapshotRepository(db *gorm.DB) SnapshotRepository {
+	return &snapshotRepository{db: db}
+}
+
+// snapshotDepartmentsCTE выбирает подразделения снимка (параметр: ID снимка)
+const snapshotDepartmentsCTE = `
+	dept AS (
+		SELECT department_id AS id, name, parent_id, head_employee_id, created_at
+		FROM snapshot_departments
+		WHERE snapshot_id = ?
+	)
+`
+
+func (r *snapshotRepository) Create(ctx context.Context, snap *domain.Snapshot) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Сериализуем создание снимков с одним именем, чтобы версии шли подряд
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", "snapshot:"+snap.Name).Error; err != nil {
+			return err
+		}
+
+		// Заголовок и содержимое вставляются одним запросом, поэтому подразделения
+		// и сотрудники копируются из одного согласованного состояния
+		return tx.Raw(`
+			WITH s AS (
+				INSERT INTO snapshots (name, version, created_by)
+				SELECT ?, COALESCE(MAX(version), 0) + 1, ? FROM snapshots WHERE name = ?
+				RETURNING id, version, created_at
+			),
+			d AS (
+				INSERT INTO snapshot_departments (snapshot_id, department_id, name, parent_id, head_employee_id, created_at)
+				SELECT s.id, departments.id, departments.name, departments.parent_id, departments.head_employee_id, departments.created_at
+				FROM s, departments
+				WHERE departments.deleted_at IS NULL
+			),
+			e AS (
+				INSERT INTO snapshot_employees (snapshot_id, employee_id, department_id, full_name, position, position_id, hired_at, created_at)
+				SELECT s.id, employees.id, employees.department_id, employees.full_name, employees.position, employees.position_id, employees.hired_at, employees.created_at
+				FROM s, employees
+				WHERE employees.deleted_at IS NULL
+			)
+			SELECT id, version, created_at FROM s
+		`, snap.Name, snap.CreatedBy, snap.Name).Row().Scan(&snap.ID, &snap.Version, &snap.CreatedAt)
+	})
+}
+
+func (r *snapshotRepository) GetByID(ctx context.Context, id int64) (*domain.Snapshot, error) {
+	var snap domain.Snapshot
+	err := r.db.WithContext(ctx).First(&snap, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, domain.ErrSnapshotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func (r *snapshotRepository) List(ctx context.Context, filter SnapshotFilter) ([]domain.Snapshot, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Snapshot{})
+
+	if filter.Name != "" {
+		query = query.Where("name = ?", filter.Name)
+	}
+	if filter.After != nil {
+		query = query.Where("id < ?", filter.After.ID)
+	}
+
+	var snapshots []domain.Snapshot
+	err := query.Order("id DESC").Limit(filter.Limit).Find(&snapshots).Error
+	return snapshots, err
+}
+
+func (r *snapshotRepository) GetForest(ctx context.Context, id int64, depth int, includeEmployees bool) ([]domain.Department, error) {
+	departments, err := loadDepartmentTree(r.db.WithContext(ctx), snapshotDepartmentsCTE, []any{id}, "parent_id IS NULL", nil, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	var employees []domain.Employee
+	if len(departments) > 0 && includeEmployees {
+		employees, err = r.employees(ctx, id, "department_id IN ?", departmentIDs(departments))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var rootIDs []int64
+	for _, dept := range departments {
+		if dept.ParentID == nil {
+			rootIDs = append(rootIDs, dept.ID)
+		}
+	}
+
+	return buildDepartmentForest(rootIDs, departments, employees), nil
+}
+
+func (r *snapshotRepository) GetState(ctx context.Context, id int64) (*domain.OrgState, error) {
+	var state domain.OrgState
+	err := r.db.WithContext(ctx).
+		Raw("WITH "+snapshotDepartmentsCTE+" SELECT id, name, parent_id, head_employee_id, created_at FROM dept ORDER BY id", id).
+		Scan(&state.Departments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	employees, err := r.employees(ctx, id, "TRUE")
+	if err != nil {
+		return nil, err
+	}
+	state.Employees = employees
+	return &state, nil
+}
+
+// employees выбирает сотрудников снимка id, удовлетворяющих условию cond
+func (r *snapshotRepository) employees(ctx context.Context, id int64, cond string, args ...any) ([]domain.Employee, error) {
+	query := `
+		SELECT employee_id AS id, department_id, full_name, position, position_id, hired_at, created_at
+		FROM snapshot_employees
+		WHERE snapshot_id = ? AND ` + cond + `
+		ORDER BY created_at ASC, employee_id ASC
+	`
+
+	var employees []domain.Employee
+	err := r.db.WithContext(ctx).Raw(query, append([]any{id}, args...)...).Scan(&employees).Error
+	return employees, err
+}
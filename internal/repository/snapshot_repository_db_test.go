@@ -0,0 +1,105 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+)
+
+func TestSnapshots_DB(t *testing.T) {
+	db := openTestDB(t)
+	depts := repository.NewDepartmentRepository(db)
+	emps := repository.NewEmployeeRepository(db)
+	snapshots := repository.NewSnapshotRepository(db)
+	ctx := context.Background()
+
+	root := &domain.Department{Name: "Company"}
+	if err := depts.Create(ctx, root); err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	child := &domain.Department{Name: "IT", ParentID: &root.ID}
+	if err := depts.Create(ctx, child); err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	dev := &domain.Position{Title: "Dev"}
+	if err := repository.NewPositionRepository(db).Create(ctx, dev); err != nil {
+		t.Fatalf("create position: %v", err)
+	}
+	emp := &domain.Employee{DepartmentID: child.ID, FullName: "John", Position: "Dev", PositionID: &dev.ID}
+	if err := emps.Create(ctx, emp); err != nil {
+		t.Fatalf("create employee: %v", err)
+	}
+	child.HeadEmployeeID = &emp.ID
+	if err := depts.Update(ctx, child); err != nil {
+		t.Fatalf("set head: %v", err)
+	}
+
+	first := &domain.Snapshot{Name: "Q1", CreatedBy: "alice"}
+	if err := snapshots.Create(ctx, first); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	if first.ID == 0 || first.Version != 1 || first.CreatedAt.IsZero() {
+		t.Fatalf("expected filled snapshot, got %+v", first)
+	}
+
+	child.Name = "Platform"
+	if err := depts.Update(ctx, child); err != nil {
+		t.Fatalf("rename child: %v", err)
+	}
+	if err := emps.Delete(ctx, emp.ID); err != nil {
+		t.Fatalf("delete employee: %v", err)
+	}
+
+	second := &domain.Snapshot{Name: "Q1", CreatedBy: "alice"}
+	if err := snapshots.Create(ctx, second); err != nil {
+		t.Fatalf("create second snapshot: %v", err)
+	}
+	if second.Version != 2 {
+		t.Errorf("expected version 2, got %d", second.Version)
+	}
+
+	forest, err := snapshots.GetForest(ctx, first.ID, 5, true)
+	if err != nil {
+		t.Fatalf("get forest: %v", err)
+	}
+	if len(forest) != 1 || len(forest[0].Children) != 1 || forest[0].Children[0].Name != "IT" || len(forest[0].Children[0].Employees) != 1 {
+		t.Fatalf("expected frozen Company > IT with John, got %+v", forest)
+	}
+	frozen := forest[0].Children[0]
+	if frozen.HeadEmployeeID == nil || *frozen.HeadEmployeeID != emp.ID {
+		t.Errorf("expected head %d in snapshot, got %v", emp.ID, frozen.HeadEmployeeID)
+	}
+	if pos := frozen.Employees[0].PositionID; pos == nil || *pos != dev.ID {
+		t.Errorf("expected position %d in snapshot, got %v", dev.ID, pos)
+	}
+
+	state, err := snapshots.GetState(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("get state: %v", err)
+	}
+	if len(state.Departments) != 2 || state.Departments[1].Name != "Platform" || len(state.Employees) != 0 {
+		t.Errorf("unexpected second snapshot: %+v", state)
+	}
+
+	list, err := snapshots.List(ctx, repository.SnapshotFilter{Name: "Q1", Limit: 10})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != second.ID {
+		t.Errorf("expected newest snapshot first, got %+v", list)
+	}
+
+	if _, err := snapshots.GetByID(ctx, 999); err != domain.ErrSnapshotNotFound {
+		t.Errorf("expected snapshot not found, got %v", err)
+	}
+
+	// Снимки неизменяемы
+	if err := db.Exec("UPDATE snapshot_departments SET name = 'x'").Error; err == nil {
+		t.Error("expected update of snapshot_departments to be rejected")
+	}
+	if err := db.Exec("DELETE FROM snapshots").Error; err == nil {
+		t.Error("expected delete of snapshots to be rejected")
+	}
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// Таблицы department_versions и employee_assignments хранят историю имени
+// и родителя подразделений и назначений сотрудников с интервалами действия
+// [valid_from, valid_to) в днях. Они поддерживаются departmentRepository и
+// employeeRepository в той же транзакции, что и изменения самих записей.
+// Изменение с датой date заменяет историю начиная с этой даты: версии,
+// начавшиеся не раньше date, удаляются, а пересекающие её - обрезаются
+
+// TemporalRepository читает состояние организации на прошедшую дату
+type TemporalRepository interface {
+	// GetSubtree возвращает подразделение с поддеревом глубиной depth на дату asOf
+	GetSubtree(ctx context.Context, id int64, depth int, includeEmployees bool, asOf time.Time) (*domain.Department, error)
+	// GetForest возвращает корневые подразделения с поддеревьями глубиной depth на дату asOf
+	GetForest(ctx context.Context, depth int, includeEmployees bool, asOf time.Time) ([]domain.Department, error)
+	// GetAncestors возвращает цепочку подразделений от корня до id на дату asOf
+	GetAncestors(ctx context.Context, id int64, asOf time.Time) ([]domain.Department, error)
+	// GetSubtreeIDs возвращает ID подразделения и всех его потомков на дату asOf
+	GetSubtreeIDs(ctx context.Context, id int64, asOf time.Time) ([]int64, error)
+	// GetEmployee возвращает сотрудника с подразделением, в котором он состоял на дату asOf
+	GetEmployee(ctx context.Context, id int64, asOf time.Time) (*domain.Employee, error)
+	// GetState возвращает все подразделения и сотрудников организации на дату asOf
+	GetState(ctx context.Context, asOf time.Time) (*domain.OrgState, error)
+}
+
+type temporalRepository struct {
+	db *gorm.DB
+}
+
+// NewTemporalRepository создаёт новый экземпляр репозитория
+func NewTemporalRepository(db *gorm.DB) TemporalRepository {
+	return &temporalRepository{db: db}
+}
+
+// departmentsAsOfCTE выбирает подразделения в состоянии на дату (параметры: дата дважды)
+const departmentsAsOfCTE = `
+	dept AS (
+		SELECT v.department_id AS id, v.name, v.parent_id, d.created_at
+		FROM department_versions v
+		INNER JOIN departments d ON d.id = v.department_id
+		WHERE v.valid_from <= ? AND (v.valid_to IS NULL OR v.valid_to > ?)
+	)
+`
+
+func (r *temporalRepository) GetSubtree(ctx context.Context, id int64, depth int, includeEmployees bool, asOf time.Time) (*domain.Department, error) {
+	departments, employees, err := r.loadSubtrees(ctx, "id = ?", []any{id}, depth, includeEmployees, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if len(departments) == 0 {
+		return nil, domain.ErrDepartmentNotFound
+	}
+
+	return buildDepartmentTree(id, departments, employees), nil
+}
+
+func (r *temporalRepository) GetForest(ctx context.Context, depth int, includeEmployees bool, asOf time.Time) ([]domain.Department, error) {
+	departments, employees, err := r.loadSubtrees(ctx, "parent_id IS NULL", nil, depth, includeEmployees, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	var rootIDs []int64
+	for _, dept := range departments {
+		if dept.ParentID == nil {
+			rootIDs = append(rootIDs, dept.ID)
+		}
+	}
+
+	return buildDepartmentForest(rootIDs, departments, employees), nil
+}
+
+// loadSubtrees загружает плоским списком подразделения, выбранные условием rootCond,
+// и их потомков до глубины depth в состоянии на дату asOf, а при includeEmployees -
+// и сотрудников, состоявших в них на эту дату
+func (r *temporalRepository) loadSubtrees(ctx context.Context, rootCond string, rootArgs []any, depth int, includeEmployees bool, asOf time.Time) ([]domain.Department, []domain.Employee, error) {
+	departments, err := loadDepartmentTree(r.db.WithContext(ctx), departmentsAsOfCTE, []any{asOf, asOf}, rootCond, rootArgs, depth)
+	if err != nil || len(departments) == 0 || !includeEmployees {
+		return departments, nil, err
+	}
+
+	employees, err := r.employeesAsOf(ctx, asOf, "a.department_id IN ?", departmentIDs(departments))
+	if err != nil {
+		return nil, nil, err
+	}
+	return departments, employees, nil
+}
+
+// loadDepartmentTree загружает плоским списком подразделения из CTE dept (deptCTE
+// с параметрами cteArgs), выбранные условием rootCond, и их потомков до глубины depth.
+// Поля подразделений заполняются по столбцам dept
+func loadDepartmentTree(db *gorm.DB, deptCTE string, cteArgs []any, rootCond string, rootArgs []any, depth int) ([]domain.Department, error) {
+	query := `
+		WITH RECURSIVE ` + deptCTE + `,
+		tree AS (
+			SELECT id, 0 AS depth FROM dept WHERE ` + rootCond + `
+			UNION ALL
+			SELECT dept.id, tree.depth + 1 FROM dept
+			INNER JOIN tree ON dept.parent_id = tree.id
+			WHERE tree.depth < ?
+		)
+		SELECT dept.*
+		FROM tree
+		INNER JOIN dept ON dept.id = tree.id
+		ORDER BY dept.id
+	`
+
+	args := append(append(append([]any{}, cteArgs...), rootArgs...), max(depth, 0))
+
+	var departments []domain.Department
+	err := db.Raw(query, args...).Scan(&departments).Error
+	return departments, err
+}
+
+// departmentIDs возвращает ID подразделений в том же порядке
+func departmentIDs(departments []domain.Department) []int64 {
+	ids := make([]int64, len(departments))
+	for i, dept := range departments {
+		ids[i] = dept.ID
+	}
+	return ids
+}
+
+// employeesAsOf выбирает сотрудников по назначениям, действовавшим на дату asOf.
+// Удалённые позже сотрудники включаются; ФИО и должность берутся текущие
+func (r *temporalRepository) employeesAsOf(ctx context.Context, asOf time.Time, cond string, args ...any) ([]domain.Employee, error) {
+	query := `
+		SELECT e.id, a.department_id, e.full_name, e.position, e.position_id, e.hired_at, e.created_at
+		FROM employee_assignments a
+		INNER JOIN employees e ON e.id = a.employee_id
+		WHERE a.valid_from <= ? AND (a.valid_to IS NULL OR a.valid_to > ?) AND ` + cond + `
+		ORDER BY e.created_at ASC, e.id ASC
+	`
+
+	var employees []domain.Employee
+	err := r.db.WithContext(ctx).Raw(query, append([]any{asOf, asOf}, args...)...).Scan(&employees).Error
+	return employees, err
+}
+
+func (r *temporalRepository) GetAncestors(ctx context.Context, id int64, asOf time.Time) ([]domain.Department, error) {
+	query := `
+		WITH RECURSIVE ` + departmentsAsOfCTE + `,
+		chain AS (
+			SELECT id, name, parent_id, created_at, 0 AS level FROM dept WHERE id = ?
+			UNION ALL
+			SELECT dept.id, dept.name, dept.parent_id, dept.created_at, chain.level + 1 FROM dept
+			INNER JOIN chain ON dept.id = chain.parent_id
+			WHERE chain.level < ?
+		)
+		SELECT id, name, parent_id, created_at FROM chain ORDER BY level DESC
+	`
+
+	var chain []domain.Department
+	if err := r.db.WithContext(ctx).Raw(query, asOf, asOf, id, maxHierarchyDepth).Scan(&chain).Error; err != nil {
+		return nil, err
+	}
+	if len(chain) == 0 {
+		return nil, domain.ErrDepartmentNotFound
+	}
+	return chain, nil
+}
+
+func (r *temporalRepository) GetSubtreeIDs(ctx context.Context, id int64, asOf time.Time) ([]int64, error) {
+	departments, _, err := r.loadSubtrees(ctx, "id = ?", []any{id}, maxHierarchyDepth, false, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if len(departments) == 0 {
+		return nil, domain.ErrDepartmentNotFound
+	}
+	return departmentIDs(departments), nil
+}
+
+func (r *temporalRepository) GetEmployee(ctx context.Context, id int64, asOf time.Time) (*domain.Employee, error) {
+	employees, err := r.employeesAsOf(ctx, asOf, "e.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(employees) == 0 {
+		return nil, domain.ErrEmployeeNotFound
+	}
+	return &employees[0], nil
+}
+
+func (r *temporalRepository) GetState(ctx context.Context, asOf time.Time) (*domain.OrgState, error) {
+	query := `WITH ` + departmentsAsOfCTE + ` SELECT id, name, parent_id, created_at FROM dept ORDER BY id`
+
+	var state domain.OrgState
+	if err := r.db.WithContext(ctx).Raw(query, asOf, asOf).Scan(&state.Departments).Error; err != nil {
+		return nil, err
+	}
+
+	employees, err := r.employeesAsOf(ctx, asOf, "TRUE")
+	if err != nil {
+		return nil, err
+	}
+	state.Employees = employees
+	return &state, nil
+}
+
+// versionDate возвращает дату (UTC), с которой действуют изменения, сделанные сейчас.
+// Изменений с датой в будущем история не хранит: запланированная реорганизация
+// готовится в черновике и применяется в свой день
+func versionDate() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// syncDepartmentVersions начинает с даты date новую версию подразделений, выбранных
+// условием cond на таблицу departments; удалённые подразделения получают только
+// завершение версии
+func syncDepartmentVersions(tx *gorm.DB, date time.Time, cond string, args ...any) error {
+	ids := "SELECT id FROM departments WHERE " + cond
+	if err := closeVersions(tx, "department_versions", "department_id", ids, date, args); err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		INSERT INTO department_versions (department_id, name, parent_id, valid_from)
+		SELECT id, name, parent_id, ? FROM departments WHERE deleted_at IS NULL AND (`+cond+`)
+	`, append([]any{date}, args...)...).Error
+}
+
+// syncEmployeeAssignments начинает с даты date новое назначение сотрудников,
+// выбранных условием cond на таблицу employees; удалённые сотрудники получают
+// только завершение назначения
+func syncEmployeeAssignments(tx *gorm.DB, date time.Time, cond string, args ...any) error {
+	ids := "SELECT id FROM employees WHERE " + cond
+	if err := closeVersions(tx, "employee_assignments", "employee_id", ids, date, args); err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		INSERT INTO employee_assignments (employee_id, department_id, valid_from)
+		SELECT id, department_id, ? FROM employees WHERE deleted_at IS NULL AND (`+cond+`)
+	`, append([]any{date}, args...)...).Error
+}
+
+// closeVersions завершает датой date историю записей с ключом key из подзапроса ids:
+// версию, начатую в тот же день, удаляет, а действующие на date - обрезает. Если у
+// записей уже есть версия, начатая позже date, возвращает ErrEffectiveDateTooEarly:
+// изменение задним числом не должно стирать более позднюю историю
+func closeVersions(tx *gorm.DB, table, key, ids string, date time.Time, args []any) error {
+	var later int64
+	err := tx.Raw("SELECT COUNT(*) FROM "+table+" WHERE valid_from > ? AND "+key+" IN ("+ids+")",
+		append([]any{date}, args...)...).Scan(&later).Error
+	if err != nil {
+		return err
+	}
+	if later > 0 {
+		return domain.ErrEffectiveDateTooEarly
+	}
+
+	err = tx.Exec("DELETE FROM "+table+" WHERE valid_from = ? AND "+key+" IN ("+ids+")",
+		append([]any{date}, args...)...).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec("UPDATE "+table+" SET valid_to = ? WHERE (valid_to IS NULL OR valid_to > ?) AND "+key+" IN ("+ids+")",
+		append([]any{date, date}, args...)...).Error
+}
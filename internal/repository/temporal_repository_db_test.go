@@ -0,0 +1,188 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+)
+
+func TestTemporalReads_DB(t *testing.T) {
+	db := openTestDB(t)
+	depts := repository.NewDepartmentRepository(db)
+	emps := repository.NewEmployeeRepository(db)
+	temporal := repository.NewTemporalRepository(db)
+	ctx := context.Background()
+
+	root := &domain.Department{Name: "Company"}
+	if err := depts.Create(ctx, root); err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	child := &domain.Department{Name: "Backend", ParentID: &root.ID}
+	if err := depts.Create(ctx, child); err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	emp := &domain.Employee{DepartmentID: child.ID, FullName: "John", Position: "Dev"}
+	if err := emps.Create(ctx, emp); err != nil {
+		t.Fatalf("create employee: %v", err)
+	}
+
+	// Сдвигаем историю на 10 дней в прошлое, как будто записи созданы тогда
+	for _, table := range []string{"department_versions", "employee_assignments"} {
+		if err := db.Exec("UPDATE " + table + " SET valid_from = valid_from - 10").Error; err != nil {
+			t.Fatalf("shift %s: %v", table, err)
+		}
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+
+	child.Name = "Platform"
+	child.ParentID = nil
+	if err := depts.Update(ctx, child); err != nil {
+		t.Fatalf("move child: %v", err)
+	}
+
+	past, err := temporal.GetSubtree(ctx, root.ID, 2, true, yesterday)
+	if err != nil {
+		t.Fatalf("get subtree: %v", err)
+	}
+	if len(past.Children) != 1 || past.Children[0].Name != "Backend" || len(past.Children[0].Employees) != 1 {
+		t.Fatalf("expected Backend with John under Company yesterday, got %+v", past.Children)
+	}
+
+	forest, err := temporal.GetForest(ctx, 1, false, today)
+	if err != nil {
+		t.Fatalf("get forest: %v", err)
+	}
+	if len(forest) != 2 || forest[1].Name != "Platform" {
+		t.Errorf("expected Platform to be a root today, got %+v", forest)
+	}
+
+	if _, err := temporal.GetSubtree(ctx, root.ID, 1, false, today.AddDate(0, 0, -11)); err != domain.ErrDepartmentNotFound {
+		t.Errorf("expected department not found before history, got %v", err)
+	}
+
+	// Задним числом переводим сотрудника в корень пять дней назад
+	transferDate := today.AddDate(0, 0, -5)
+	emp.DepartmentID = root.ID
+	if err := emps.Transfer(ctx, emp, transferDate); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	for date, want := range map[time.Time]int64{transferDate.AddDate(0, 0, -1): child.ID, transferDate: root.ID} {
+		got, err := temporal.GetEmployee(ctx, emp.ID, date)
+		if err != nil {
+			t.Fatalf("get employee as of %v: %v", date, err)
+		}
+		if got.DepartmentID != want {
+			t.Errorf("as of %v: expected department %d, got %d", date, want, got.DepartmentID)
+		}
+	}
+
+	asOf := transferDate.AddDate(0, 0, -1)
+	list, err := emps.List(ctx, repository.EmployeeFilter{
+		DepartmentIDs: []int64{child.ID},
+		SortField:     "full_name",
+		AsOf:          &asOf,
+		Limit:         10,
+	})
+	if err != nil {
+		t.Fatalf("list as of: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != emp.ID || list[0].DepartmentID != child.ID {
+		t.Errorf("expected John in Backend before the transfer, got %+v", list)
+	}
+
+	state, err := temporal.GetState(ctx, today)
+	if err != nil {
+		t.Fatalf("get state: %v", err)
+	}
+	if len(state.Departments) != 2 || len(state.Employees) != 1 || state.Employees[0].DepartmentID != root.ID {
+		t.Errorf("unexpected state today: %+v", state)
+	}
+
+	// Перевод раньше уже записанного не должен стирать его из истории
+	emp.DepartmentID = child.ID
+	if err := emps.Transfer(ctx, emp, transferDate.AddDate(0, 0, -2)); err != domain.ErrEffectiveDateTooEarly {
+		t.Fatalf("expected ErrEffectiveDateTooEarly, got %v", err)
+	}
+	got, err := temporal.GetEmployee(ctx, emp.ID, transferDate)
+	if err != nil {
+		t.Fatalf("get employee: %v", err)
+	}
+	if got.DepartmentID != root.ID {
+		t.Errorf("expected earlier transfer to survive, got department %d", got.DepartmentID)
+	}
+}
+
+func TestPurgeTruncatesHistory_DB(t *testing.T) {
+	db := openTestDB(t)
+	depts := repository.NewDepartmentRepository(db)
+	emps := repository.NewEmployeeRepository(db)
+	temporal := repository.NewTemporalRepository(db)
+	ctx := context.Background()
+
+	root := &domain.Department{Name: "Company"}
+	if err := depts.Create(ctx, root); err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	old := &domain.Department{Name: "Legacy", ParentID: &root.ID}
+	if err := depts.Create(ctx, old); err != nil {
+		t.Fatalf("create department: %v", err)
+	}
+	child := &domain.Department{Name: "Team", ParentID: &old.ID}
+	if err := depts.Create(ctx, child); err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	emp := &domain.Employee{DepartmentID: old.ID, FullName: "John", Position: "Dev"}
+	if err := emps.Create(ctx, emp); err != nil {
+		t.Fatalf("create employee: %v", err)
+	}
+
+	for _, table := range []string{"department_versions", "employee_assignments"} {
+		if err := db.Exec("UPDATE " + table + " SET valid_from = valid_from - 10").Error; err != nil {
+			t.Fatalf("shift %s: %v", table, err)
+		}
+	}
+
+	// Сотрудник и дочернее подразделение покидают Legacy до его удаления
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	emp.DepartmentID = root.ID
+	if err := emps.Transfer(ctx, emp, today.AddDate(0, 0, -5)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	child.ParentID = &root.ID
+	if err := depts.Update(ctx, child); err != nil {
+		t.Fatalf("move child: %v", err)
+	}
+	if err := depts.Delete(ctx, old.ID); err != nil {
+		t.Fatalf("delete department: %v", err)
+	}
+
+	purged, err := depts.Purge(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if len(purged) != 1 || purged[0].ID != old.ID {
+		t.Fatalf("expected Legacy to be purged, got %+v", purged)
+	}
+
+	past := today.AddDate(0, 0, -7)
+	if _, err := temporal.GetEmployee(ctx, emp.ID, past); err != domain.ErrEmployeeNotFound {
+		t.Errorf("expected assignment to the purged department to be gone, got %v", err)
+	}
+	if _, err := temporal.GetSubtree(ctx, child.ID, 1, false, past); err != domain.ErrDepartmentNotFound {
+		t.Errorf("expected version under the purged department to be gone, got %v", err)
+	}
+
+	current, err := temporal.GetEmployee(ctx, emp.ID, today)
+	if err != nil {
+		t.Fatalf("get employee: %v", err)
+	}
+	if current.DepartmentID != root.ID {
+		t.Errorf("expected current assignment to survive, got department %d", current.DepartmentID)
+	}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// TransferRepository определяет интерфейс для работы с историей переводов сотрудников
+type TransferRepository interface {
+	Create(ctx context.Context, transfer *domain.EmployeeTransfer) error
+	CreateForDepartment(ctx context.Context, fromDeptID, toDeptID int64, effectiveDate time.Time) error
+	GetByEmployeeID(ctx context.Context, employeeID int64) ([]domain.EmployeeTransfer, error)
+}
+
+type transferRepository struct {
+	db *gorm.DB
+}
+
+// NewTransferRepository создаёт новый экземпляр репозитория
+func NewTransferRepository(db *gorm.DB) TransferRepository {
+	return &transferRepository{db: db}
+}
+
+func (r *transferRepository) Create(ctx context.Context, transfer *domain.EmployeeTransfer) error {
+	return r.db.WithContext(ctx).Create(transfer).Error
+}
+
+// CreateForDepartment записывает перевод для каждого сотрудника подразделения fromDeptID.
+// Должен вызываться до фактического переназначения сотрудников
+func (r *transferRepository) CreateForDepartment(ctx context.Context, fromDeptID, toDeptID int64, effectiveDate time.Time) error {
+	query := `
+		INSERT INTO employee_transfers (employee_id, from_department_id, to_department_id, effective_date)
+		SELECT id, department_id, ?, ? FROM employees WHERE department_id = ? AND deleted_at IS NULL
+	`
+	return r.db.WithContext(ctx).Exec(query, toDeptID, effectiveDate, fromDeptID).Error
+}
+
+// GetByEmployeeID возвращает переводы сотрудника в хронологическом порядке
+func (r *transferRepository) GetByEmployeeID(ctx context.Context, employeeID int64) ([]domain.EmployeeTransfer, error) {
+	var transfers []domain.EmployeeTransfer
+	err := r.db.WithContext(ctx).
+		Where("employee_id = ?", employeeID).
+		Order("effective_date ASC, id ASC").
+		Find(&transfers).Error
+	return transfers, err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repositories - набор репозиториев, работающих через одно соединение или одну транзакцию
+type Repositories struct {
+	Departments DepartmentRepository
+	Employees   EmployeeRepository
+	Transfers   TransferRepository
+	Audit       AuditRepository
+	Drafts      DraftRepository
+	Delegations DelegationRepository
+	Positions   PositionRepository
+	Headcount   HeadcountRepository
+}
+
+// NewRepositories создаёт набор репозиториев, привязанных к db
+func NewRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Departments: NewDepartmentRepository(db),
+		Employees:   NewEmployeeRepository(db),
+		Transfers:   NewTransferRepository(db),
+		Audit:       NewAuditRepository(db),
+		Drafts:      NewDraftRepository(db),
+		Delegations: NewDelegationRepository(db),
+		Positions:   NewPositionRepository(db),
+		Headcount:   NewHeadcountRepository(db),
+	}
+}
+
+// TxManager определяет интерфейс для выполнения нескольких операций атомарно
+type TxManager interface {
+	// WithinTransaction выполняет fn в транзакции. Все вызовы через переданные
+	// репозитории идут в этой транзакции; если fn возвращает ошибку,
+	// транзакция откатывается, иначе фиксируется. При конфликте сериализации
+	// или взаимоблокировке транзакция повторяется целиком, поэтому fn
+	// не должна иметь побочных эффектов вне БД
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
+
+type gormTxManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager создаёт новый менеджер транзакций
+func NewTxManager(db *gorm.DB) TxManager {
+	return &gormTxManager{db: db}
+}
+
+// maxTxAttempts - сколько раз выполняется транзакция при конфликтах сериализации
+const maxTxAttempts = 5
+
+func (m *gormTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(ctx, NewRepositories(tx))
+		})
+		if !isRetryable(err) || attempt == maxTxAttempts {
+			return err
+		}
+
+		// Небольшая случайная пауза, чтобы конкурирующие транзакции разошлись
+		backoff := time.Duration(attempt)*10*time.Millisecond + rand.N(10*time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/repository"
+)
+
+// AuditService определяет интерфейс чтения журнала аудита
+type AuditService interface {
+	List(ctx context.Context, query *dto.ListAuditQuery) ([]domain.AuditEntry, string, error)
+}
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditService создаёт новый экземпляр сервиса
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditService{auditRepo: auditRepo}
+}
+
+// List возвращает страницу журнала аудита, начиная с новых записей,
+// и курсор следующей страницы (пустой, если страница последняя)
+func (s *auditService) List(ctx context.Context, query *dto.ListAuditQuery) ([]domain.AuditEntry, string, error) {
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	filter := repository.AuditFilter{
+		Entity:   query.Entity,
+		EntityID: query.EntityID,
+		After:    after,
+		// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+		Limit: query.Limit + 1,
+	}
+
+	if query.From != nil {
+		from, err := time.Parse(time.RFC3339, *query.From)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.From = &from
+	}
+
+	if query.To != nil {
+		to, err := time.Parse(time.RFC3339, *query.To)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.To = &to
+	}
+
+	entries, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(entries) <= query.Limit {
+		return entries, "", nil
+	}
+
+	entries = entries[:query.Limit]
+	last := &entries[len(entries)-1]
+	return entries, encodeCursor(repository.Cursor{ID: last.ID}), nil
+}
+
+// anonymousActor - пользователь журнала аудита для запросов без заголовка аутентификации
+const anonymousActor = "anonymous"
+
+// currentActor возвращает пользователя запроса или anonymousActor, если он не известен
+func currentActor(ctx context.Context) string {
+	if actor := domain.ActorFromContext(ctx); actor != "" {
+		return actor
+	}
+	return anonymousActor
+}
+
+// recordAudit записывает в журнал аудита изменение сущности в транзакции repos.
+// before и after сериализуются в JSON; nil означает отсутствие состояния
+// (до создания или после удаления)
+func recordAudit(ctx context.Context, repos repository.Repositories, entity string, entityID int64, operation string, before, after any) error {
+	entry := &domain.AuditEntry{
+		Actor:     currentActor(ctx),
+		Entity:    entity,
+		EntityID:  entityID,
+		Operation: operation,
+		RequestID: domain.RequestIDFromContext(ctx),
+	}
+
+	var err error
+	if entry.Before, err = marshalAuditState(before); err != nil {
+		return err
+	}
+	if entry.After, err = marshalAuditState(after); err != nil {
+		return err
+	}
+
+	return repos.Audit.Create(ctx, entry)
+}
+
+func marshalAuditState(state any) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	return json.Marshal(state)
+}
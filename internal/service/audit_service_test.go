@@ -0,0 +1,504 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func newAuditService(store *memStore) service.AuditService {
+	return service.NewAuditService(store.repositories().Audit)
+}
+
+func auditContext() context.Context {
+	ctx := domain.WithActor(context.Background(), "alice")
+	return domain.WithRequestID(ctx, "req-1")
+}
+
+func TestAudit_DepartmentMutations(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	svc, _ := newDepartmentService(store)
+	ctx := auditContext()
+
+	dept, err := svc.Create(ctx, &dto.CreateDepartmentRequest{Name: "Backend", ParentID: &root})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	newName := "Platform"
+	if _, err := svc.Update(ctx, dept.ID, &dto.UpdateDepartmentRequest{Name: &newName}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.Delete(ctx, dept.ID, &dto.DeleteDepartmentQuery{Mode: "cascade"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var operations []string
+	for _, entry := range store.audit {
+		if entry.Entity != domain.AuditEntityDepartment || entry.EntityID != dept.ID {
+			t.Errorf("unexpected audit target: %+v", entry)
+		}
+		if entry.Actor != "alice" || entry.RequestID != "req-1" {
+			t.Errorf("expected actor and request id from context, got %q/%q", entry.Actor, entry.RequestID)
+		}
+		operations = append(operations, entry.Operation)
+	}
+	want := []string{domain.AuditOperationCreate, domain.AuditOperationUpdate, domain.AuditOperationDelete}
+	if !slices.Equal(operations, want) {
+		t.Fatalf("expected operations %v, got %v", want, operations)
+	}
+
+	create, update, remove := store.audit[0], store.audit[1], store.audit[2]
+	if create.Before != nil || remove.After != nil {
+		t.Errorf("expected no state before create and after delete, got %s / %s", create.Before, remove.After)
+	}
+
+	var before, after domain.Department
+	if err := json.Unmarshal(update.Before, &before); err != nil {
+		t.Fatalf("invalid before state: %v", err)
+	}
+	if err := json.Unmarshal(update.After, &after); err != nil {
+		t.Fatalf("invalid after state: %v", err)
+	}
+	if before.Name != "Backend" || after.Name != "Platform" {
+		t.Errorf("expected rename Backend -> Platform, got %q -> %q", before.Name, after.Name)
+	}
+}
+
+func TestAudit_ReassignRecordsMovedChildren(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	toDelete := store.addDepartment("ToDelete", &root)
+	child := store.addDepartment("Child", &toDelete)
+
+	svc, _ := newDepartmentService(store)
+	err := svc.Delete(context.Background(), toDelete, &dto.DeleteDepartmentQuery{
+		Mode:                   "reassign",
+		ReassignToDepartmentID: &root,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.audit) != 2 {
+		t.Fatalf("expected 2 audit entries, got %+v", store.audit)
+	}
+	if e := store.audit[0]; e.EntityID != child || e.Operation != domain.AuditOperationUpdate {
+		t.Errorf("expected child move to be recorded first, got %+v", e)
+	}
+	if e := store.audit[1]; e.EntityID != toDelete || e.Operation != domain.AuditOperationReassign {
+		t.Errorf("expected reassign entry, got %+v", e)
+	}
+	if store.audit[0].Actor != "anonymous" || store.audit[0].RequestID != "" {
+		t.Errorf("expected anonymous actor without request id, got %+v", store.audit[0])
+	}
+}
+
+func TestAudit_CascadeRecordsEveryDeletedEntity(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	child := store.addDepartment("Child", &root)
+	grandchild := store.addDepartment("Grandchild", &child)
+	rootEmp := store.addEmployee(root, "Anna")
+	grandchildEmp := store.addEmployee(grandchild, "Bob")
+
+	svc, _ := newDepartmentService(store)
+	if err := svc.Delete(context.Background(), root, &dto.DeleteDepartmentQuery{Mode: "cascade"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type target struct {
+		entity string
+		id     int64
+	}
+	var got []target
+	for _, entry := range store.audit {
+		if entry.Operation != domain.AuditOperationDelete || entry.Before == nil || entry.After != nil {
+			t.Errorf("expected delete entry with state before, got %+v", entry)
+		}
+		got = append(got, target{entry.Entity, entry.EntityID})
+	}
+	for _, want := range []target{
+		{domain.AuditEntityDepartment, root},
+		{domain.AuditEntityDepartment, child},
+		{domain.AuditEntityDepartment, grandchild},
+		{domain.AuditEntityEmployee, rootEmp},
+		{domain.AuditEntityEmployee, grandchildEmp},
+	} {
+		if !slices.Contains(got, want) {
+			t.Errorf("expected audit entry for %+v, got %+v", want, got)
+		}
+	}
+	if len(got) != 5 {
+		t.Errorf("expected 5 audit entries, got %+v", got)
+	}
+}
+
+func TestAudit_RestoreRecordsEveryRestoredEntity(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	child := store.addDepartment("Child", &root)
+	rootEmp := store.addEmployee(root, "Anna")
+	childEmp := store.addEmployee(child, "Bob")
+
+	svc, _ := newDepartmentService(store)
+	if err := svc.Delete(context.Background(), root, &dto.DeleteDepartmentQuery{Mode: "cascade"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.audit = nil
+	if _, err := svc.Restore(context.Background(), root); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type target struct {
+		entity string
+		id     int64
+	}
+	var got []target
+	for _, entry := range store.audit {
+		if entry.Operation != domain.AuditOperationRestore || entry.Before == nil || entry.After == nil {
+			t.Errorf("expected restore entry with states before and after, got %+v", entry)
+		}
+		got = append(got, target{entry.Entity, entry.EntityID})
+	}
+	want := []target{
+		{domain.AuditEntityDepartment, root},
+		{domain.AuditEntityDepartment, child},
+		{domain.AuditEntityEmployee, rootEmp},
+		{domain.AuditEntityEmployee, childEmp},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected audit entries %+v, got %+v", want, got)
+	}
+}
+
+func TestAudit_PurgeRecordsEveryPurgedEntity(t *testing.T) {
+	store := newMemStore()
+	dept := store.addDepartment("Old", nil)
+	emp := store.addEmployee(dept, "Anna")
+	store.softDeleteEmployee(emp, time.Now().Add(-48*time.Hour))
+	store.softDeleteDepartment(dept, time.Now().Add(-48*time.Hour))
+
+	svc, _ := newTrashService(store)
+	if _, err := svc.Purge(auditContext(), 24*time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.audit) != 2 {
+		t.Fatalf("expected 2 audit entries, got %+v", store.audit)
+	}
+	for i, want := range []struct {
+		entity string
+		id     int64
+	}{
+		{domain.AuditEntityEmployee, emp},
+		{domain.AuditEntityDepartment, dept},
+	} {
+		entry := store.audit[i]
+		if entry.Entity != want.entity || entry.EntityID != want.id || entry.Operation != domain.AuditOperationPurge {
+			t.Errorf("expected purge entry for %s %d, got %+v", want.entity, want.id, entry)
+		}
+		if entry.Before == nil || entry.After != nil {
+			t.Errorf("expected purge entry with state before only, got %+v", entry)
+		}
+	}
+}
+
+func TestAudit_ReassignRecordsMovedEmployees(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	toDelete := store.addDepartment("ToDelete", &root)
+	emp := store.addEmployee(toDelete, "Anna")
+
+	svc, _ := newDepartmentService(store)
+	err := svc.Delete(context.Background(), toDelete, &dto.DeleteDepartmentQuery{
+		Mode:                   "reassign",
+		ReassignToDepartmentID: &root,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.audit) != 2 {
+		t.Fatalf("expected 2 audit entries, got %+v", store.audit)
+	}
+	entry := store.audit[0]
+	if entry.Entity != domain.AuditEntityEmployee || entry.EntityID != emp || entry.Operation != domain.AuditOperationTransfer {
+		t.Fatalf("expected employee transfer entry, got %+v", entry)
+	}
+	var before, after domain.Employee
+	if err := json.Unmarshal(entry.Before, &before); err != nil {
+		t.Fatalf("invalid before state: %v", err)
+	}
+	if err := json.Unmarshal(entry.After, &after); err != nil {
+		t.Fatalf("invalid after state: %v", err)
+	}
+	if before.DepartmentID != toDelete || after.DepartmentID != root {
+		t.Errorf("expected move %d -> %d, got %d -> %d", toDelete, root, before.DepartmentID, after.DepartmentID)
+	}
+}
+
+func TestAudit_EmployeeMutations(t *testing.T) {
+	store := newMemStore()
+	dept := store.addDepartment("Company", nil)
+	target := store.addDepartment("Target", nil)
+	addPosition(t, store, "Dev")
+	svc, _ := newEmployeeService(store)
+	ctx := auditContext()
+
+	emp, err := svc.Create(ctx, dept, &dto.CreateEmployeeRequest{FullName: "Ann", Position: "Dev"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Transfer(ctx, emp.ID, &dto.TransferEmployeeRequest{DepartmentID: target}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.Delete(ctx, emp.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Restore(ctx, emp.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var operations []string
+	for _, entry := range store.audit {
+		if entry.Entity != domain.AuditEntityEmployee || entry.EntityID != emp.ID {
+			t.Errorf("unexpected audit target: %+v", entry)
+		}
+		operations = append(operations, entry.Operation)
+	}
+	want := []string{
+		domain.AuditOperationCreate,
+		domain.AuditOperationTransfer,
+		domain.AuditOperationDelete,
+		domain.AuditOperationRestore,
+	}
+	if !slices.Equal(operations, want) {
+		t.Fatalf("expected operations %v, got %v", want, operations)
+	}
+
+	var after domain.Employee
+	if err := json.Unmarshal(store.audit[1].After, &after); err != nil {
+		t.Fatalf("invalid after state: %v", err)
+	}
+	if after.DepartmentID != target {
+		t.Errorf("expected transfer to %d in after state, got %d", target, after.DepartmentID)
+	}
+}
+
+func TestAudit_DelegationMutations(t *testing.T) {
+	store := newMemStore()
+	sales := store.addDepartment("Sales", nil)
+	head := store.addEmployee(sales, "Anna")
+	deputy := store.addEmployee(sales, "Bob")
+
+	deptSvc, _ := newDepartmentService(store)
+	ctx := auditContext()
+	if _, err := deptSvc.SetHead(ctx, sales, &dto.SetDepartmentHeadRequest{EmployeeID: head}); err != nil {
+		t.Fatalf("set head: %v", err)
+	}
+
+	svc := newDelegationService(store)
+	delegation, err := svc.Create(ctx, &dto.CreateDelegationRequest{
+		DepartmentID: sales, DelegatorID: head, DelegateID: deputy,
+		StartsOn: dateAfter(0), EndsOn: dateAfter(7),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Revoke(ctx, delegation.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var entries []domain.AuditEntry
+	for _, entry := range store.audit {
+		if entry.Entity == domain.AuditEntityDelegation {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) != 2 || entries[0].Operation != domain.AuditOperationCreate || entries[1].Operation != domain.AuditOperationRevoke {
+		t.Fatalf("expected create and revoke entries, got %+v", entries)
+	}
+	var before, after domain.Delegation
+	if err := json.Unmarshal(entries[1].Before, &before); err != nil {
+		t.Fatalf("invalid before state: %v", err)
+	}
+	if err := json.Unmarshal(entries[1].After, &after); err != nil {
+		t.Fatalf("invalid after state: %v", err)
+	}
+	if entries[1].EntityID != delegation.ID || before.RevokedAt != nil || after.RevokedBy == nil || *after.RevokedBy != "alice" {
+		t.Errorf("expected revocation by alice in after state, got %+v -> %+v", before, after)
+	}
+}
+
+func TestAudit_PositionMutations(t *testing.T) {
+	store := newMemStore()
+	it := store.addDepartment("IT", nil)
+	svc := newPositionService(store)
+	ctx := auditContext()
+
+	developer, err := svc.Create(ctx, &dto.CreatePositionRequest{Title: "Developer"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	empSvc, _ := newEmployeeService(store)
+	emp, err := empSvc.Create(ctx, it, &dto.CreateEmployeeRequest{FullName: "Anna", PositionID: &developer.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Update(ctx, developer.ID, &dto.UpdatePositionRequest{Title: ptr("Engineer")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unused, err := svc.Create(ctx, &dto.CreatePositionRequest{Title: "Analyst"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.Delete(ctx, unused.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type target struct {
+		entity    string
+		id        int64
+		operation string
+	}
+	var got []target
+	for _, entry := range store.audit {
+		got = append(got, target{entry.Entity, entry.EntityID, entry.Operation})
+	}
+	want := []target{
+		{domain.AuditEntityPosition, developer.ID, domain.AuditOperationCreate},
+		{domain.AuditEntityEmployee, emp.ID, domain.AuditOperationCreate},
+		{domain.AuditEntityPosition, developer.ID, domain.AuditOperationUpdate},
+		{domain.AuditEntityEmployee, emp.ID, domain.AuditOperationUpdate},
+		{domain.AuditEntityPosition, unused.ID, domain.AuditOperationCreate},
+		{domain.AuditEntityPosition, unused.ID, domain.AuditOperationDelete},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected entries %v, got %v", want, got)
+	}
+
+	// Переименование должности записывается и как изменение поля position сотрудника
+	var before, after domain.Employee
+	if err := json.Unmarshal(store.audit[3].Before, &before); err != nil {
+		t.Fatalf("invalid before state: %v", err)
+	}
+	if err := json.Unmarshal(store.audit[3].After, &after); err != nil {
+		t.Fatalf("invalid after state: %v", err)
+	}
+	if before.Position != "Developer" || after.Position != "Engineer" {
+		t.Errorf("expected position Developer -> Engineer, got %q -> %q", before.Position, after.Position)
+	}
+}
+
+func TestAudit_HeadcountMutations(t *testing.T) {
+	store := newMemStore()
+	it := store.addDepartment("IT", nil)
+	developer := addPosition(t, store, "Developer")
+	svc := newHeadcountService(store)
+	ctx := auditContext()
+
+	for _, approved := range []int{2, 3} {
+		if _, err := svc.SetSlot(ctx, it, developer, &dto.SetHeadcountSlotRequest{Approved: ptr(approved)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := svc.RemoveSlot(ctx, it, developer); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var operations []string
+	for _, entry := range store.audit {
+		if entry.Entity != domain.AuditEntityHeadcount {
+			t.Errorf("unexpected audit target: %+v", entry)
+		}
+		operations = append(operations, entry.Operation)
+	}
+	want := []string{domain.AuditOperationCreate, domain.AuditOperationUpdate, domain.AuditOperationDelete}
+	if !slices.Equal(operations, want) {
+		t.Fatalf("expected operations %v, got %v", want, operations)
+	}
+
+	var before, after domain.HeadcountSlot
+	if err := json.Unmarshal(store.audit[1].Before, &before); err != nil {
+		t.Fatalf("invalid before state: %v", err)
+	}
+	if err := json.Unmarshal(store.audit[1].After, &after); err != nil {
+		t.Fatalf("invalid after state: %v", err)
+	}
+	if before.Approved != 2 || after.Approved != 3 {
+		t.Errorf("expected approved 2 -> 3, got %d -> %d", before.Approved, after.Approved)
+	}
+}
+
+func TestAudit_FailureRollsBackMutation(t *testing.T) {
+	store := newMemStore()
+	dept := store.addDepartment("Company", nil)
+	addPosition(t, store, "Dev")
+	store.failures["Audit.Create"] = errInjected
+
+	deptSvc, deptTx := newDepartmentService(store)
+	if _, err := deptSvc.Create(context.Background(), &dto.CreateDepartmentRequest{Name: "Backend"}); !errors.Is(err, errInjected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if len(store.departments) != 1 || deptTx.rollbacks != 1 {
+		t.Errorf("expected department creation to be rolled back")
+	}
+
+	empSvc, _ := newEmployeeService(store)
+	if _, err := empSvc.Create(context.Background(), dept, &dto.CreateEmployeeRequest{FullName: "Ann", Position: "Dev"}); !errors.Is(err, errInjected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if len(store.employees) != 0 {
+		t.Errorf("expected employee creation to be rolled back")
+	}
+}
+
+func TestAuditList_FiltersAndPaginates(t *testing.T) {
+	store := newMemStore()
+	deptSvc, _ := newDepartmentService(store)
+	ctx := auditContext()
+
+	for _, name := range []string{"A", "B", "C"} {
+		dept, err := deptSvc.Create(ctx, &dto.CreateDepartmentRequest{Name: name})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		newName := name + "2"
+		if _, err := deptSvc.Update(ctx, dept.ID, &dto.UpdateDepartmentRequest{Name: &newName}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Подразделению B (ID 2) соответствуют записи 3 (создание) и 4 (переименование)
+	want := []int64{4, 3}
+	svc := newAuditService(store)
+	entityID := int64(2)
+	query := &dto.ListAuditQuery{Limit: 1, Entity: domain.AuditEntityDepartment, EntityID: &entityID}
+
+	var got []int64
+	for {
+		page, next, err := svc.List(context.Background(), query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, entry := range page {
+			got = append(got, entry.ID)
+		}
+		if next == "" {
+			break
+		}
+		query.Cursor = next
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("expected entries %v newest first, got %v", want, got)
+	}
+}
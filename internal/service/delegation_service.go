@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/repository"
+)
+
+// DelegationService определяет интерфейс работы с делегированием полномочий руководителей
+type DelegationService interface {
+	Create(ctx context.Context, req *dto.CreateDelegationRequest) (*domain.Delegation, error)
+	GetByID(ctx context.Context, id int64) (*domain.Delegation, error)
+	List(ctx context.Context, query *dto.ListDelegationsQuery) ([]domain.Delegation, string, error)
+	Revoke(ctx context.Context, id int64) (*domain.Delegation, error)
+}
+
+type delegationService struct {
+	delegationRepo repository.DelegationRepository
+	txManager      repository.TxManager
+}
+
+// NewDelegationService создаёт новый экземпляр сервиса
+func NewDelegationService(delegationRepo repository.DelegationRepository, txManager repository.TxManager) DelegationService {
+	return &delegationService{
+		delegationRepo: delegationRepo,
+		txManager:      txManager,
+	}
+}
+
+// Create передаёт полномочия руководителя подразделения заместителю на период.
+// Делегировать может только текущий руководитель; периоды делегирований одного
+// подразделения не пересекаются
+func (s *delegationService) Create(ctx context.Context, req *dto.CreateDelegationRequest) (*domain.Delegation, error) {
+	startsOn, err := time.Parse("2006-01-02", req.StartsOn)
+	if err != nil {
+		return nil, err
+	}
+	endsOn, err := time.Parse("2006-01-02", req.EndsOn)
+	if err != nil {
+		return nil, err
+	}
+	if endsOn.Before(startsOn) || endsOn.Before(today()) {
+		return nil, domain.ErrInvalidDelegationPeriod
+	}
+
+	delegation := &domain.Delegation{
+		DepartmentID: req.DepartmentID,
+		DelegatorID:  req.DelegatorID,
+		DelegateID:   req.DelegateID,
+		StartsOn:     startsOn,
+		EndsOn:       endsOn,
+		CreatedBy:    currentActor(ctx),
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		// Блокировка подразделения упорядочивает создание делегирований и смену руководителя
+		dept, err := repos.Departments.GetByIDForUpdate(ctx, req.DepartmentID)
+		if err != nil {
+			return err
+		}
+		if dept.HeadEmployeeID == nil || *dept.HeadEmployeeID != req.DelegatorID {
+			return domain.ErrDelegatorNotHead
+		}
+
+		if _, err := repos.Employees.GetByID(ctx, req.DelegateID); err != nil {
+			return err
+		}
+
+		overlap, err := repos.Delegations.HasOverlap(ctx, req.DepartmentID, req.DelegatorID, startsOn, endsOn)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return domain.ErrDelegationOverlap
+		}
+
+		if err := repos.Delegations.Create(ctx, delegation); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, domain.AuditEntityDelegation, delegation.ID, domain.AuditOperationCreate, nil, *delegation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delegation, nil
+}
+
+func (s *delegationService) GetByID(ctx context.Context, id int64) (*domain.Delegation, error) {
+	return s.delegationRepo.GetByID(ctx, id)
+}
+
+// List возвращает страницу делегирований, начиная с новых, и курсор следующей страницы
+func (s *delegationService) List(ctx context.Context, query *dto.ListDelegationsQuery) ([]domain.Delegation, string, error) {
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	activeOn, err := parseAsOf(query.ActiveOn)
+	if err != nil {
+		return nil, "", err
+	}
+
+	delegations, err := s.delegationRepo.List(ctx, repository.DelegationFilter{
+		DepartmentID: query.DepartmentID,
+		EmployeeID:   query.EmployeeID,
+		ActiveOn:     activeOn,
+		After:        after,
+		// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+		Limit: query.Limit + 1,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(delegations) <= query.Limit {
+		return delegations, "", nil
+	}
+
+	delegations = delegations[:query.Limit]
+	last := &delegations[len(delegations)-1]
+	return delegations, encodeCursor(repository.Cursor{ID: last.ID}), nil
+}
+
+// Revoke отзывает делегирование; полномочия сразу возвращаются руководителю
+func (s *delegationService) Revoke(ctx context.Context, id int64) (*domain.Delegation, error) {
+	var delegation *domain.Delegation
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		delegation, err = repos.Delegations.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if delegation.RevokedAt != nil {
+			return domain.ErrDelegationRevoked
+		}
+
+		before := *delegation
+		if err := repos.Delegations.Revoke(ctx, delegation, currentActor(ctx)); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, domain.AuditEntityDelegation, id, domain.AuditOperationRevoke, before, *delegation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delegation, nil
+}
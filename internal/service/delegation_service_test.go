@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func newDelegationService(store *memStore) service.DelegationService {
+	return service.NewDelegationService(store.repositories().Delegations, &memTxManager{store: store})
+}
+
+// dateAfter возвращает дату через days дней от сегодняшней в формате запроса
+func dateAfter(days int) string {
+	return time.Now().UTC().AddDate(0, 0, days).Format("2006-01-02")
+}
+
+func TestCreateDelegation_Validates(t *testing.T) {
+	store := newMemStore()
+	sales := store.addDepartment("Sales", nil)
+	head := store.addEmployee(sales, "Anna")
+	deputy := store.addEmployee(sales, "Bob")
+
+	deptSvc, _ := newDepartmentService(store)
+	ctx := domain.WithActor(context.Background(), "alice")
+	if _, err := deptSvc.SetHead(ctx, sales, &dto.SetDepartmentHeadRequest{EmployeeID: head}); err != nil {
+		t.Fatalf("set head: %v", err)
+	}
+
+	svc := newDelegationService(store)
+	request := func(delegator, delegate int64, from, to int) *dto.CreateDelegationRequest {
+		return &dto.CreateDelegationRequest{
+			DepartmentID: sales, DelegatorID: delegator, DelegateID: delegate,
+			StartsOn: dateAfter(from), EndsOn: dateAfter(to),
+		}
+	}
+
+	tests := []struct {
+		name string
+		req  *dto.CreateDelegationRequest
+		want error
+	}{
+		{"not the head", request(deputy, head, 0, 7), domain.ErrDelegatorNotHead},
+		{"unknown delegate", request(head, 99, 0, 7), domain.ErrEmployeeNotFound},
+		{"ends before start", request(head, deputy, 7, 0), domain.ErrInvalidDelegationPeriod},
+		{"ends in the past", request(head, deputy, -7, -1), domain.ErrInvalidDelegationPeriod},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Create(ctx, tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	delegation, err := svc.Create(ctx, request(head, deputy, 0, 7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Create(ctx, request(head, deputy, 7, 14)); !errors.Is(err, domain.ErrDelegationOverlap) {
+		t.Fatalf("expected overlap, got %v", err)
+	}
+
+	// Отозванное делегирование не мешает новому на тот же период
+	revoked, err := svc.Revoke(ctx, delegation.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revoked.RevokedAt == nil || *revoked.RevokedBy != "alice" {
+		t.Errorf("expected revocation to be recorded, got %+v", revoked)
+	}
+	if _, err := svc.Revoke(ctx, delegation.ID); !errors.Is(err, domain.ErrDelegationRevoked) {
+		t.Errorf("expected already revoked, got %v", err)
+	}
+	if _, err := svc.Create(ctx, request(head, deputy, 7, 14)); err != nil {
+		t.Errorf("unexpected error after revocation: %v", err)
+	}
+
+	active, _, err := svc.List(ctx, &dto.ListDelegationsQuery{Limit: 10, ActiveOn: ptr(dateAfter(0))})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(active) != 0 {
+		t.Errorf("expected no delegation active today, got %+v", active)
+	}
+}
+
+func TestCreateDelegation_IgnoresFormerHead(t *testing.T) {
+	store := newMemStore()
+	sales := store.addDepartment("Sales", nil)
+	formerHead := store.addEmployee(sales, "Anna")
+	newHead := store.addEmployee(sales, "Bob")
+	deputy := store.addEmployee(sales, "Carl")
+
+	deptSvc, _ := newDepartmentService(store)
+	ctx := domain.WithActor(context.Background(), "alice")
+	svc := newDelegationService(store)
+
+	if _, err := deptSvc.SetHead(ctx, sales, &dto.SetDepartmentHeadRequest{EmployeeID: formerHead}); err != nil {
+		t.Fatalf("set head: %v", err)
+	}
+	if _, err := svc.Create(ctx, &dto.CreateDelegationRequest{
+		DepartmentID: sales, DelegatorID: formerHead, DelegateID: deputy,
+		StartsOn: dateAfter(0), EndsOn: dateAfter(7),
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Делегирование прежнего руководителя не действует и не мешает новому
+	if _, err := deptSvc.SetHead(ctx, sales, &dto.SetDepartmentHeadRequest{EmployeeID: newHead}); err != nil {
+		t.Fatalf("set head: %v", err)
+	}
+	if _, err := svc.Create(ctx, &dto.CreateDelegationRequest{
+		DepartmentID: sales, DelegatorID: newHead, DelegateID: deputy,
+		StartsOn: dateAfter(0), EndsOn: dateAfter(7),
+	}); err != nil {
+		t.Errorf("expected stale delegation to be ignored, got %v", err)
+	}
+}
@@ -2,47 +2,105 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/org-structure-api/internal/domain"
 	"github.com/org-structure-api/internal/dto"
 	"github.com/org-structure-api/internal/repository"
+	"gorm.io/gorm"
 )
 
+// maxDepartmentNameLength - максимальная длина имени подразделения (см. схему БД)
+const maxDepartmentNameLength = 200
+
+// maxCloneSize - максимальное число записей (подразделений и мест численности),
+// копируемых одним запросом
+const maxCloneSize = 1000
+
 // DepartmentService определяет интерфейс бизнес-логики для подразделений
 type DepartmentService interface {
 	Create(ctx context.Context, req *dto.CreateDepartmentRequest) (*domain.Department, error)
 	GetByID(ctx context.Context, id int64, query *dto.GetDepartmentQuery) (*domain.Department, error)
 	Update(ctx context.Context, id int64, req *dto.UpdateDepartmentRequest) (*domain.Department, error)
 	Delete(ctx context.Context, id int64, query *dto.DeleteDepartmentQuery) error
+	// Merge переносит сотрудников и детей подразделения в целевое и удаляет исходное
+	Merge(ctx context.Context, id int64, req *dto.MergeDepartmentRequest) (*domain.DepartmentImpact, error)
+	// Clone копирует поддерево подразделения и возвращает копию с поддеревом
+	Clone(ctx context.Context, id int64, req *dto.CloneDepartmentRequest) (*domain.Department, error)
+	// SetHead назначает руководителя подразделения, RemoveHead снимает его
+	SetHead(ctx context.Context, id int64, req *dto.SetDepartmentHeadRequest) (*domain.Department, error)
+	RemoveHead(ctx context.Context, id int64) (*domain.Department, error)
+	// CreateDryRun, UpdateDryRun и DeleteDryRun выполняют те же проверки, что и изменения,
+	// и возвращают их последствия, не сохраняя изменений
+	CreateDryRun(ctx context.Context, req *dto.CreateDepartmentRequest) (*domain.DepartmentImpact, error)
+	UpdateDryRun(ctx context.Context, id int64, req *dto.UpdateDepartmentRequest) (*domain.DepartmentImpact, error)
+	DeleteDryRun(ctx context.Context, id int64, query *dto.DeleteDepartmentQuery) (*domain.DepartmentImpact, error)
+	MergeDryRun(ctx context.Context, id int64, req *dto.MergeDepartmentRequest) (*domain.DepartmentImpact, error)
+	GetAncestors(ctx context.Context, id int64, query *dto.AncestorsQuery) ([]domain.Department, error)
+	ListRoots(ctx context.Context, query *dto.ListDepartmentsQuery) ([]domain.Department, string, error)
+	GetOrgTree(ctx context.Context, query *dto.OrgTreeQuery) ([]domain.Department, error)
+	Restore(ctx context.Context, id int64) (*domain.Department, error)
 }
 
 type departmentService struct {
-	deptRepo repository.DepartmentRepository
-	empRepo  repository.EmployeeRepository
+	deptRepo     repository.DepartmentRepository
+	empRepo      repository.EmployeeRepository
+	temporalRepo repository.TemporalRepository
+	txManager    repository.TxManager
+	// policy - правила для сотрудников, которых переводят изменения структуры
+	policy EmployeePolicy
 }
 
 // NewDepartmentService создаёт новый экземпляр сервиса
-func NewDepartmentService(deptRepo repository.DepartmentRepository, empRepo repository.EmployeeRepository) DepartmentService {
+func NewDepartmentService(
+	deptRepo repository.DepartmentRepository,
+	empRepo repository.EmployeeRepository,
+	temporalRepo repository.TemporalRepository,
+	txManager repository.TxManager,
+	policy EmployeePolicy,
+) DepartmentService {
 	return &departmentService{
-		deptRepo: deptRepo,
-		empRepo:  empRepo,
+		deptRepo:     deptRepo,
+		empRepo:      empRepo,
+		temporalRepo: temporalRepo,
+		txManager:    txManager,
+		policy:       policy,
 	}
 }
 
 func (s *departmentService) Create(ctx context.Context, req *dto.CreateDepartmentRequest) (*domain.Department, error) {
+	var dept *domain.Department
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		dept, err = s.create(ctx, repos, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dept, nil
+}
+
+func (s *departmentService) create(ctx context.Context, repos repository.Repositories, req *dto.CreateDepartmentRequest) (*domain.Department, error) {
 	name := strings.TrimSpace(req.Name)
 
 	// Проверяем существование родительского подразделения
 	if req.ParentID != nil {
-		_, err := s.deptRepo.GetByID(ctx, *req.ParentID)
+		_, err := repos.Departments.GetByID(ctx, *req.ParentID)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	// Проверяем уникальность имени в пределах родителя
-	exists, err := s.deptRepo.ExistsByNameAndParent(ctx, name, req.ParentID, nil)
+	if err := repos.Departments.LockName(ctx, name, req.ParentID); err != nil {
+		return nil, err
+	}
+	exists, err := repos.Departments.ExistsByNameAndParent(ctx, name, req.ParentID, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -55,22 +113,83 @@ func (s *departmentService) Create(ctx context.Context, req *dto.CreateDepartmen
 		ParentID: req.ParentID,
 	}
 
-	if err := s.deptRepo.Create(ctx, dept); err != nil {
+	if err := repos.Departments.Create(ctx, dept); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, repos, domain.AuditEntityDepartment, dept.ID, domain.AuditOperationCreate, nil, *dept); err != nil {
 		return nil, err
 	}
 
 	return dept, nil
 }
 
+// GetByID возвращает подразделение с поддеревом в текущем состоянии или,
+// если задан as_of, в состоянии на эту дату
 func (s *departmentService) GetByID(ctx context.Context, id int64, query *dto.GetDepartmentQuery) (*domain.Department, error) {
+	asOf, err := parseAsOf(query.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	if asOf != nil {
+		return s.temporalRepo.GetSubtree(ctx, id, query.Depth, query.IncludeEmployees, *asOf)
+	}
 	return s.deptRepo.GetByIDWithChildren(ctx, id, query.Depth, query.IncludeEmployees)
 }
 
+// GetAncestors возвращает цепочку подразделений от корня до id включительно
+func (s *departmentService) GetAncestors(ctx context.Context, id int64, query *dto.AncestorsQuery) ([]domain.Department, error) {
+	asOf, err := parseAsOf(query.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	if asOf != nil {
+		return s.temporalRepo.GetAncestors(ctx, id, *asOf)
+	}
+	return s.deptRepo.GetAncestors(ctx, id)
+}
+
 func (s *departmentService) Update(ctx context.Context, id int64, req *dto.UpdateDepartmentRequest) (*domain.Department, error) {
-	dept, err := s.deptRepo.GetByID(ctx, id)
+	var dept *domain.Department
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		dept, err = s.update(ctx, repos, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dept, nil
+}
+
+func (s *departmentService) update(ctx context.Context, repos repository.Repositories, id int64, req *dto.UpdateDepartmentRequest) (*domain.Department, error) {
+	// Блокируем подразделение и цепочку предков нового родителя, чтобы
+	// параллельные перемещения не создали цикл, каждое пройдя проверку по отдельности
+	if req.ParentID != nil && *req.ParentID != id {
+		if err := repos.Departments.LockForMove(ctx, id, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	dept, err := repos.Departments.GetByIDForUpdate(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := *dept
+
+	// Блокируем итоговую пару (родитель, имя) до проверки уникальности
+	if req.Name != nil || req.ParentID != nil {
+		name, parentID := dept.Name, dept.ParentID
+		if req.Name != nil {
+			name = strings.TrimSpace(*req.Name)
+		}
+		if req.ParentID != nil {
+			parentID = req.ParentID
+		}
+		if err := repos.Departments.LockName(ctx, name, parentID); err != nil {
+			return nil, err
+		}
+	}
 
 	// Обновляем имя, если передано
 	if req.Name != nil {
@@ -83,7 +202,7 @@ func (s *departmentService) Update(ctx context.Context, id int64, req *dto.Updat
 		}
 
 		// Проверяем уникальность нового имени
-		exists, err := s.deptRepo.ExistsByNameAndParent(ctx, name, parentID, &id)
+		exists, err := repos.Departments.ExistsByNameAndParent(ctx, name, parentID, &id)
 		if err != nil {
 			return nil, err
 		}
@@ -104,13 +223,13 @@ func (s *departmentService) Update(ctx context.Context, id int64, req *dto.Updat
 		}
 
 		// Проверяем существование нового родителя
-		_, err := s.deptRepo.GetByID(ctx, newParentID)
+		_, err := repos.Departments.GetByID(ctx, newParentID)
 		if err != nil {
 			return nil, err
 		}
 
 		// Проверка на циклическую ссылку: нельзя переместить в своего потомка
-		isDescendant, err := s.deptRepo.IsDescendant(ctx, id, newParentID)
+		isDescendant, err := repos.Departments.IsDescendant(ctx, id, newParentID)
 		if err != nil {
 			return nil, err
 		}
@@ -120,7 +239,7 @@ func (s *departmentService) Update(ctx context.Context, id int64, req *dto.Updat
 
 		// Если новое имя не было передано, проверяем уникальность текущего имени в новом родителе
 		if req.Name == nil {
-			exists, err := s.deptRepo.ExistsByNameAndParent(ctx, dept.Name, &newParentID, &id)
+			exists, err := repos.Departments.ExistsByNameAndParent(ctx, dept.Name, &newParentID, &id)
 			if err != nil {
 				return nil, err
 			}
@@ -132,7 +251,11 @@ func (s *departmentService) Update(ctx context.Context, id int64, req *dto.Updat
 		dept.ParentID = &newParentID
 	}
 
-	if err := s.deptRepo.Update(ctx, dept); err != nil {
+	if err := repos.Departments.Update(ctx, dept); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, repos, domain.AuditEntityDepartment, id, domain.AuditOperationUpdate, before, *dept); err != nil {
 		return nil, err
 	}
 
@@ -140,15 +263,61 @@ func (s *departmentService) Update(ctx context.Context, id int64, req *dto.Updat
 }
 
 func (s *departmentService) Delete(ctx context.Context, id int64, query *dto.DeleteDepartmentQuery) error {
-	// Проверяем существование подразделения
-	_, err := s.deptRepo.GetByID(ctx, id)
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		return s.delete(ctx, repos, id, query)
+	})
+}
+
+func (s *departmentService) delete(ctx context.Context, repos repository.Repositories, id int64, query *dto.DeleteDepartmentQuery) error {
+	// Проверяем существование подразделения и блокируем его до конца транзакции
+	dept, err := repos.Departments.GetByIDForUpdate(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	switch query.Mode {
 	case "cascade":
-		return s.deptRepo.DeleteCascade(ctx, id)
+		// Состояние поддерева читаем до удаления, чтобы записать в журнал каждую
+		// удаляемую запись, а не только корень
+		descendantIDs, err := repos.Departments.GetAllDescendantIDs(ctx, id)
+		if err != nil {
+			return err
+		}
+		descendants := make([]domain.Department, 0, len(descendantIDs))
+		for _, descendantID := range descendantIDs {
+			descendant, err := repos.Departments.GetByID(ctx, descendantID)
+			if err != nil {
+				return err
+			}
+			descendants = append(descendants, *descendant)
+		}
+		var employees []domain.Employee
+		for _, deptID := range append([]int64{id}, descendantIDs...) {
+			deptEmployees, err := repos.Employees.GetByDepartmentID(ctx, deptID)
+			if err != nil {
+				return err
+			}
+			employees = append(employees, deptEmployees...)
+		}
+
+		if err := repos.Departments.DeleteCascade(ctx, id); err != nil {
+			return err
+		}
+
+		if err := recordAudit(ctx, repos, domain.AuditEntityDepartment, id, domain.AuditOperationDelete, *dept, nil); err != nil {
+			return err
+		}
+		for _, descendant := range descendants {
+			if err := recordAudit(ctx, repos, domain.AuditEntityDepartment, descendant.ID, domain.AuditOperationDelete, descendant, nil); err != nil {
+				return err
+			}
+		}
+		for _, emp := range employees {
+			if err := recordAudit(ctx, repos, domain.AuditEntityEmployee, emp.ID, domain.AuditOperationDelete, emp, nil); err != nil {
+				return err
+			}
+		}
+		return nil
 
 	case "reassign":
 		if query.ReassignToDepartmentID == nil {
@@ -163,7 +332,7 @@ func (s *departmentService) Delete(ctx context.Context, id int64, query *dto.Del
 		}
 
 		// Проверяем существование целевого подразделения
-		_, err := s.deptRepo.GetByID(ctx, targetID)
+		_, err := repos.Departments.GetByID(ctx, targetID)
 		if err != nil {
 			if err == domain.ErrDepartmentNotFound {
 				return domain.ErrReassignTargetNotFound
@@ -171,33 +340,607 @@ func (s *departmentService) Delete(ctx context.Context, id int64, query *dto.Del
 			return err
 		}
 
-		// Переназначаем сотрудников
-		if err := s.empRepo.ReassignToDepartment(ctx, id, targetID); err != nil {
+		// Переводим только собственных сотрудников удаляемого подразделения
+		if err := reassignEmployees(ctx, repos, id, targetID, s.policy.StrictHeadcount); err != nil {
 			return err
 		}
 
-		// Переназначаем дочерние подразделения к родителю удаляемого
-		// или устанавливаем parent_id = NULL, если родителя нет
-		dept, _ := s.deptRepo.GetByID(ctx, id)
-		descendants, err := s.deptRepo.GetAllDescendantIDs(ctx, id)
+		// Дети обрабатываются в порядке возрастания ID, поэтому разрешение
+		// конфликтов имён детерминировано
+		children, err := repos.Departments.GetChildren(ctx, id)
 		if err != nil {
 			return err
 		}
 
-		// Переназначаем сотрудников из всех дочерних подразделений
-		for _, descID := range descendants {
-			if err := s.empRepo.ReassignToDepartment(ctx, descID, targetID); err != nil {
-				return err
-			}
+		// Временно отвязываем детей, чтобы удаление не затронуло их поддеревья
+		if err := repos.Departments.DetachChildren(ctx, id); err != nil {
+			return err
 		}
 
-		// Обновляем parent_id прямых детей на parent текущего подразделения
-		_ = dept // Используем parent_id удаляемого подразделения для детей
+		if err := repos.Departments.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		// Поднимаем детей к родителю удаляемого подразделения (или в корень)
+		if err := reparentDepartments(ctx, repos, children, dept.ParentID); err != nil {
+			return err
+		}
 
-		// Удаляем подразделение (каскадно удалятся дети из-за FK constraint)
-		return s.deptRepo.DeleteCascade(ctx, id)
+		return recordAudit(ctx, repos, domain.AuditEntityDepartment, id, domain.AuditOperationReassign, *dept, nil)
 
 	default:
 		return domain.ErrInvalidDeleteMode
 	}
 }
+
+// reassignEmployees переводит собственных сотрудников подразделения fromID в toID
+// с текущей даты, сохраняя переводы в истории и журнале аудита. При strictHeadcount
+// в toID должно хватать вакансий на всех переводимых
+func reassignEmployees(ctx context.Context, repos repository.Repositories, fromID, toID int64, strictHeadcount bool) error {
+	employees, err := repos.Employees.GetByDepartmentID(ctx, fromID)
+	if err != nil {
+		return err
+	}
+
+	if strictHeadcount {
+		if _, err := repos.Departments.GetByIDForUpdate(ctx, toID); err != nil {
+			return err
+		}
+		positionIDs := make([]*int64, 0, len(employees))
+		for i := range employees {
+			positionIDs = append(positionIDs, employees[i].PositionID)
+		}
+		if err := checkHeadcount(ctx, repos, toID, positionIDs...); err != nil {
+			return err
+		}
+	}
+
+	effectiveDate := today()
+	if err := repos.Transfers.CreateForDepartment(ctx, fromID, toID, effectiveDate); err != nil {
+		return err
+	}
+	if err := repos.Employees.ReassignToDepartment(ctx, fromID, toID, effectiveDate); err != nil {
+		return err
+	}
+
+	for _, before := range employees {
+		after := before
+		after.DepartmentID = toID
+		if err := recordAudit(ctx, repos, domain.AuditEntityEmployee, before.ID, domain.AuditOperationTransfer, before, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reparentDepartments переносит подразделения под parentID вместе с поддеревьями.
+// При совпадении имени с подразделением нового родителя к имени добавляется суффикс;
+// departments обновляются на месте
+func reparentDepartments(ctx context.Context, repos repository.Repositories, departments []domain.Department, parentID *int64) error {
+	for i := range departments {
+		dept := &departments[i]
+		before := *dept
+
+		name, err := uniqueDepartmentName(ctx, repos.Departments, dept.Name, parentID, dept.ID)
+		if err != nil {
+			return err
+		}
+
+		dept.Name = name
+		dept.ParentID = parentID
+		if err := repos.Departments.Update(ctx, dept); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, repos, domain.AuditEntityDepartment, dept.ID, domain.AuditOperationUpdate, before, *dept); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge переносит сотрудников и дочерние подразделения в целевое подразделение
+// и удаляет исходное
+func (s *departmentService) Merge(ctx context.Context, id int64, req *dto.MergeDepartmentRequest) (*domain.DepartmentImpact, error) {
+	var impact *domain.DepartmentImpact
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		impact, err = s.merge(ctx, repos, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return impact, nil
+}
+
+func (s *departmentService) MergeDryRun(ctx context.Context, id int64, req *dto.MergeDepartmentRequest) (*domain.DepartmentImpact, error) {
+	return s.dryRun(ctx, func(ctx context.Context, repos repository.Repositories) (*domain.DepartmentImpact, error) {
+		return s.merge(ctx, repos, id, req)
+	})
+}
+
+func (s *departmentService) merge(ctx context.Context, repos repository.Repositories, id int64, req *dto.MergeDepartmentRequest) (*domain.DepartmentImpact, error) {
+	targetID := req.TargetDepartmentID
+	if targetID == id {
+		return nil, domain.ErrCannotMergeIntoSelf
+	}
+
+	// Дети источника переезжают под цель, поэтому блокируем источник и цепочку
+	// предков цели, как при перемещении
+	if err := repos.Departments.LockForMove(ctx, id, targetID); err != nil {
+		return nil, err
+	}
+
+	source, err := repos.Departments.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := repos.Departments.GetByID(ctx, targetID)
+	if err != nil {
+		if errors.Is(err, domain.ErrDepartmentNotFound) {
+			return nil, domain.ErrMergeTargetNotFound
+		}
+		return nil, err
+	}
+
+	// Цель внутри поддерева источника стала бы потомком собственных предков
+	isDescendant, err := repos.Departments.IsDescendant(ctx, id, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if isDescendant {
+		return nil, domain.ErrCyclicReference
+	}
+
+	children, err := repos.Departments.GetChildren(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Проверяем все имена до изменений, чтобы сообщить обо всех конфликтах сразу
+	var conflicts []string
+	for _, child := range children {
+		if err := repos.Departments.LockName(ctx, child.Name, &targetID); err != nil {
+			return nil, err
+		}
+		exists, err := repos.Departments.ExistsByNameAndParent(ctx, child.Name, &targetID, &child.ID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			conflicts = append(conflicts, strconv.Quote(child.Name))
+		}
+	}
+	if len(conflicts) > 0 && req.OnConflict != "suffix" {
+		return nil, fmt.Errorf("%w: %s", domain.ErrMergeNameConflict, strings.Join(conflicts, ", "))
+	}
+
+	employees, err := repos.Employees.CountByDepartmentIDs(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	if err := reassignEmployees(ctx, repos, id, targetID, s.policy.StrictHeadcount); err != nil {
+		return nil, err
+	}
+
+	// Как при удалении с переназначением: отвязываем детей, удаляем источник
+	// и переносим детей под цель
+	if err := repos.Departments.DetachChildren(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := repos.Departments.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := reparentDepartments(ctx, repos, children, &targetID); err != nil {
+		return nil, err
+	}
+
+	mergedInto := struct {
+		MergedInto int64 `json:"merged_into"`
+	}{targetID}
+	if err := recordAudit(ctx, repos, domain.AuditEntityDepartment, id, domain.AuditOperationMerge, *source, mergedInto); err != nil {
+		return nil, err
+	}
+
+	impact := &domain.DepartmentImpact{
+		Department:             target,
+		AffectedDepartmentIDs:  []int64{id, targetID},
+		DeletedDepartmentIDs:   []int64{id},
+		ReparentedDepartments:  children,
+		ReassignToDepartmentID: &targetID,
+		ReassignedEmployees:    employees,
+	}
+	for _, child := range children {
+		impact.AffectedDepartmentIDs = append(impact.AffectedDepartmentIDs, child.ID)
+	}
+	return impact, nil
+}
+
+func (s *departmentService) Clone(ctx context.Context, id int64, req *dto.CloneDepartmentRequest) (*domain.Department, error) {
+	var dept *domain.Department
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		dept, err = s.clone(ctx, repos, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dept, nil
+}
+
+func (s *departmentService) clone(ctx context.Context, repos repository.Repositories, id int64, req *dto.CloneDepartmentRequest) (*domain.Department, error) {
+	source, err := repos.Departments.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants, err := repos.Departments.GetAllDescendantIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subtree := append([]int64{id}, descendants...)
+	// Копируются только штатные места, а не сами сотрудники: копия сотрудника
+	// была бы вторым человеком с теми же данными
+	var slots []domain.HeadcountSlot
+	if req.IncludeHeadcount {
+		if slots, err = repos.Headcount.List(ctx, subtree); err != nil {
+			return nil, err
+		}
+	}
+	size := len(subtree) + len(slots)
+	if size > maxCloneSize {
+		return nil, fmt.Errorf("%w: %d records, limit is %d", domain.ErrCloneTooLarge, size, maxCloneSize)
+	}
+
+	name := strings.TrimSpace(req.NamePrefix + source.Name + req.NameSuffix)
+	if utf8.RuneCountInString(name) > maxDepartmentNameLength {
+		return nil, domain.ErrDepartmentNameTooLong
+	}
+
+	// Запоминаем детей до копирования: копия может оказаться внутри исходного
+	// поддерева и не должна копироваться сама
+	children := make(map[int64][]domain.Department, len(subtree))
+	for _, deptID := range subtree {
+		if children[deptID], err = repos.Departments.GetChildren(ctx, deptID); err != nil {
+			return nil, err
+		}
+	}
+	slotsByDept := make(map[int64][]domain.HeadcountSlot)
+	for _, slot := range slots {
+		slotsByDept[slot.DepartmentID] = append(slotsByDept[slot.DepartmentID], slot)
+	}
+
+	return s.cloneTree(ctx, repos, source.ID, name, req.ParentID, children, slotsByDept)
+}
+
+// cloneTree создаёт под parentID копию подразделения sourceID с именем name
+// и его мест численности из slots, затем рекурсивно копирует его детей
+func (s *departmentService) cloneTree(
+	ctx context.Context,
+	repos repository.Repositories,
+	sourceID int64,
+	name string,
+	parentID *int64,
+	children map[int64][]domain.Department,
+	slots map[int64][]domain.HeadcountSlot,
+) (*domain.Department, error) {
+	dept, err := s.create(ctx, repos, &dto.CreateDepartmentRequest{Name: name, ParentID: parentID})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, source := range slots[sourceID] {
+		slot := domain.HeadcountSlot{
+			DepartmentID: dept.ID,
+			PositionID:   source.PositionID,
+			Approved:     source.Approved,
+		}
+		if err := repos.Headcount.Save(ctx, &slot); err != nil {
+			return nil, err
+		}
+		if err := recordAudit(ctx, repos, domain.AuditEntityHeadcount, slot.ID, domain.AuditOperationCreate, nil, slot); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, child := range children[sourceID] {
+		clone, err := s.cloneTree(ctx, repos, child.ID, child.Name, &dept.ID, children, slots)
+		if err != nil {
+			return nil, err
+		}
+		dept.Children = append(dept.Children, *clone)
+	}
+	return dept, nil
+}
+
+func (s *departmentService) SetHead(ctx context.Context, id int64, req *dto.SetDepartmentHeadRequest) (*domain.Department, error) {
+	return s.setHead(ctx, id, &req.EmployeeID)
+}
+
+func (s *departmentService) RemoveHead(ctx context.Context, id int64) (*domain.Department, error) {
+	return s.setHead(ctx, id, nil)
+}
+
+// setHead назначает руководителем сотрудника employeeID (из любого подразделения)
+// или снимает руководителя, если employeeID = nil
+func (s *departmentService) setHead(ctx context.Context, id int64, employeeID *int64) (*domain.Department, error) {
+	var dept *domain.Department
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		dept, err = repos.Departments.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if employeeID != nil {
+			// Блокируем сотрудника, чтобы его не удалили до фиксации назначения
+			emp, err := repos.Employees.GetWithDeletedForUpdate(ctx, *employeeID)
+			if err != nil {
+				return err
+			}
+			if emp.DeletedAt.Valid {
+				return domain.ErrEmployeeNotFound
+			}
+		}
+
+		before := *dept
+		dept.HeadEmployeeID = employeeID
+		if err := repos.Departments.Update(ctx, dept); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, domain.AuditEntityDepartment, id, domain.AuditOperationUpdate, before, *dept)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dept, nil
+}
+
+// errRollback откатывает транзакцию, изменения которой нужны только для чтения результата
+var errRollback = errors.New("rollback")
+
+// dryRun выполняет fn в транзакции, которая затем откатывается
+func (s *departmentService) dryRun(ctx context.Context, fn func(ctx context.Context, repos repository.Repositories) (*domain.DepartmentImpact, error)) (*domain.DepartmentImpact, error) {
+	var impact *domain.DepartmentImpact
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		if impact, err = fn(ctx, repos); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		return nil, err
+	}
+	return impact, nil
+}
+
+func (s *departmentService) CreateDryRun(ctx context.Context, req *dto.CreateDepartmentRequest) (*domain.DepartmentImpact, error) {
+	return s.dryRun(ctx, func(ctx context.Context, repos repository.Repositories) (*domain.DepartmentImpact, error) {
+		dept, err := s.create(ctx, repos, req)
+		if err != nil {
+			return nil, err
+		}
+		dept.ID = 0
+		return &domain.DepartmentImpact{Department: dept}, nil
+	})
+}
+
+func (s *departmentService) UpdateDryRun(ctx context.Context, id int64, req *dto.UpdateDepartmentRequest) (*domain.DepartmentImpact, error) {
+	return s.dryRun(ctx, func(ctx context.Context, repos repository.Repositories) (*domain.DepartmentImpact, error) {
+		current, err := repos.Departments.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		moved := req.ParentID != nil && !domain.SameParent(current.ParentID, req.ParentID)
+
+		dept, err := s.update(ctx, repos, id, req)
+		if err != nil {
+			return nil, err
+		}
+
+		impact := &domain.DepartmentImpact{Department: dept, AffectedDepartmentIDs: []int64{id}}
+		if moved {
+			// Поддерево перемещается вместе с подразделением
+			descendants, err := repos.Departments.GetAllDescendantIDs(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			impact.AffectedDepartmentIDs = append(impact.AffectedDepartmentIDs, descendants...)
+			if impact.MovedEmployees, err = repos.Employees.CountByDepartmentIDs(ctx, impact.AffectedDepartmentIDs); err != nil {
+				return nil, err
+			}
+		}
+		return impact, nil
+	})
+}
+
+func (s *departmentService) DeleteDryRun(ctx context.Context, id int64, query *dto.DeleteDepartmentQuery) (*domain.DepartmentImpact, error) {
+	return s.dryRun(ctx, func(ctx context.Context, repos repository.Repositories) (*domain.DepartmentImpact, error) {
+		// Состав поддерева, детей и сотрудников запоминаем до удаления
+		descendants, err := repos.Departments.GetAllDescendantIDs(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		subtree := append([]int64{id}, descendants...)
+		children, err := repos.Departments.GetChildren(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		subtreeEmployees, err := repos.Employees.CountByDepartmentIDs(ctx, subtree)
+		if err != nil {
+			return nil, err
+		}
+		ownEmployees, err := repos.Employees.CountByDepartmentIDs(ctx, []int64{id})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.delete(ctx, repos, id, query); err != nil {
+			return nil, err
+		}
+
+		if query.Mode == "cascade" {
+			return &domain.DepartmentImpact{
+				AffectedDepartmentIDs: subtree,
+				DeletedDepartmentIDs:  subtree,
+				DeletedEmployees:      subtreeEmployees,
+			}, nil
+		}
+
+		impact := &domain.DepartmentImpact{
+			AffectedDepartmentIDs:  []int64{id},
+			DeletedDepartmentIDs:   []int64{id},
+			ReassignToDepartmentID: query.ReassignToDepartmentID,
+			ReassignedEmployees:    ownEmployees,
+		}
+		for _, child := range children {
+			// Дети уже подняты к родителю удалённого подразделения и, возможно, переименованы
+			reparented, err := repos.Departments.GetByID(ctx, child.ID)
+			if err != nil {
+				return nil, err
+			}
+			impact.AffectedDepartmentIDs = append(impact.AffectedDepartmentIDs, child.ID)
+			impact.ReparentedDepartments = append(impact.ReparentedDepartments, *reparented)
+		}
+		return impact, nil
+	})
+}
+
+// uniqueDepartmentName подбирает свободное имя в пределах родителя,
+// добавляя к исходному суффикс " (2)", " (3)" и т.д.
+func uniqueDepartmentName(ctx context.Context, deptRepo repository.DepartmentRepository, name string, parentID *int64, excludeID int64) (string, error) {
+	candidate := name
+	for n := 2; ; n++ {
+		exists, err := deptRepo.ExistsByNameAndParent(ctx, candidate, parentID, &excludeID)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+
+		suffix := fmt.Sprintf(" (%d)", n)
+		base := name
+		for utf8.RuneCountInString(base)+utf8.RuneCountInString(suffix) > maxDepartmentNameLength {
+			_, size := utf8.DecodeLastRuneInString(base)
+			base = base[:len(base)-size]
+		}
+		candidate = base + suffix
+	}
+}
+
+// Restore восстанавливает удалённое подразделение вместе с поддеревом и сотрудниками,
+// удалёнными той же операцией
+func (s *departmentService) Restore(ctx context.Context, id int64) (*domain.Department, error) {
+	var dept *domain.Department
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		dept, err = s.restore(ctx, repos, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dept, nil
+}
+
+func (s *departmentService) restore(ctx context.Context, repos repository.Repositories, id int64) (*domain.Department, error) {
+	dept, err := repos.Departments.GetWithDeletedForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !dept.DeletedAt.Valid {
+		return nil, domain.ErrDepartmentNotDeleted
+	}
+
+	// Родитель должен существовать, иначе подразделение окажется в корзине снова
+	// вместе с ним; блокируем его, чтобы он не был удалён параллельно
+	if dept.ParentID != nil {
+		if _, err := repos.Departments.GetByIDForUpdate(ctx, *dept.ParentID); err != nil {
+			if errors.Is(err, domain.ErrDepartmentNotFound) {
+				return nil, domain.ErrParentDeleted
+			}
+			return nil, err
+		}
+	}
+
+	// Пока подразделение было в корзине, его имя могли занять
+	if err := repos.Departments.LockName(ctx, dept.Name, dept.ParentID); err != nil {
+		return nil, err
+	}
+	exists, err := repos.Departments.ExistsByNameAndParent(ctx, dept.Name, dept.ParentID, &dept.ID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, domain.ErrDuplicateDepartmentName
+	}
+
+	departments, employees, err := repos.Departments.Restore(ctx, dept.ID, dept.DeletedAt.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	// Восстановление записывается для каждого подразделения поддерева и каждого сотрудника,
+	// удалённых вместе с подразделением
+	for _, before := range departments {
+		after := before
+		after.DeletedAt = gorm.DeletedAt{}
+		if err := recordAudit(ctx, repos, domain.AuditEntityDepartment, before.ID, domain.AuditOperationRestore, before, after); err != nil {
+			return nil, err
+		}
+	}
+	for _, before := range employees {
+		after := before
+		after.DeletedAt = gorm.DeletedAt{}
+		if err := recordAudit(ctx, repos, domain.AuditEntityEmployee, before.ID, domain.AuditOperationRestore, before, after); err != nil {
+			return nil, err
+		}
+	}
+
+	dept.DeletedAt = gorm.DeletedAt{}
+	return dept, nil
+}
+
+// ListRoots возвращает страницу корневых подразделений и курсор следующей страницы
+// (пустой, если страница последняя)
+func (s *departmentService) ListRoots(ctx context.Context, query *dto.ListDepartmentsQuery) ([]domain.Department, string, error) {
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	departments, err := s.deptRepo.ListRoots(ctx, repository.DepartmentFilter{
+		Name:  strings.TrimSpace(query.Name),
+		After: after,
+		// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+		Limit: query.Limit + 1,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(departments) <= query.Limit {
+		return departments, "", nil
+	}
+
+	departments = departments[:query.Limit]
+	last := &departments[len(departments)-1]
+	next := encodeCursor(repository.Cursor{Value: last.Name, ID: last.ID})
+
+	return departments, next, nil
+}
+
+// GetOrgTree возвращает все корневые подразделения с поддеревьями заданной глубины
+func (s *departmentService) GetOrgTree(ctx context.Context, query *dto.OrgTreeQuery) ([]domain.Department, error) {
+	asOf, err := parseAsOf(query.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	if asOf != nil {
+		return s.temporalRepo.GetForest(ctx, query.Depth, query.IncludeEmployees, *asOf)
+	}
+	return s.deptRepo.GetForest(ctx, query.Depth, query.IncludeEmployees)
+}
@@ -0,0 +1,644 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func newDepartmentService(store *memStore) (service.DepartmentService, *memTxManager) {
+	txManager := &memTxManager{store: store}
+	repos := store.repositories()
+	return service.NewDepartmentService(repos.Departments, repos.Employees, &memTemporalRepo{store: store}, txManager, service.EmployeePolicy{}), txManager
+}
+
+func TestDeleteReassign_ReparentsChildren(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	target := store.addDepartment("Target", &root)
+	toDelete := store.addDepartment("ToDelete", &root)
+	child := store.addDepartment("Child", &toDelete)
+	grandChild := store.addDepartment("GrandChild", &child)
+	ownEmp := store.addEmployee(toDelete, "Own")
+	childEmp := store.addEmployee(child, "Child member")
+
+	svc, _ := newDepartmentService(store)
+	err := svc.Delete(context.Background(), toDelete, &dto.DeleteDepartmentQuery{
+		Mode:                   "reassign",
+		ReassignToDepartmentID: &target,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := store.departments[toDelete]; ok {
+		t.Errorf("department %d should be deleted", toDelete)
+	}
+	if got := store.departments[child].ParentID; got == nil || *got != root {
+		t.Errorf("expected child to be moved under %d, got %v", root, got)
+	}
+	if got := store.departments[grandChild].ParentID; got == nil || *got != child {
+		t.Errorf("expected grandchild subtree to stay intact, got parent %v", got)
+	}
+	if got := store.employees[ownEmp].DepartmentID; got != target {
+		t.Errorf("expected own employee in %d, got %d", target, got)
+	}
+	if got := store.employees[childEmp].DepartmentID; got != child {
+		t.Errorf("expected child employee to stay in %d, got %d", child, got)
+	}
+	if len(store.transfers) != 1 || store.transfers[0].EmployeeID != ownEmp {
+		t.Errorf("expected a single transfer record for employee %d, got %+v", ownEmp, store.transfers)
+	}
+}
+
+func TestDeleteReassign_ResolvesNameCollisions(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	store.addDepartment("Sales", &root)
+	store.addDepartment("Sales (2)", &root)
+	toDelete := store.addDepartment("Regions", &root)
+	first := store.addDepartment("Sales", &toDelete)
+	second := store.addDepartment("Regions", &toDelete)
+
+	svc, _ := newDepartmentService(store)
+	err := svc.Delete(context.Background(), toDelete, &dto.DeleteDepartmentQuery{
+		Mode:                   "reassign",
+		ReassignToDepartmentID: &root,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := store.departments[first].Name; got != "Sales (3)" {
+		t.Errorf("expected 'Sales (3)', got '%s'", got)
+	}
+	if got := store.departments[second].Name; got != "Regions" {
+		t.Errorf("expected 'Regions' to be kept after parent removal, got '%s'", got)
+	}
+}
+
+func TestDeleteReassign_ToRoot(t *testing.T) {
+	store := newMemStore()
+	toDelete := store.addDepartment("Company", nil)
+	target := store.addDepartment("Other", nil)
+	child := store.addDepartment("Other", &toDelete)
+
+	svc, _ := newDepartmentService(store)
+	err := svc.Delete(context.Background(), toDelete, &dto.DeleteDepartmentQuery{
+		Mode:                   "reassign",
+		ReassignToDepartmentID: &target,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dept := store.departments[child]
+	if dept.ParentID != nil {
+		t.Errorf("expected child to become a root department, got parent %d", *dept.ParentID)
+	}
+	if dept.Name != "Other (2)" {
+		t.Errorf("expected 'Other (2)', got '%s'", dept.Name)
+	}
+}
+
+func TestDeleteReassign_RollbackOnFailure(t *testing.T) {
+	failures := []string{
+		"Departments.Delete",
+		"Departments.Update",
+	}
+
+	for _, method := range failures {
+		t.Run(method, func(t *testing.T) {
+			store := newMemStore()
+			root := store.addDepartment("Company", nil)
+			target := store.addDepartment("Target", &root)
+			toDelete := store.addDepartment("ToDelete", &root)
+			child := store.addDepartment("Child", &toDelete)
+			emp := store.addEmployee(toDelete, "Own")
+			store.failures[method] = errInjected
+
+			svc, txManager := newDepartmentService(store)
+			err := svc.Delete(context.Background(), toDelete, &dto.DeleteDepartmentQuery{
+				Mode:                   "reassign",
+				ReassignToDepartmentID: &target,
+			})
+			if !errors.Is(err, errInjected) {
+				t.Fatalf("expected injected error, got %v", err)
+			}
+			if txManager.rollbacks != 1 || txManager.commits != 0 {
+				t.Errorf("expected 1 rollback and 0 commits, got %d and %d", txManager.rollbacks, txManager.commits)
+			}
+
+			if _, ok := store.departments[toDelete]; !ok {
+				t.Errorf("department %d should still exist", toDelete)
+			}
+			if got := store.departments[child].ParentID; got == nil || *got != toDelete {
+				t.Errorf("expected child to stay under %d, got %v", toDelete, got)
+			}
+			if got := store.employees[emp].DepartmentID; got != toDelete {
+				t.Errorf("expected employee to stay in %d, got %d", toDelete, got)
+			}
+		})
+	}
+}
+
+func TestUpdate_RollbackOnFailure(t *testing.T) {
+	store := newMemStore()
+	parent := store.addDepartment("Parent", nil)
+	dept := store.addDepartment("Dept", nil)
+	store.failures["Departments.Update"] = errInjected
+
+	svc, txManager := newDepartmentService(store)
+	_, err := svc.Update(context.Background(), dept, &dto.UpdateDepartmentRequest{
+		Name:     ptr("Renamed"),
+		ParentID: &parent,
+	})
+	if !errors.Is(err, errInjected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if txManager.rollbacks != 1 {
+		t.Errorf("expected 1 rollback, got %d", txManager.rollbacks)
+	}
+
+	got := store.departments[dept]
+	if got.Name != "Dept" || got.ParentID != nil {
+		t.Errorf("expected department to stay unchanged, got name '%s' and parent %v", got.Name, got.ParentID)
+	}
+}
+
+func TestCreate_RollbackOnFailure(t *testing.T) {
+	store := newMemStore()
+	store.failures["Departments.Create"] = errInjected
+
+	svc, _ := newDepartmentService(store)
+	_, err := svc.Create(context.Background(), &dto.CreateDepartmentRequest{Name: "IT"})
+	if !errors.Is(err, errInjected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if len(store.departments) != 0 {
+		t.Errorf("expected no departments, got %d", len(store.departments))
+	}
+}
+
+func TestUpdate_LocksAffectedChainAndName(t *testing.T) {
+	store := newMemStore()
+	parent := store.addDepartment("Parent", nil)
+	dept := store.addDepartment("Dept", nil)
+
+	svc, _ := newDepartmentService(store)
+
+	if _, err := svc.Update(context.Background(), dept, &dto.UpdateDepartmentRequest{Name: ptr("Renamed")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.moveLocks) != 0 {
+		t.Errorf("expected rename not to lock the ancestor chain, got %v", store.moveLocks)
+	}
+	if want := []string{"root:Renamed"}; !slices.Equal(store.nameLocks, want) {
+		t.Errorf("expected name locks %v, got %v", want, store.nameLocks)
+	}
+
+	if _, err := svc.Update(context.Background(), dept, &dto.UpdateDepartmentRequest{ParentID: &parent}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := [][2]int64{{dept, parent}}; !slices.Equal(store.moveLocks, want) {
+		t.Errorf("expected move locks %v, got %v", want, store.moveLocks)
+	}
+	if want := []string{"root:Renamed", "1:Renamed"}; !slices.Equal(store.nameLocks, want) {
+		t.Errorf("expected name locks %v, got %v", want, store.nameLocks)
+	}
+}
+
+func TestListRoots_PaginatesByName(t *testing.T) {
+	store := newMemStore()
+	beta := store.addDepartment("Beta", nil)
+	alpha := store.addDepartment("Alpha", nil)
+	store.addDepartment("Child", &alpha)
+	gamma := store.addDepartment("Gamma", nil)
+
+	svc, _ := newDepartmentService(store)
+
+	var got []int64
+	query := &dto.ListDepartmentsQuery{Limit: 2}
+	for {
+		page, next, err := svc.ListRoots(context.Background(), query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, dept := range page {
+			got = append(got, dept.ID)
+		}
+		if next == "" {
+			break
+		}
+		query.Cursor = next
+	}
+
+	if want := []int64{alpha, beta, gamma}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDeleteCascade_RestoreSubtree(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	dept := store.addDepartment("IT", &root)
+	child := store.addDepartment("Backend", &dept)
+	trashedEarlier := store.addDepartment("Legacy", &dept)
+	emp := store.addEmployee(child, "Alice")
+	firedEarlier := store.addEmployee(dept, "Bob")
+
+	earlier := time.Now().Add(-time.Hour)
+	store.softDeleteDepartment(trashedEarlier, earlier)
+	store.softDeleteEmployee(firedEarlier, earlier)
+
+	svc, _ := newDepartmentService(store)
+	if err := svc.Delete(context.Background(), dept, &dto.DeleteDepartmentQuery{Mode: "cascade"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := store.departments[child]; ok {
+		t.Fatalf("expected child department %d to be trashed", child)
+	}
+	if _, ok := store.employees[emp]; ok {
+		t.Fatalf("expected employee %d to be trashed", emp)
+	}
+
+	restored, err := svc.Restore(context.Background(), dept)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored.DeletedAt.Valid {
+		t.Error("expected restored department to have no deleted_at")
+	}
+
+	for _, id := range []int64{dept, child} {
+		if _, ok := store.departments[id]; !ok {
+			t.Errorf("expected department %d to be restored", id)
+		}
+	}
+	if _, ok := store.employees[emp]; !ok {
+		t.Errorf("expected employee %d to be restored", emp)
+	}
+	if _, ok := store.deletedDepartments[trashedEarlier]; !ok {
+		t.Errorf("expected department %d deleted earlier to stay in trash", trashedEarlier)
+	}
+	if _, ok := store.deletedEmployees[firedEarlier]; !ok {
+		t.Errorf("expected employee %d deleted earlier to stay in trash", firedEarlier)
+	}
+}
+
+func TestRestore_Conflicts(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	deletedParent := store.addDepartment("Old", &root)
+	orphan := store.addDepartment("Orphan", &deletedParent)
+	renamed := store.addDepartment("Sales", &root)
+	live := store.addDepartment("Live", &root)
+
+	svc, _ := newDepartmentService(store)
+	ctx := context.Background()
+
+	if err := svc.Delete(ctx, orphan, &dto.DeleteDepartmentQuery{Mode: "cascade"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.Delete(ctx, deletedParent, &dto.DeleteDepartmentQuery{Mode: "cascade"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.Delete(ctx, renamed, &dto.DeleteDepartmentQuery{Mode: "cascade"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.addDepartment("Sales", &root)
+
+	cases := []struct {
+		name string
+		id   int64
+		want error
+	}{
+		{"parent in trash", orphan, domain.ErrParentDeleted},
+		{"name taken", renamed, domain.ErrDuplicateDepartmentName},
+		{"not deleted", live, domain.ErrDepartmentNotDeleted},
+		{"unknown", 999, domain.ErrDepartmentNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.Restore(ctx, tc.id); !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestGetByID_AsOf(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	moved := store.addDepartment("Platform", nil)
+
+	jan2024 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan2025 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.addVersion(root, "Company", nil, jan2024, nil)
+	store.addVersion(moved, "Backend", &root, jan2024, &jan2025)
+	store.addVersion(moved, "Platform", nil, jan2025, nil)
+
+	svc, _ := newDepartmentService(store)
+	ctx := context.Background()
+
+	past, err := svc.GetByID(ctx, root, &dto.GetDepartmentQuery{Depth: 2, AsOf: ptr("2024-06-01")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(past.Children) != 1 || past.Children[0].ID != moved || past.Children[0].Name != "Backend" {
+		t.Errorf("expected Backend under Company in 2024, got %+v", past.Children)
+	}
+
+	forest, err := svc.GetOrgTree(ctx, &dto.OrgTreeQuery{Depth: 5, AsOf: ptr("2025-01-01")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(forest) != 2 || forest[1].Name != "Platform" || forest[1].ParentID != nil {
+		t.Errorf("expected Platform to be a root since 2025-01-01, got %+v", forest)
+	}
+
+	path, err := svc.GetAncestors(ctx, moved, &dto.AncestorsQuery{AsOf: ptr("2024-12-31")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(path) != 2 || path[0].ID != root {
+		t.Errorf("expected path Company > Backend, got %+v", path)
+	}
+
+	if _, err := svc.GetByID(ctx, root, &dto.GetDepartmentQuery{Depth: 1, AsOf: ptr("2023-12-31")}); !errors.Is(err, domain.ErrDepartmentNotFound) {
+		t.Errorf("expected department not found before it existed, got %v", err)
+	}
+
+	// Без as_of читается текущее состояние, а не история
+	current, err := svc.GetByID(ctx, moved, &dto.GetDepartmentQuery{Depth: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current.Name != "Platform" {
+		t.Errorf("expected current state, got %+v", current)
+	}
+}
+
+func TestDeleteDryRun_ReportsImpactWithoutChanges(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	target := store.addDepartment("Target", &root)
+	toDelete := store.addDepartment("ToDelete", &root)
+	child := store.addDepartment("Target", &toDelete)
+	grandChild := store.addDepartment("GrandChild", &child)
+	store.addEmployee(toDelete, "Own")
+	store.addEmployee(grandChild, "Deep")
+
+	svc, txManager := newDepartmentService(store)
+	ctx := context.Background()
+
+	cascade, err := svc.DeleteDryRun(ctx, toDelete, &dto.DeleteDepartmentQuery{Mode: "cascade"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(cascade.DeletedDepartmentIDs, []int64{toDelete, child, grandChild}) || cascade.DeletedEmployees != 2 {
+		t.Errorf("unexpected cascade impact: %+v", cascade)
+	}
+
+	reassign, err := svc.DeleteDryRun(ctx, toDelete, &dto.DeleteDepartmentQuery{Mode: "reassign", ReassignToDepartmentID: &target})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(reassign.DeletedDepartmentIDs, []int64{toDelete}) || reassign.ReassignedEmployees != 1 || *reassign.ReassignToDepartmentID != target {
+		t.Errorf("unexpected reassign impact: %+v", reassign)
+	}
+	// Ребёнок поднимается к корню и получает свободное имя
+	if len(reassign.ReparentedDepartments) != 1 || reassign.ReparentedDepartments[0].Name != "Target (2)" || *reassign.ReparentedDepartments[0].ParentID != root {
+		t.Errorf("unexpected reparented departments: %+v", reassign.ReparentedDepartments)
+	}
+
+	if len(store.departments) != 5 || len(store.employees) != 2 || len(store.audit) != 0 || txManager.commits != 0 {
+		t.Errorf("dry run must not change anything, got %d departments, %d audit entries, %d commits", len(store.departments), len(store.audit), txManager.commits)
+	}
+
+	if _, err := svc.DeleteDryRun(ctx, toDelete, &dto.DeleteDepartmentQuery{Mode: "reassign", ReassignToDepartmentID: &toDelete}); !errors.Is(err, domain.ErrCannotReassignToSelf) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
+
+func TestUpdateDryRun_MoveReportsSubtree(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	other := store.addDepartment("Other", &root)
+	dept := store.addDepartment("IT", &root)
+	child := store.addDepartment("Backend", &dept)
+	store.addEmployee(child, "John")
+
+	svc, _ := newDepartmentService(store)
+	ctx := context.Background()
+
+	impact, err := svc.UpdateDryRun(ctx, dept, &dto.UpdateDepartmentRequest{ParentID: &other})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(impact.AffectedDepartmentIDs, []int64{dept, child}) || impact.MovedEmployees != 1 || *impact.Department.ParentID != other {
+		t.Errorf("unexpected impact: %+v", impact)
+	}
+	if parent := store.departments[dept].ParentID; *parent != root {
+		t.Errorf("dry run must not move the department, parent is %d", *parent)
+	}
+
+	if _, err := svc.UpdateDryRun(ctx, dept, &dto.UpdateDepartmentRequest{ParentID: &child}); !errors.Is(err, domain.ErrCyclicReference) {
+		t.Errorf("expected cyclic reference, got %v", err)
+	}
+
+	created, err := svc.CreateDryRun(ctx, &dto.CreateDepartmentRequest{Name: " HR ", ParentID: &root})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Department.ID != 0 || created.Department.Name != "HR" || len(store.departments) != 4 {
+		t.Errorf("unexpected create impact: %+v", created.Department)
+	}
+}
+
+func TestMerge_MovesEmployeesAndChildren(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	source := store.addDepartment("Team A", &root)
+	target := store.addDepartment("Team B", &root)
+	sameName := store.addDepartment("QA", &source)
+	unique := store.addDepartment("Docs", &source)
+	store.addDepartment("QA", &target)
+	emp := store.addEmployee(source, "John")
+
+	svc, _ := newDepartmentService(store)
+	ctx := context.Background()
+
+	_, err := svc.Merge(ctx, source, &dto.MergeDepartmentRequest{TargetDepartmentID: target})
+	if !errors.Is(err, domain.ErrMergeNameConflict) || !strings.Contains(err.Error(), `"QA"`) {
+		t.Fatalf("expected name conflict on QA, got %v", err)
+	}
+	if _, ok := store.departments[source]; !ok || store.employees[emp].DepartmentID != source {
+		t.Fatal("failed merge must not change anything")
+	}
+
+	impact, err := svc.Merge(ctx, source, &dto.MergeDepartmentRequest{TargetDepartmentID: target, OnConflict: "suffix"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if impact.ReassignedEmployees != 1 || len(impact.ReparentedDepartments) != 2 || impact.Department.ID != target {
+		t.Errorf("unexpected impact: %+v", impact)
+	}
+
+	if _, ok := store.deletedDepartments[source]; !ok {
+		t.Error("expected source department in trash")
+	}
+	if got := store.employees[emp].DepartmentID; got != target {
+		t.Errorf("expected John in target, got %d", got)
+	}
+	for id, name := range map[int64]string{sameName: "QA (2)", unique: "Docs"} {
+		if dept := store.departments[id]; dept.Name != name || *dept.ParentID != target {
+			t.Errorf("expected %q under target, got %+v", name, dept)
+		}
+	}
+	if last := store.audit[len(store.audit)-1]; last.Operation != domain.AuditOperationMerge || last.EntityID != source || !strings.Contains(string(last.After), `"merged_into":3`) {
+		t.Errorf("unexpected audit entry: %+v", last)
+	}
+}
+
+func TestMerge_RejectsTargetInsideSource(t *testing.T) {
+	store := newMemStore()
+	source := store.addDepartment("Company", nil)
+	child := store.addDepartment("IT", &source)
+
+	svc, _ := newDepartmentService(store)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		id     int64
+		target int64
+		want   error
+	}{
+		{"into descendant", source, child, domain.ErrCyclicReference},
+		{"into itself", source, source, domain.ErrCannotMergeIntoSelf},
+		{"missing target", source, 99, domain.ErrMergeTargetNotFound},
+		{"missing source", 99, source, domain.ErrDepartmentNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Merge(ctx, tt.id, &dto.MergeDepartmentRequest{TargetDepartmentID: tt.target}); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestClone_CopiesSubtree(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	office := store.addDepartment("Office", &root)
+	sales := store.addDepartment("Sales", &office)
+	store.addDepartment("Support", &sales)
+	store.addEmployee(sales, "John")
+	developer := addPosition(t, store, "Developer")
+
+	svc, _ := newDepartmentService(store)
+	ctx := context.Background()
+	if _, err := newHeadcountService(store).SetSlot(ctx, sales, developer, &dto.SetHeadcountSlotRequest{Approved: ptr(2)}); err != nil {
+		t.Fatalf("set slot: %v", err)
+	}
+
+	// Без префикса и суффикса имя копии совпадает с исходным в том же родителе
+	if _, err := svc.Clone(ctx, office, &dto.CloneDepartmentRequest{ParentID: &root}); !errors.Is(err, domain.ErrDuplicateDepartmentName) {
+		t.Fatalf("expected duplicate name, got %v", err)
+	}
+
+	structure, err := svc.Clone(ctx, office, &dto.CloneDepartmentRequest{ParentID: &root, NameSuffix: " Berlin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if structure.Name != "Office Berlin" || len(structure.Children) != 1 || structure.Children[0].Name != "Sales" || len(structure.Children[0].Children) != 1 {
+		t.Fatalf("unexpected clone: %+v", structure)
+	}
+	if len(store.headcount) != 1 {
+		t.Errorf("structure-only clone must not copy headcount, got %d slots", len(store.headcount))
+	}
+
+	// Копия внутри исходного поддерева не копирует сама себя; места копируются,
+	// а сотрудники - нет
+	nested, err := svc.Clone(ctx, office, &dto.CloneDepartmentRequest{ParentID: &sales, NamePrefix: "Copy of ", IncludeHeadcount: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nested.Name != "Copy of Office" || len(nested.Children) != 1 || len(nested.Children[0].Employees) != 0 {
+		t.Fatalf("unexpected nested clone: %+v", nested)
+	}
+	if len(store.departments) != 10 || len(store.employees) != 1 {
+		t.Errorf("expected 10 departments and 1 employee, got %d and %d", len(store.departments), len(store.employees))
+	}
+	slot, ok := store.headcount[[2]int64{nested.Children[0].ID, developer}]
+	if !ok || slot.Approved != 2 {
+		t.Errorf("expected cloned Sales to get 2 approved developer slots, got %+v", slot)
+	}
+}
+
+func TestClone_RejectsLargeSubtree(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	for i := range 1000 {
+		store.addDepartment("Team "+strconv.Itoa(i), &root)
+	}
+
+	svc, _ := newDepartmentService(store)
+
+	_, err := svc.Clone(context.Background(), root, &dto.CloneDepartmentRequest{NameSuffix: " 2"})
+	if !errors.Is(err, domain.ErrCloneTooLarge) {
+		t.Fatalf("expected clone too large, got %v", err)
+	}
+	if len(store.departments) != 1001 {
+		t.Errorf("rejected clone must not create departments, got %d", len(store.departments))
+	}
+}
+
+func TestSetHead_ValidatesEmployee(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	sales := store.addDepartment("Sales", &root)
+	cfo := store.addEmployee(root, "Anna")
+	left := store.addEmployee(sales, "John")
+
+	svc, _ := newDepartmentService(store)
+	empSvc, _ := newEmployeeService(store)
+	ctx := context.Background()
+
+	if _, err := svc.SetHead(ctx, sales, &dto.SetDepartmentHeadRequest{EmployeeID: 99}); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Fatalf("expected employee not found, got %v", err)
+	}
+	if err := empSvc.Delete(ctx, left); err != nil {
+		t.Fatalf("delete employee: %v", err)
+	}
+	if _, err := svc.SetHead(ctx, sales, &dto.SetDepartmentHeadRequest{EmployeeID: left}); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Fatalf("expected deleted employee to be rejected, got %v", err)
+	}
+
+	// Руководитель может числиться в другом подразделении
+	dept, err := svc.SetHead(ctx, sales, &dto.SetDepartmentHeadRequest{EmployeeID: cfo})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dept.HeadEmployeeID == nil || *dept.HeadEmployeeID != cfo {
+		t.Fatalf("expected head %d, got %v", cfo, dept.HeadEmployeeID)
+	}
+
+	// Удаление сотрудника снимает его с руководства
+	if err := empSvc.Delete(ctx, cfo); err != nil {
+		t.Fatalf("delete employee: %v", err)
+	}
+	if head := store.departments[sales].HeadEmployeeID; head != nil {
+		t.Errorf("expected head to be cleared, got %d", *head)
+	}
+}
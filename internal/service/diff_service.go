@@ -0,0 +1,146 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/repository"
+)
+
+// DiffService определяет интерфейс сравнения состояний организации
+type DiffService interface {
+	Diff(ctx context.Context, query *dto.OrgDiffQuery) (*domain.OrgDiff, error)
+}
+
+type diffService struct {
+	temporalRepo repository.TemporalRepository
+	snapshotRepo repository.SnapshotRepository
+}
+
+// NewDiffService создаёт новый экземпляр сервиса
+func NewDiffService(temporalRepo repository.TemporalRepository, snapshotRepo repository.SnapshotRepository) DiffService {
+	return &diffService{
+		temporalRepo: temporalRepo,
+		snapshotRepo: snapshotRepo,
+	}
+}
+
+// Diff возвращает изменения структуры организации между двумя состояниями,
+// каждое из которых задано датой или снимком
+func (s *diffService) Diff(ctx context.Context, query *dto.OrgDiffQuery) (*domain.OrgDiff, error) {
+	from, err := s.state(ctx, query.From, query.FromSnapshotID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.state(ctx, query.To, query.ToSnapshotID)
+	if err != nil {
+		return nil, err
+	}
+	return diffOrgStates(from, to), nil
+}
+
+// state возвращает состояние организации из снимка snapshotID, если он задан, иначе - на дату date
+func (s *diffService) state(ctx context.Context, date *string, snapshotID *int64) (*domain.OrgState, error) {
+	if snapshotID != nil {
+		if _, err := s.snapshotRepo.GetByID(ctx, *snapshotID); err != nil {
+			return nil, err
+		}
+		return s.snapshotRepo.GetState(ctx, *snapshotID)
+	}
+
+	asOf, err := time.Parse("2006-01-02", *date)
+	if err != nil {
+		return nil, err
+	}
+	return s.temporalRepo.GetState(ctx, asOf)
+}
+
+// diffOrgStates сравнивает два состояния организации. Изменения упорядочены по ID;
+// подразделение, которое одновременно переименовали и переместили, даёт два изменения
+func diffOrgStates(from, to *domain.OrgState) *domain.OrgDiff {
+	diff := &domain.OrgDiff{}
+
+	before := make(map[int64]*domain.Department, len(from.Departments))
+	for i := range from.Departments {
+		before[from.Departments[i].ID] = &from.Departments[i]
+	}
+	after := make(map[int64]*domain.Department, len(to.Departments))
+	for i := range to.Departments {
+		after[to.Departments[i].ID] = &to.Departments[i]
+	}
+
+	for _, id := range unionIDs(before, after) {
+		old, cur := before[id], after[id]
+		change := domain.DepartmentChange{DepartmentID: id, Before: old, After: cur}
+		switch {
+		case old == nil:
+			change.Type = domain.ChangeDepartmentAdded
+		case cur == nil:
+			change.Type = domain.ChangeDepartmentRemoved
+		default:
+			if old.Name != cur.Name {
+				change.Type = domain.ChangeDepartmentRenamed
+				diff.Departments = append(diff.Departments, change)
+			}
+			if !domain.SameParent(old.ParentID, cur.ParentID) {
+				change.Type = domain.ChangeDepartmentMoved
+				diff.Departments = append(diff.Departments, change)
+			}
+			continue
+		}
+		diff.Departments = append(diff.Departments, change)
+	}
+
+	wasIn := make(map[int64]*domain.Employee, len(from.Employees))
+	for i := range from.Employees {
+		wasIn[from.Employees[i].ID] = &from.Employees[i]
+	}
+	isIn := make(map[int64]*domain.Employee, len(to.Employees))
+	for i := range to.Employees {
+		isIn[to.Employees[i].ID] = &to.Employees[i]
+	}
+
+	for _, id := range unionIDs(wasIn, isIn) {
+		old, cur := wasIn[id], isIn[id]
+		change := domain.EmployeeChange{EmployeeID: id}
+		switch {
+		case old == nil:
+			change.Type = domain.ChangeEmployeeHired
+			change.FullName = cur.FullName
+			change.ToDepartmentID = &cur.DepartmentID
+		case cur == nil:
+			change.Type = domain.ChangeEmployeeRemoved
+			change.FullName = old.FullName
+			change.FromDepartmentID = &old.DepartmentID
+		case old.DepartmentID != cur.DepartmentID:
+			change.Type = domain.ChangeEmployeeTransferred
+			change.FullName = cur.FullName
+			change.FromDepartmentID = &old.DepartmentID
+			change.ToDepartmentID = &cur.DepartmentID
+		default:
+			continue
+		}
+		diff.Employees = append(diff.Employees, change)
+	}
+
+	return diff
+}
+
+// unionIDs возвращает отсортированные ключи обоих словарей без повторов
+func unionIDs[T any](a, b map[int64]T) []int64 {
+	ids := make([]int64, 0, len(a)+len(b))
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, cmp.Compare[int64])
+	return ids
+}
@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func TestDiff_ReportsChanges(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	renamed := store.addDepartment("Platform", &root)
+	moved := store.addDepartment("QA", nil)
+	closed := store.addDepartment("Legacy", &root)
+	opened := store.addDepartment("Data", &root)
+	stayed := store.addEmployee(root, "Ann")
+	transferred := store.addEmployee(moved, "Bob")
+	left := store.addEmployee(closed, "Carl")
+	hired := store.addEmployee(opened, "Dina")
+
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jun := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	store.addVersion(root, "Company", nil, jan, nil)
+	store.addVersion(renamed, "Backend", &root, jan, &jun)
+	store.addVersion(renamed, "Platform", &root, jun, nil)
+	store.addVersion(moved, "QA", &root, jan, &jun)
+	store.addVersion(moved, "QA", nil, jun, nil)
+	store.addVersion(closed, "Legacy", &root, jan, &jun)
+	store.addVersion(opened, "Data", &root, jun, nil)
+	store.assign(stayed, root, jan)
+	store.assign(transferred, root, jan)
+	store.assign(transferred, moved, jun)
+	store.assign(left, closed, jan)
+	store.assignments[len(store.assignments)-1].ValidTo = &jun
+	store.assign(hired, opened, jun)
+
+	svc := service.NewDiffService(&memTemporalRepo{store: store}, &memSnapshotRepo{store: store})
+	diff, err := svc.Diff(context.Background(), &dto.OrgDiffQuery{From: ptr("2024-03-01"), To: ptr("2024-07-01")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var departments []string
+	for _, change := range diff.Departments {
+		departments = append(departments, fmt.Sprintf("%s %d", change.Type, change.DepartmentID))
+	}
+	wantDepartments := []string{
+		fmt.Sprintf("%s %d", domain.ChangeDepartmentRenamed, renamed),
+		fmt.Sprintf("%s %d", domain.ChangeDepartmentMoved, moved),
+		fmt.Sprintf("%s %d", domain.ChangeDepartmentRemoved, closed),
+		fmt.Sprintf("%s %d", domain.ChangeDepartmentAdded, opened),
+	}
+	if !slices.Equal(departments, wantDepartments) {
+		t.Errorf("expected department changes %v, got %v", wantDepartments, departments)
+	}
+	if change := diff.Departments[0]; change.Before.Name != "Backend" || change.After.Name != "Platform" {
+		t.Errorf("expected rename from Backend to Platform, got %+v -> %+v", change.Before, change.After)
+	}
+
+	var employees []string
+	for _, change := range diff.Employees {
+		employees = append(employees, fmt.Sprintf("%s %s", change.Type, change.FullName))
+	}
+	wantEmployees := []string{"transferred Bob", "removed Carl", "hired Dina"}
+	if !slices.Equal(employees, wantEmployees) {
+		t.Errorf("expected employee changes %v, got %v", wantEmployees, employees)
+	}
+	if change := diff.Employees[0]; *change.FromDepartmentID != root || *change.ToDepartmentID != moved {
+		t.Errorf("expected transfer from %d to %d, got %+v", root, moved, change)
+	}
+
+	same, err := svc.Diff(context.Background(), &dto.OrgDiffQuery{From: ptr("2024-07-01"), To: ptr("2024-08-01")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(same.Departments) != 0 || len(same.Employees) != 0 {
+		t.Errorf("expected no changes, got %+v", same)
+	}
+}
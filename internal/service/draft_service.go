@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/repository"
+)
+
+// DraftService определяет интерфейс работы с черновиками реорганизации
+type DraftService interface {
+	Create(ctx context.Context, req *dto.CreateDraftRequest) (*domain.Draft, error)
+	GetByID(ctx context.Context, id int64) (*domain.Draft, error)
+	AddOperation(ctx context.Context, draftID int64, req *dto.DraftOperationRequest) (*domain.DraftOperation, error)
+	RemoveOperation(ctx context.Context, draftID, id int64) error
+	Preview(ctx context.Context, id int64, query *dto.DraftPreviewQuery) ([]domain.Department, error)
+	Apply(ctx context.Context, id int64) (*domain.Draft, error)
+	Discard(ctx context.Context, id int64) (*domain.Draft, error)
+}
+
+type draftService struct {
+	draftRepo   repository.DraftRepository
+	txManager   repository.TxManager
+	departments *departmentService
+	employees   *employeeService
+}
+
+// NewDraftService создаёт новый экземпляр сервиса. Операции черновика выполняются
+// той же логикой и с теми же правилами, что и одиночные изменения: departments и
+// employees должны быть созданы NewDepartmentService и NewEmployeeService
+func NewDraftService(
+	draftRepo repository.DraftRepository,
+	departments DepartmentService,
+	employees EmployeeService,
+	txManager repository.TxManager,
+) DraftService {
+	return &draftService{
+		draftRepo:   draftRepo,
+		txManager:   txManager,
+		departments: departments.(*departmentService),
+		employees:   employees.(*employeeService),
+	}
+}
+
+func (s *draftService) Create(ctx context.Context, req *dto.CreateDraftRequest) (*domain.Draft, error) {
+	draft := &domain.Draft{
+		Name:      strings.TrimSpace(req.Name),
+		Status:    domain.DraftStatusOpen,
+		CreatedBy: currentActor(ctx),
+	}
+	if err := s.draftRepo.Create(ctx, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (s *draftService) GetByID(ctx context.Context, id int64) (*domain.Draft, error) {
+	return s.draftRepo.GetByID(ctx, id)
+}
+
+// AddOperation добавляет операцию в конец открытого черновика. Здесь проверяется только
+// полнота операции и метки; применимость проверяется при предпросмотре и применении
+func (s *draftService) AddOperation(ctx context.Context, draftID int64, req *dto.DraftOperationRequest) (*domain.DraftOperation, error) {
+	op := &domain.DraftOperation{
+		DraftID:       draftID,
+		Type:          req.Type,
+		Ref:           req.Ref,
+		DepartmentID:  req.DepartmentID,
+		DepartmentRef: req.DepartmentRef,
+		ParentID:      req.ParentID,
+		ParentRef:     req.ParentRef,
+		DeleteMode:    req.Mode,
+		ReassignToID:  req.ReassignToDepartmentID,
+		ReassignToRef: req.ReassignToRef,
+		EmployeeID:    req.EmployeeID,
+	}
+	if req.Name != nil {
+		op.Name = *req.Name
+	}
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		draft, err := repos.Drafts.GetByIDForUpdate(ctx, draftID)
+		if err != nil {
+			return err
+		}
+		if draft.Status != domain.DraftStatusOpen {
+			return domain.ErrDraftNotOpen
+		}
+
+		if err := validateDraftOperation(op, draft.Operations); err != nil {
+			return err
+		}
+		return repos.Drafts.AddOperation(ctx, op)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// RemoveOperation удаляет операцию из открытого черновика
+func (s *draftService) RemoveOperation(ctx context.Context, draftID, id int64) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		draft, err := repos.Drafts.GetByIDForUpdate(ctx, draftID)
+		if err != nil {
+			return err
+		}
+		if draft.Status != domain.DraftStatusOpen {
+			return domain.ErrDraftNotOpen
+		}
+		return repos.Drafts.DeleteOperation(ctx, draftID, id)
+	})
+}
+
+// Preview выполняет операции черновика в транзакции, которая затем откатывается,
+// и возвращает дерево организации, каким оно стало бы после применения
+func (s *draftService) Preview(ctx context.Context, id int64, query *dto.DraftPreviewQuery) ([]domain.Department, error) {
+	var forest []domain.Department
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		draft, err := repos.Drafts.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if draft.Status != domain.DraftStatusOpen {
+			return domain.ErrDraftNotOpen
+		}
+
+		if err := s.run(ctx, repos, draft.Operations); err != nil {
+			return err
+		}
+
+		forest, err = repos.Departments.GetForest(ctx, query.Depth, query.IncludeEmployees)
+		if err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+	return forest, nil
+}
+
+// Apply выполняет все операции черновика в одной транзакции и закрывает черновик.
+// Если хотя бы одна операция не проходит проверку, не применяется ни одна
+func (s *draftService) Apply(ctx context.Context, id int64) (*domain.Draft, error) {
+	var draft *domain.Draft
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		draft, err = repos.Drafts.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if draft.Status != domain.DraftStatusOpen {
+			return domain.ErrDraftNotOpen
+		}
+
+		if err := s.run(ctx, repos, draft.Operations); err != nil {
+			return err
+		}
+		return repos.Drafts.Close(ctx, draft, domain.DraftStatusApplied)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// Discard закрывает черновик без применения операций
+func (s *draftService) Discard(ctx context.Context, id int64) (*domain.Draft, error) {
+	var draft *domain.Draft
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		draft, err = repos.Drafts.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if draft.Status != domain.DraftStatusOpen {
+			return domain.ErrDraftNotOpen
+		}
+		return repos.Drafts.Close(ctx, draft, domain.DraftStatusDiscarded)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// run выполняет операции по порядку в транзакции repos. Ошибка операции
+// возвращается как *domain.DraftOperationError
+func (s *draftService) run(ctx context.Context, repos repository.Repositories, ops []domain.DraftOperation) error {
+	// refs - ID подразделений, созданных операциями с метками
+	refs := make(map[string]int64)
+	for i := range ops {
+		if err := s.runOperation(ctx, repos, &ops[i], refs); err != nil {
+			return &domain.DraftOperationError{Position: ops[i].Position, Type: ops[i].Type, Err: err}
+		}
+	}
+	return nil
+}
+
+func (s *draftService) runOperation(ctx context.Context, repos repository.Repositories, op *domain.DraftOperation, refs map[string]int64) error {
+	// resolve возвращает ID подразделения по ID или метке; nil, если не задано ни то, ни другое
+	resolve := func(id *int64, ref string) (*int64, error) {
+		if ref == "" {
+			return id, nil
+		}
+		created, ok := refs[ref]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown ref %q", domain.ErrInvalidDraftOperation, ref)
+		}
+		return &created, nil
+	}
+
+	deptID, err := resolve(op.DepartmentID, op.DepartmentRef)
+	if err != nil {
+		return err
+	}
+	parentID, err := resolve(op.ParentID, op.ParentRef)
+	if err != nil {
+		return err
+	}
+
+	switch op.Type {
+	case domain.DraftOperationCreate:
+		dept, err := s.departments.create(ctx, repos, &dto.CreateDepartmentRequest{Name: op.Name, ParentID: parentID})
+		if err != nil {
+			return err
+		}
+		if op.Ref != "" {
+			refs[op.Ref] = dept.ID
+		}
+		return nil
+
+	case domain.DraftOperationRename:
+		_, err := s.departments.update(ctx, repos, *deptID, &dto.UpdateDepartmentRequest{Name: &op.Name})
+		return err
+
+	case domain.DraftOperationMove:
+		_, err := s.departments.update(ctx, repos, *deptID, &dto.UpdateDepartmentRequest{ParentID: parentID})
+		return err
+
+	case domain.DraftOperationDelete:
+		targetID, err := resolve(op.ReassignToID, op.ReassignToRef)
+		if err != nil {
+			return err
+		}
+		return s.departments.delete(ctx, repos, *deptID, &dto.DeleteDepartmentQuery{Mode: op.DeleteMode, ReassignToDepartmentID: targetID})
+
+	case domain.DraftOperationTransfer:
+		_, err := s.employees.transfer(ctx, repos, *op.EmployeeID, *deptID, today())
+		return err
+
+	default:
+		return fmt.Errorf("%w: unknown type %q", domain.ErrInvalidDraftOperation, op.Type)
+	}
+}
+
+// validateDraftOperation проверяет, что у операции заданы нужные для её типа поля,
+// а метки ссылаются на подразделения, создаваемые предыдущими операциями
+func validateDraftOperation(op *domain.DraftOperation, previous []domain.DraftOperation) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidDraftOperation, fmt.Sprintf(format, args...))
+	}
+
+	refs := make(map[string]bool)
+	for _, prev := range previous {
+		if prev.Ref != "" {
+			refs[prev.Ref] = true
+		}
+	}
+
+	// Каждое подразделение задаётся либо ID, либо меткой
+	for _, field := range []struct {
+		name string
+		id   *int64
+		ref  string
+	}{
+		{"department", op.DepartmentID, op.DepartmentRef},
+		{"parent", op.ParentID, op.ParentRef},
+		{"reassign_to", op.ReassignToID, op.ReassignToRef},
+	} {
+		if field.id != nil && field.ref != "" {
+			return invalid("%s_id and %s_ref are mutually exclusive", field.name, field.name)
+		}
+		if field.ref != "" && !refs[field.ref] {
+			return invalid("%s_ref %q is not created by a previous operation", field.name, field.ref)
+		}
+	}
+
+	hasDepartment := op.DepartmentID != nil || op.DepartmentRef != ""
+	hasParent := op.ParentID != nil || op.ParentRef != ""
+
+	if op.Ref != "" {
+		if op.Type != domain.DraftOperationCreate {
+			return invalid("ref is only allowed for create")
+		}
+		if refs[op.Ref] {
+			return invalid("ref %q is already used", op.Ref)
+		}
+	}
+
+	switch op.Type {
+	case domain.DraftOperationCreate:
+		if op.Name == "" {
+			return invalid("name is required")
+		}
+	case domain.DraftOperationRename:
+		if !hasDepartment || op.Name == "" {
+			return invalid("department and name are required")
+		}
+	case domain.DraftOperationMove:
+		if !hasDepartment || !hasParent {
+			return invalid("department and parent are required")
+		}
+	case domain.DraftOperationDelete:
+		if !hasDepartment || op.DeleteMode == "" {
+			return invalid("department and mode are required")
+		}
+	case domain.DraftOperationTransfer:
+		if op.EmployeeID == nil || !hasDepartment {
+			return invalid("employee_id and department are required")
+		}
+	default:
+		return invalid("unknown type %q", op.Type)
+	}
+	return nil
+}
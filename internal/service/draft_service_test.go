@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func newDraftService(store *memStore) service.DraftService {
+	departments, txManager := newDepartmentService(store)
+	employees, _ := newEmployeeService(store)
+	return service.NewDraftService(store.repositories().Drafts, departments, employees, txManager)
+}
+
+func TestDraft_ApplyIsAtomic(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	backend := store.addDepartment("Backend", &root)
+	emp := store.addEmployee(backend, "John")
+
+	svc := newDraftService(store)
+	ctx := context.Background()
+
+	draft, err := svc.Create(ctx, &dto.CreateDraftRequest{Name: "Q3 reorg"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := []dto.DraftOperationRequest{
+		{Type: domain.DraftOperationCreate, Ref: "platform", ParentID: &root, Name: ptr("Platform")},
+		{Type: domain.DraftOperationMove, DepartmentID: &backend, ParentRef: "platform"},
+		{Type: domain.DraftOperationTransfer, EmployeeID: &emp, DepartmentRef: "platform"},
+		// Компания внутри собственного потомка - цикл
+		{Type: domain.DraftOperationMove, DepartmentID: &root, ParentID: &backend},
+	}
+	var last *domain.DraftOperation
+	for _, req := range ops {
+		if last, err = svc.AddOperation(ctx, draft.ID, &req); err != nil {
+			t.Fatalf("add operation: %v", err)
+		}
+	}
+
+	_, err = svc.Apply(ctx, draft.ID)
+	var opErr *domain.DraftOperationError
+	if !errors.As(err, &opErr) || opErr.Position != 4 || !errors.Is(err, domain.ErrCyclicReference) {
+		t.Fatalf("expected cyclic reference in operation 4, got %v", err)
+	}
+	if len(store.departments) != 2 || store.employees[emp].DepartmentID != backend || store.drafts[draft.ID].Status != domain.DraftStatusOpen {
+		t.Fatalf("expected nothing applied, got %+v %+v", store.departments, store.drafts[draft.ID])
+	}
+
+	if err := svc.RemoveOperation(ctx, draft.ID, last.ID); err != nil {
+		t.Fatalf("remove operation: %v", err)
+	}
+
+	forest, err := svc.Preview(ctx, draft.ID, &dto.DraftPreviewQuery{Depth: 5, IncludeEmployees: true})
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if len(forest) != 1 || len(forest[0].Children) != 1 || forest[0].Children[0].Name != "Platform" {
+		t.Fatalf("expected Company > Platform in preview, got %+v", forest)
+	}
+	platform := forest[0].Children[0]
+	if len(platform.Children) != 1 || platform.Children[0].ID != backend || len(platform.Employees) != 1 {
+		t.Fatalf("expected Backend and John under Platform, got %+v", platform)
+	}
+	if len(store.departments) != 2 {
+		t.Fatalf("preview must not change the organization, got %+v", store.departments)
+	}
+
+	applied, err := svc.Apply(ctx, draft.ID)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if applied.Status != domain.DraftStatusApplied || applied.ClosedAt == nil {
+		t.Errorf("expected applied draft, got %+v", applied)
+	}
+	if dept := store.departments[backend]; dept.ParentID == nil || *dept.ParentID != platform.ID {
+		t.Errorf("expected Backend under Platform, got %+v", dept)
+	}
+	if got := store.employees[emp].DepartmentID; got != platform.ID {
+		t.Errorf("expected John in Platform, got %d", got)
+	}
+
+	if _, err := svc.Apply(ctx, draft.ID); !errors.Is(err, domain.ErrDraftNotOpen) {
+		t.Errorf("expected draft not open on second apply, got %v", err)
+	}
+}
+
+func TestDraft_AddOperationValidates(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+
+	svc := newDraftService(store)
+	ctx := context.Background()
+
+	draft, _ := svc.Create(ctx, &dto.CreateDraftRequest{Name: "reorg"})
+	if _, err := svc.AddOperation(ctx, draft.ID, &dto.DraftOperationRequest{Type: domain.DraftOperationCreate, Ref: "hr", Name: ptr("HR")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  dto.DraftOperationRequest
+	}{
+		{"unknown ref", dto.DraftOperationRequest{Type: domain.DraftOperationRename, DepartmentRef: "it", Name: ptr("IT")}},
+		{"duplicate ref", dto.DraftOperationRequest{Type: domain.DraftOperationCreate, Ref: "hr", Name: ptr("HR 2")}},
+		{"ref on rename", dto.DraftOperationRequest{Type: domain.DraftOperationRename, Ref: "x", DepartmentID: &root, Name: ptr("X")}},
+		{"id and ref", dto.DraftOperationRequest{Type: domain.DraftOperationMove, DepartmentRef: "hr", ParentID: &root, ParentRef: "hr"}},
+		{"missing mode", dto.DraftOperationRequest{Type: domain.DraftOperationDelete, DepartmentID: &root}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.AddOperation(ctx, draft.ID, &tt.req); !errors.Is(err, domain.ErrInvalidDraftOperation) {
+				t.Errorf("expected invalid draft operation, got %v", err)
+			}
+		})
+	}
+
+	if _, err := svc.Discard(ctx, draft.ID); err != nil {
+		t.Fatalf("discard: %v", err)
+	}
+	req := dto.DraftOperationRequest{Type: domain.DraftOperationCreate, Name: ptr("IT")}
+	if _, err := svc.AddOperation(ctx, draft.ID, &req); !errors.Is(err, domain.ErrDraftNotOpen) {
+		t.Errorf("expected draft not open after discard, got %v", err)
+	}
+	if len(store.departments) != 1 {
+		t.Errorf("discarded draft must not change the organization, got %+v", store.departments)
+	}
+}
@@ -2,41 +2,67 @@ package service
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/org-structure-api/internal/domain"
 	"github.com/org-structure-api/internal/dto"
 	"github.com/org-structure-api/internal/repository"
+	"gorm.io/gorm"
 )
 
 // EmployeeService определяет интерфейс бизнес-логики для сотрудников
 type EmployeeService interface {
 	Create(ctx context.Context, departmentID int64, req *dto.CreateEmployeeRequest) (*domain.Employee, error)
-	GetByID(ctx context.Context, id int64) (*domain.Employee, error)
+	GetByID(ctx context.Context, id int64, query *dto.GetEmployeeQuery) (*domain.Employee, error)
 	GetByDepartmentID(ctx context.Context, departmentID int64) ([]domain.Employee, error)
+	Update(ctx context.Context, id int64, req *dto.UpdateEmployeeRequest) (*domain.Employee, error)
+	Delete(ctx context.Context, id int64) error
+	Transfer(ctx context.Context, id int64, req *dto.TransferEmployeeRequest) (*domain.Employee, error)
+	GetTransferHistory(ctx context.Context, id int64) ([]domain.EmployeeTransfer, error)
+	ListByDepartment(ctx context.Context, departmentID int64, query *dto.ListEmployeesQuery) ([]domain.Employee, string, error)
+	Restore(ctx context.Context, id int64) (*domain.Employee, error)
 }
 
 type employeeService struct {
-	empRepo  repository.EmployeeRepository
-	deptRepo repository.DepartmentRepository
+	empRepo      repository.EmployeeRepository
+	deptRepo     repository.DepartmentRepository
+	transferRepo repository.TransferRepository
+	temporalRepo repository.TemporalRepository
+	txManager    repository.TxManager
+	policy       EmployeePolicy
 }
 
-// NewEmployeeService создаёт новый экземпляр сервиса
-func NewEmployeeService(empRepo repository.EmployeeRepository, deptRepo repository.DepartmentRepository) EmployeeService {
+// EmployeePolicy - настраиваемые правила приёма и изменения сотрудников
+type EmployeePolicy struct {
+	// StrictHeadcount - принимать сотрудников только на вакантные места плана численности
+	StrictHeadcount bool
+	// AutoCreatePositions - создавать должность в справочнике по неизвестному названию
+	// вместо ошибки ErrPositionNotFound
+	AutoCreatePositions bool
+}
+
+// NewEmployeeService создаёт новый экземпляр сервиса с правилами policy
+func NewEmployeeService(
+	empRepo repository.EmployeeRepository,
+	deptRepo repository.DepartmentRepository,
+	transferRepo repository.TransferRepository,
+	temporalRepo repository.TemporalRepository,
+	txManager repository.TxManager,
+	policy EmployeePolicy,
+) EmployeeService {
 	return &employeeService{
-		empRepo:  empRepo,
-		deptRepo: deptRepo,
+		empRepo:      empRepo,
+		deptRepo:     deptRepo,
+		transferRepo: transferRepo,
+		temporalRepo: temporalRepo,
+		txManager:    txManager,
+		policy:       policy,
 	}
 }
 
 func (s *employeeService) Create(ctx context.Context, departmentID int64, req *dto.CreateEmployeeRequest) (*domain.Employee, error) {
-	// Проверяем существование подразделения
-	_, err := s.deptRepo.GetByID(ctx, departmentID)
-	if err != nil {
-		return nil, err
-	}
-	
 	emp := &domain.Employee{
 		DepartmentID: departmentID,
 		FullName:     strings.TrimSpace(req.FullName),
@@ -52,14 +78,50 @@ func (s *employeeService) Create(ctx context.Context, departmentID int64, req *d
 		emp.HiredAt = &hiredAt
 	}
 	
-	if err := s.empRepo.Create(ctx, emp); err != nil {
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		// Проверяем существование подразделения. При строгом контроле численности
+		// блокируем его, чтобы одновременные приёмы не заняли одно место дважды
+		getDepartment := repos.Departments.GetByID
+		if s.policy.StrictHeadcount {
+			getDepartment = repos.Departments.GetByIDForUpdate
+		}
+		if _, err := getDepartment(ctx, departmentID); err != nil {
+			return err
+		}
+
+		if err := resolvePosition(ctx, repos, emp, req.PositionID, s.policy.AutoCreatePositions); err != nil {
+			return err
+		}
+
+		if s.policy.StrictHeadcount {
+			if err := checkHeadcount(ctx, repos, departmentID, emp.PositionID); err != nil {
+				return err
+			}
+		}
+
+		if err := repos.Employees.Create(ctx, emp); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repos, domain.AuditEntityEmployee, emp.ID, domain.AuditOperationCreate, nil, *emp)
+	})
+	if err != nil {
 		return nil, err
 	}
 	
 	return emp, nil
 }
 
-func (s *employeeService) GetByID(ctx context.Context, id int64) (*domain.Employee, error) {
+// GetByID возвращает сотрудника; если задан as_of - с подразделением, в котором
+// он состоял на эту дату
+func (s *employeeService) GetByID(ctx context.Context, id int64, query *dto.GetEmployeeQuery) (*domain.Employee, error) {
+	asOf, err := parseAsOf(query.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	if asOf != nil {
+		return s.temporalRepo.GetEmployee(ctx, id, *asOf)
+	}
 	return s.empRepo.GetByID(ctx, id)
 }
 
@@ -72,3 +134,298 @@ func (s *employeeService) GetByDepartmentID(ctx context.Context, departmentID in
 	
 	return s.empRepo.GetByDepartmentID(ctx, departmentID)
 }
+
+func (s *employeeService) Update(ctx context.Context, id int64, req *dto.UpdateEmployeeRequest) (*domain.Employee, error) {
+	var hiredAt *time.Time
+	if req.HiredAt != nil {
+		date, err := time.Parse("2006-01-02", *req.HiredAt)
+		if err != nil {
+			return nil, err
+		}
+		hiredAt = &date
+	}
+
+	var emp *domain.Employee
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		emp, err = repos.Employees.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		before := *emp
+
+		if req.FullName != nil {
+			emp.FullName = strings.TrimSpace(*req.FullName)
+		}
+
+		if req.Position != nil || req.PositionID != nil {
+			if req.Position != nil {
+				emp.Position = strings.TrimSpace(*req.Position)
+			}
+			if err := resolvePosition(ctx, repos, emp, req.PositionID, s.policy.AutoCreatePositions); err != nil {
+				return err
+			}
+
+			// Новая должность занимает место в плане численности подразделения
+			if s.policy.StrictHeadcount && (before.PositionID == nil || *before.PositionID != *emp.PositionID) {
+				if _, err := repos.Departments.GetByIDForUpdate(ctx, emp.DepartmentID); err != nil {
+					return err
+				}
+				if err := checkHeadcount(ctx, repos, emp.DepartmentID, emp.PositionID); err != nil {
+					return err
+				}
+			}
+		}
+
+		if hiredAt != nil {
+			emp.HiredAt = hiredAt
+		}
+
+		if err := repos.Employees.Update(ctx, emp); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repos, domain.AuditEntityEmployee, id, domain.AuditOperationUpdate, before, *emp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return emp, nil
+}
+
+func (s *employeeService) Delete(ctx context.Context, id int64) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		emp, err := repos.Employees.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := repos.Employees.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repos, domain.AuditEntityEmployee, id, domain.AuditOperationDelete, *emp, nil)
+	})
+}
+
+func (s *employeeService) Transfer(ctx context.Context, id int64, req *dto.TransferEmployeeRequest) (*domain.Employee, error) {
+	effectiveDate := today()
+	if req.EffectiveDate != nil {
+		date, err := time.Parse("2006-01-02", *req.EffectiveDate)
+		if err != nil {
+			return nil, err
+		}
+		if date.After(effectiveDate) {
+			return nil, domain.ErrFutureEffectiveDate
+		}
+		effectiveDate = date
+	}
+
+	var emp *domain.Employee
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		emp, err = s.transfer(ctx, repos, id, req.DepartmentID, effectiveDate)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return emp, nil
+}
+
+func (s *employeeService) transfer(ctx context.Context, repos repository.Repositories, id, departmentID int64, effectiveDate time.Time) (*domain.Employee, error) {
+	emp, err := repos.Employees.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if emp.DepartmentID == departmentID {
+		return nil, domain.ErrTransferToSameDepartment
+	}
+
+	// Проверяем существование целевого подразделения; при строгом контроле
+	// численности блокируем его и проверяем вакансию на должность сотрудника
+	getDepartment := repos.Departments.GetByID
+	if s.policy.StrictHeadcount {
+		getDepartment = repos.Departments.GetByIDForUpdate
+	}
+	if _, err := getDepartment(ctx, departmentID); err != nil {
+		return nil, err
+	}
+	if s.policy.StrictHeadcount {
+		if err := checkHeadcount(ctx, repos, departmentID, emp.PositionID); err != nil {
+			return nil, err
+		}
+	}
+
+	before := *emp
+	fromDeptID := emp.DepartmentID
+	emp.DepartmentID = departmentID
+	if err := repos.Employees.Transfer(ctx, emp, effectiveDate); err != nil {
+		return nil, err
+	}
+
+	err = repos.Transfers.Create(ctx, &domain.EmployeeTransfer{
+		EmployeeID:       id,
+		FromDepartmentID: &fromDeptID,
+		ToDepartmentID:   &departmentID,
+		EffectiveDate:    effectiveDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, repos, domain.AuditEntityEmployee, id, domain.AuditOperationTransfer, before, *emp); err != nil {
+		return nil, err
+	}
+	return emp, nil
+}
+
+func (s *employeeService) GetTransferHistory(ctx context.Context, id int64) ([]domain.EmployeeTransfer, error) {
+	// Проверяем существование сотрудника
+	if _, err := s.empRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.transferRepo.GetByEmployeeID(ctx, id)
+}
+
+// ListByDepartment возвращает страницу сотрудников подразделения (при recursive -
+// вместе со всеми дочерними) и курсор следующей страницы (пустой, если страница последняя).
+// Если задан as_of, и подразделения, и сотрудники берутся в состоянии на эту дату
+func (s *employeeService) ListByDepartment(ctx context.Context, departmentID int64, query *dto.ListEmployeesQuery) ([]domain.Employee, string, error) {
+	asOf, err := parseAsOf(query.AsOf)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Проверяем существование подразделения
+	var subtreeIDs []int64
+	if asOf != nil {
+		if subtreeIDs, err = s.temporalRepo.GetSubtreeIDs(ctx, departmentID, *asOf); err != nil {
+			return nil, "", err
+		}
+	} else if _, err := s.deptRepo.GetByID(ctx, departmentID); err != nil {
+		return nil, "", err
+	}
+
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	filter := repository.EmployeeFilter{
+		DepartmentIDs: []int64{departmentID},
+		Position:      strings.TrimSpace(query.Position),
+		PositionID:    query.PositionID,
+		SortField:     query.Sort,
+		Desc:          query.Order == "desc",
+		AsOf:          asOf,
+		After:         after,
+		// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+		Limit: query.Limit + 1,
+	}
+
+	switch {
+	case query.Recursive && asOf != nil:
+		filter.DepartmentIDs = subtreeIDs
+	case query.Recursive:
+		descendants, err := s.deptRepo.GetAllDescendantIDs(ctx, departmentID)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.DepartmentIDs = append(filter.DepartmentIDs, descendants...)
+	}
+
+	if query.HiredFrom != nil {
+		hiredFrom, err := time.Parse("2006-01-02", *query.HiredFrom)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.HiredFrom = &hiredFrom
+	}
+
+	if query.HiredTo != nil {
+		hiredTo, err := time.Parse("2006-01-02", *query.HiredTo)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.HiredTo = &hiredTo
+	}
+
+	employees, err := s.empRepo.List(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(employees) <= query.Limit {
+		return employees, "", nil
+	}
+
+	employees = employees[:query.Limit]
+	last := &employees[len(employees)-1]
+	next := encodeCursor(repository.Cursor{
+		Value: repository.EmployeeSortValue(last, query.Sort),
+		ID:    last.ID,
+	})
+
+	return employees, next, nil
+}
+
+// Restore восстанавливает удалённого сотрудника в его прежнем подразделении
+func (s *employeeService) Restore(ctx context.Context, id int64) (*domain.Employee, error) {
+	var emp *domain.Employee
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		emp, err = repos.Employees.GetWithDeletedForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !emp.DeletedAt.Valid {
+			return domain.ErrEmployeeNotDeleted
+		}
+
+		if _, err := repos.Departments.GetByIDForUpdate(ctx, emp.DepartmentID); err != nil {
+			if errors.Is(err, domain.ErrDepartmentNotFound) {
+				return domain.ErrParentDeleted
+			}
+			return err
+		}
+		if s.policy.StrictHeadcount {
+			if err := checkHeadcount(ctx, repos, emp.DepartmentID, emp.PositionID); err != nil {
+				return err
+			}
+		}
+
+		if err := repos.Employees.Restore(ctx, emp.ID); err != nil {
+			return err
+		}
+
+		before := *emp
+		emp.DeletedAt = gorm.DeletedAt{}
+		return recordAudit(ctx, repos, domain.AuditEntityEmployee, emp.ID, domain.AuditOperationRestore, before, *emp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return emp, nil
+}
+
+// today возвращает текущую дату (UTC) без времени
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// parseAsOf разбирает дату, на которую запрашивается состояние; nil означает текущее
+func parseAsOf(value *string) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
+	}
+	asOf, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return nil, err
+	}
+	return &asOf, nil
+}
@@ -0,0 +1,343 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func newEmployeeService(store *memStore) (service.EmployeeService, *memTxManager) {
+	txManager := &memTxManager{store: store}
+	repos := store.repositories()
+	return service.NewEmployeeService(repos.Employees, repos.Departments, repos.Transfers, &memTemporalRepo{store: store}, txManager, service.EmployeePolicy{}), txManager
+}
+
+func TestTransfer_RecordsHistory(t *testing.T) {
+	store := newMemStore()
+	from := store.addDepartment("IT", nil)
+	to := store.addDepartment("HR", nil)
+	emp := store.addEmployee(from, "John")
+
+	svc, _ := newEmployeeService(store)
+	_, err := svc.Transfer(context.Background(), emp, &dto.TransferEmployeeRequest{
+		DepartmentID:  to,
+		EffectiveDate: ptr("2024-06-01"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := store.employees[emp].DepartmentID; got != to {
+		t.Errorf("expected employee in %d, got %d", to, got)
+	}
+
+	history, err := svc.GetTransferHistory(context.Background(), emp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("expected 1 transfer, got %d", len(history))
+	}
+	if *history[0].FromDepartmentID != from || *history[0].ToDepartmentID != to {
+		t.Errorf("unexpected transfer: %+v", history[0])
+	}
+	if got := history[0].EffectiveDate.Format("2006-01-02"); got != "2024-06-01" {
+		t.Errorf("expected effective date '2024-06-01', got '%s'", got)
+	}
+}
+
+func TestTransfer_FutureEffectiveDate(t *testing.T) {
+	store := newMemStore()
+	from := store.addDepartment("IT", nil)
+	to := store.addDepartment("HR", nil)
+	emp := store.addEmployee(from, "John")
+
+	svc, _ := newEmployeeService(store)
+	_, err := svc.Transfer(context.Background(), emp, &dto.TransferEmployeeRequest{
+		DepartmentID:  to,
+		EffectiveDate: ptr("2999-01-01"),
+	})
+	if !errors.Is(err, domain.ErrFutureEffectiveDate) {
+		t.Errorf("expected ErrFutureEffectiveDate, got %v", err)
+	}
+}
+
+func TestTransfer_RollbackOnFailure(t *testing.T) {
+	store := newMemStore()
+	from := store.addDepartment("IT", nil)
+	to := store.addDepartment("HR", nil)
+	emp := store.addEmployee(from, "John")
+	store.failures["Transfers.Create"] = errInjected
+
+	svc, txManager := newEmployeeService(store)
+	_, err := svc.Transfer(context.Background(), emp, &dto.TransferEmployeeRequest{DepartmentID: to})
+	if !errors.Is(err, errInjected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if txManager.rollbacks != 1 {
+		t.Errorf("expected 1 rollback, got %d", txManager.rollbacks)
+	}
+	if got := store.employees[emp].DepartmentID; got != from {
+		t.Errorf("expected employee to stay in %d, got %d", from, got)
+	}
+}
+
+func TestListByDepartment_CursorPagination(t *testing.T) {
+	store := newMemStore()
+	dept := store.addDepartment("IT", nil)
+	for _, name := range []string{"Dave", "Alice", "Carol", "Bob", "Eve"} {
+		store.addEmployee(dept, name)
+	}
+
+	svc, _ := newEmployeeService(store)
+
+	var names []string
+	cursor := ""
+	for range 10 {
+		page, next, err := svc.ListByDepartment(context.Background(), dept, &dto.ListEmployeesQuery{
+			Limit:  2,
+			Cursor: cursor,
+			Sort:   "full_name",
+			Order:  "desc",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, emp := range page {
+			names = append(names, emp.FullName)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	want := []string{"Eve", "Dave", "Carol", "Bob", "Alice"}
+	if !slices.Equal(names, want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+}
+
+func TestListByDepartment_RecursiveWithFilters(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	child := store.addDepartment("IT", &root)
+	grandChild := store.addDepartment("Backend", &child)
+	store.addEmployee(root, "CEO")
+	store.addEmployee(child, "CTO")
+	store.addEmployee(grandChild, "Developer")
+
+	hiredAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for id, emp := range store.employees {
+		if emp.FullName != "CEO" {
+			emp.HiredAt = &hiredAt
+			store.employees[id] = emp
+		}
+	}
+
+	svc, _ := newEmployeeService(store)
+
+	page, _, err := svc.ListByDepartment(context.Background(), child, &dto.ListEmployeesQuery{
+		Limit: 10,
+		Sort:  "created_at",
+		Order: "asc",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 1 {
+		t.Errorf("expected only direct employees without recursive, got %d", len(page))
+	}
+
+	page, _, err = svc.ListByDepartment(context.Background(), root, &dto.ListEmployeesQuery{
+		Limit:     10,
+		Sort:      "hired_at",
+		Order:     "asc",
+		HiredFrom: ptr("2024-01-01"),
+		Recursive: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 2 {
+		t.Errorf("expected 2 employees hired since 2024 in the subtree, got %d", len(page))
+	}
+}
+
+func TestListByDepartment_InvalidCursor(t *testing.T) {
+	store := newMemStore()
+	dept := store.addDepartment("IT", nil)
+
+	svc, _ := newEmployeeService(store)
+	_, _, err := svc.ListByDepartment(context.Background(), dept, &dto.ListEmployeesQuery{
+		Limit:  10,
+		Cursor: "not-a-cursor",
+		Sort:   "full_name",
+		Order:  "asc",
+	})
+	if !errors.Is(err, domain.ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestRestoreEmployee(t *testing.T) {
+	store := newMemStore()
+	dept := store.addDepartment("IT", nil)
+	emp := store.addEmployee(dept, "Alice")
+
+	svc, _ := newEmployeeService(store)
+	ctx := context.Background()
+
+	if _, err := svc.Restore(ctx, emp); !errors.Is(err, domain.ErrEmployeeNotDeleted) {
+		t.Fatalf("expected ErrEmployeeNotDeleted, got %v", err)
+	}
+
+	if err := svc.Delete(ctx, emp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.GetByID(ctx, emp, &dto.GetEmployeeQuery{}); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Fatalf("expected deleted employee to be hidden, got %v", err)
+	}
+
+	restored, err := svc.Restore(ctx, emp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored.DeletedAt.Valid || store.employees[emp].DepartmentID != dept {
+		t.Errorf("expected employee to be restored into %d, got %+v", dept, restored)
+	}
+}
+
+func TestRestoreEmployee_DepartmentDeleted(t *testing.T) {
+	store := newMemStore()
+	dept := store.addDepartment("IT", nil)
+	emp := store.addEmployee(dept, "Alice")
+	store.softDeleteEmployee(emp, time.Now().Add(-time.Hour))
+	store.softDeleteDepartment(dept, time.Now())
+
+	svc, _ := newEmployeeService(store)
+	if _, err := svc.Restore(context.Background(), emp); !errors.Is(err, domain.ErrParentDeleted) {
+		t.Errorf("expected ErrParentDeleted, got %v", err)
+	}
+}
+
+func TestTransfer_StartsAssignmentAtEffectiveDate(t *testing.T) {
+	store := newMemStore()
+	from := store.addDepartment("IT", nil)
+	to := store.addDepartment("HR", nil)
+	emp := store.addEmployee(from, "John")
+	store.assign(emp, from, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	svc, _ := newEmployeeService(store)
+	ctx := context.Background()
+	_, err := svc.Transfer(ctx, emp, &dto.TransferEmployeeRequest{
+		DepartmentID:  to,
+		EffectiveDate: ptr("2024-06-01"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for asOf, want := range map[string]int64{"2024-05-31": from, "2024-06-01": to} {
+		got, err := svc.GetByID(ctx, emp, &dto.GetEmployeeQuery{AsOf: ptr(asOf)})
+		if err != nil {
+			t.Fatalf("as of %s: unexpected error: %v", asOf, err)
+		}
+		if got.DepartmentID != want {
+			t.Errorf("as of %s: expected department %d, got %d", asOf, want, got.DepartmentID)
+		}
+	}
+
+	if _, err := svc.GetByID(ctx, emp, &dto.GetEmployeeQuery{AsOf: ptr("2023-12-31")}); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("expected employee not found before assignment, got %v", err)
+	}
+}
+
+func TestTransfer_RejectsDateBeforeLatestTransfer(t *testing.T) {
+	store := newMemStore()
+	from := store.addDepartment("IT", nil)
+	to := store.addDepartment("HR", nil)
+	other := store.addDepartment("Sales", nil)
+	emp := store.addEmployee(from, "John")
+	store.assign(emp, from, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	svc, _ := newEmployeeService(store)
+	ctx := context.Background()
+	_, err := svc.Transfer(ctx, emp, &dto.TransferEmployeeRequest{
+		DepartmentID:  to,
+		EffectiveDate: ptr("2024-06-01"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = svc.Transfer(ctx, emp, &dto.TransferEmployeeRequest{
+		DepartmentID:  other,
+		EffectiveDate: ptr("2024-03-01"),
+	})
+	if !errors.Is(err, domain.ErrEffectiveDateTooEarly) {
+		t.Fatalf("expected ErrEffectiveDateTooEarly, got %v", err)
+	}
+
+	history, err := svc.GetTransferHistory(ctx, emp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("expected rejected transfer to stay out of history, got %d entries", len(history))
+	}
+	got, err := svc.GetByID(ctx, emp, &dto.GetEmployeeQuery{AsOf: ptr("2024-07-01")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.DepartmentID != to {
+		t.Errorf("expected later transfer to survive, got department %d", got.DepartmentID)
+	}
+}
+
+func TestListByDepartment_AsOf(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	child := store.addDepartment("Backend", &root)
+	other := store.addDepartment("Other", nil)
+	inChild := store.addEmployee(child, "Ann")
+	movedAway := store.addEmployee(other, "Bob")
+	removed := store.addEmployee(root, "Carl")
+
+	jan2024 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan2025 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.addVersion(root, "Company", nil, jan2024, nil)
+	store.addVersion(child, "Backend", &root, jan2024, nil)
+	store.addVersion(other, "Other", nil, jan2024, nil)
+	store.assign(inChild, child, jan2024)
+	store.assign(movedAway, child, jan2024)
+	store.assign(movedAway, other, jan2025)
+	store.assign(removed, root, jan2024)
+	store.softDeleteEmployee(removed, time.Now())
+
+	svc, _ := newEmployeeService(store)
+	query := &dto.ListEmployeesQuery{Limit: 10, Sort: "full_name", Order: "asc", Recursive: true, AsOf: ptr("2024-06-01")}
+	employees, _, err := svc.ListByDepartment(context.Background(), root, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, emp := range employees {
+		names = append(names, emp.FullName)
+	}
+	if want := []string{"Ann", "Bob", "Carl"}; !slices.Equal(names, want) {
+		t.Errorf("expected %v in the subtree on 2024-06-01, got %v", want, names)
+	}
+
+	query.AsOf = ptr("2023-01-01")
+	if _, _, err := svc.ListByDepartment(context.Background(), root, query); !errors.Is(err, domain.ErrDepartmentNotFound) {
+		t.Errorf("expected department not found before it existed, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/repository"
+)
+
+// HeadcountService определяет интерфейс планирования численности подразделений
+type HeadcountService interface {
+	SetSlot(ctx context.Context, departmentID, positionID int64, req *dto.SetHeadcountSlotRequest) (*domain.HeadcountSlot, error)
+	RemoveSlot(ctx context.Context, departmentID, positionID int64) error
+	GetVacancies(ctx context.Context, departmentID int64) (*domain.Vacancies, error)
+}
+
+type headcountService struct {
+	headcountRepo repository.HeadcountRepository
+	deptRepo      repository.DepartmentRepository
+	txManager     repository.TxManager
+}
+
+// NewHeadcountService создаёт новый экземпляр сервиса
+func NewHeadcountService(
+	headcountRepo repository.HeadcountRepository,
+	deptRepo repository.DepartmentRepository,
+	txManager repository.TxManager,
+) HeadcountService {
+	return &headcountService{
+		headcountRepo: headcountRepo,
+		deptRepo:      deptRepo,
+		txManager:     txManager,
+	}
+}
+
+// SetSlot задаёт число утверждённых мест должности в подразделении. Утвердить
+// меньше мест, чем уже занято, можно: вакансий тогда нет, сотрудники остаются
+func (s *headcountService) SetSlot(ctx context.Context, departmentID, positionID int64, req *dto.SetHeadcountSlotRequest) (*domain.HeadcountSlot, error) {
+	var slot *domain.HeadcountSlot
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		// Блокировка подразделения упорядочивает изменение мест и приём сотрудников
+		if _, err := repos.Departments.GetByIDForUpdate(ctx, departmentID); err != nil {
+			return err
+		}
+		if _, err := repos.Positions.GetByID(ctx, positionID); err != nil {
+			return err
+		}
+
+		before, err := repos.Headcount.Get(ctx, departmentID, positionID)
+		if err != nil && !errors.Is(err, domain.ErrHeadcountSlotNotFound) {
+			return err
+		}
+
+		err = repos.Headcount.Save(ctx, &domain.HeadcountSlot{
+			DepartmentID: departmentID,
+			PositionID:   positionID,
+			Approved:     *req.Approved,
+		})
+		if err != nil {
+			return err
+		}
+
+		slot, err = repos.Headcount.Get(ctx, departmentID, positionID)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return recordAudit(ctx, repos, domain.AuditEntityHeadcount, slot.ID, domain.AuditOperationCreate, nil, *slot)
+		}
+		return recordAudit(ctx, repos, domain.AuditEntityHeadcount, slot.ID, domain.AuditOperationUpdate, *before, *slot)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slot, nil
+}
+
+// RemoveSlot убирает должность из плана численности подразделения
+func (s *headcountService) RemoveSlot(ctx context.Context, departmentID, positionID int64) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if _, err := repos.Departments.GetByIDForUpdate(ctx, departmentID); err != nil {
+			return err
+		}
+
+		slot, err := repos.Headcount.Get(ctx, departmentID, positionID)
+		if err != nil {
+			return err
+		}
+		if err := repos.Headcount.Delete(ctx, departmentID, positionID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, domain.AuditEntityHeadcount, slot.ID, domain.AuditOperationDelete, *slot, nil)
+	})
+}
+
+// GetVacancies возвращает места подразделения и всех его дочерних с итогами по поддереву
+func (s *headcountService) GetVacancies(ctx context.Context, departmentID int64) (*domain.Vacancies, error) {
+	// Проверяем существование подразделения
+	if _, err := s.deptRepo.GetByID(ctx, departmentID); err != nil {
+		return nil, err
+	}
+
+	descendants, err := s.deptRepo.GetAllDescendantIDs(ctx, departmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.headcountRepo.List(ctx, append([]int64{departmentID}, descendants...))
+	if err != nil {
+		return nil, err
+	}
+
+	vacancies := &domain.Vacancies{DepartmentID: departmentID, Slots: slots}
+	for i := range slots {
+		vacancies.Approved += slots[i].Approved
+		vacancies.Filled += slots[i].Filled
+		vacancies.Open += slots[i].Open()
+	}
+	return vacancies, nil
+}
+
+// checkHeadcount проверяет, что в подразделении хватает вакантных мест для сотрудников
+// с должностями positionIDs - по месту на каждого. Подразделения без плана численности
+// не ограничиваются; если план есть, должность без мест в нём (или сотрудник без
+// должности) считается должностью без вакансий
+func checkHeadcount(ctx context.Context, repos repository.Repositories, departmentID int64, positionIDs ...*int64) error {
+	if len(positionIDs) == 0 {
+		return nil
+	}
+	slots, err := repos.Headcount.List(ctx, []int64{departmentID})
+	if err != nil {
+		return err
+	}
+	if len(slots) == 0 {
+		return nil
+	}
+
+	open := make(map[int64]int, len(slots))
+	for i := range slots {
+		open[slots[i].PositionID] = slots[i].Open()
+	}
+	for _, positionID := range positionIDs {
+		if positionID == nil || open[*positionID] == 0 {
+			return domain.ErrHeadcountExhausted
+		}
+		open[*positionID]--
+	}
+	return nil
+}
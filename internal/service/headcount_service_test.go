@@ -0,0 +1,233 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func newHeadcountService(store *memStore) service.HeadcountService {
+	return service.NewHeadcountService(store.repositories().Headcount, store.repositories().Departments, &memTxManager{store: store})
+}
+
+// newStrictEmployeeService создаёт сервис сотрудников со строгим контролем численности
+func newStrictEmployeeService(store *memStore) service.EmployeeService {
+	repos := store.repositories()
+	return service.NewEmployeeService(repos.Employees, repos.Departments, repos.Transfers, &memTemporalRepo{store: store}, &memTxManager{store: store}, service.EmployeePolicy{StrictHeadcount: true})
+}
+
+// addPosition добавляет должность в справочник в обход сервиса (без записи в журнал
+// аудита) и возвращает её ID
+func addPosition(t *testing.T, store *memStore, title string) int64 {
+	t.Helper()
+	position := &domain.Position{Title: title}
+	if err := store.repositories().Positions.Create(context.Background(), position); err != nil {
+		t.Fatalf("create position: %v", err)
+	}
+	return position.ID
+}
+
+func TestGetVacancies_RollsUpSubtree(t *testing.T) {
+	store := newMemStore()
+	company := store.addDepartment("Company", nil)
+	it := store.addDepartment("IT", &company)
+	backend := store.addDepartment("Backend", &it)
+	store.addDepartment("HR", &company)
+
+	developer := addPosition(t, store, "Developer")
+	lead := addPosition(t, store, "Team Lead")
+
+	svc := newHeadcountService(store)
+	empSvc, _ := newEmployeeService(store)
+	ctx := context.Background()
+
+	for _, plan := range []struct {
+		department, position int64
+		approved             int
+	}{
+		{it, lead, 1},
+		{backend, developer, 3},
+		{backend, lead, 1},
+	} {
+		if _, err := svc.SetSlot(ctx, plan.department, plan.position, &dto.SetHeadcountSlotRequest{Approved: ptr(plan.approved)}); err != nil {
+			t.Fatalf("set slot: %v", err)
+		}
+	}
+
+	// Два разработчика на три места и два руководителя на одно место: лишний
+	// руководитель не закрывает вакансию разработчика
+	for _, hire := range []struct {
+		department, position int64
+	}{
+		{backend, developer}, {backend, developer}, {backend, lead}, {backend, lead},
+	} {
+		if _, err := empSvc.Create(ctx, hire.department, &dto.CreateEmployeeRequest{FullName: "Employee", PositionID: &hire.position}); err != nil {
+			t.Fatalf("create employee: %v", err)
+		}
+	}
+
+	vacancies, err := svc.GetVacancies(ctx, company)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vacancies.Approved != 5 || vacancies.Filled != 4 || vacancies.Open != 2 {
+		t.Errorf("expected 5 approved, 4 filled, 2 open, got %+v", vacancies)
+	}
+	if len(vacancies.Slots) != 3 {
+		t.Fatalf("expected 3 slots, got %+v", vacancies.Slots)
+	}
+	first := vacancies.Slots[0]
+	if first.DepartmentID != it || first.PositionTitle != "Team Lead" || first.Filled != 0 || first.Open() != 1 {
+		t.Errorf("unexpected first slot: %+v", first)
+	}
+
+	vacancies, err = svc.GetVacancies(ctx, backend)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vacancies.Approved != 4 || vacancies.Open != 1 {
+		t.Errorf("expected backend subtree to have 4 approved and 1 open, got %+v", vacancies)
+	}
+
+	if _, err := svc.GetVacancies(ctx, 99); !errors.Is(err, domain.ErrDepartmentNotFound) {
+		t.Errorf("expected department not found, got %v", err)
+	}
+}
+
+func TestSetSlot_Validates(t *testing.T) {
+	store := newMemStore()
+	it := store.addDepartment("IT", nil)
+	developer := addPosition(t, store, "Developer")
+	svc := newHeadcountService(store)
+	ctx := context.Background()
+
+	if _, err := svc.SetSlot(ctx, 99, developer, &dto.SetHeadcountSlotRequest{Approved: ptr(1)}); !errors.Is(err, domain.ErrDepartmentNotFound) {
+		t.Errorf("expected department not found, got %v", err)
+	}
+	if _, err := svc.SetSlot(ctx, it, 99, &dto.SetHeadcountSlotRequest{Approved: ptr(1)}); !errors.Is(err, domain.ErrPositionNotFound) {
+		t.Errorf("expected position not found, got %v", err)
+	}
+
+	if _, err := svc.SetSlot(ctx, it, developer, &dto.SetHeadcountSlotRequest{Approved: ptr(1)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slot, err := svc.SetSlot(ctx, it, developer, &dto.SetHeadcountSlotRequest{Approved: ptr(4)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slot.Approved != 4 || slot.PositionTitle != "Developer" || len(store.headcount) != 1 {
+		t.Errorf("expected slot to be updated in place, got %+v", slot)
+	}
+
+	if err := svc.RemoveSlot(ctx, it, developer); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.RemoveSlot(ctx, it, developer); !errors.Is(err, domain.ErrHeadcountSlotNotFound) {
+		t.Errorf("expected slot not found, got %v", err)
+	}
+}
+
+func TestCreateEmployee_StrictHeadcount(t *testing.T) {
+	store := newMemStore()
+	it := store.addDepartment("IT", nil)
+	hr := store.addDepartment("HR", nil)
+	developer := addPosition(t, store, "Developer")
+	tester := addPosition(t, store, "Tester")
+
+	ctx := context.Background()
+	if _, err := newHeadcountService(store).SetSlot(ctx, it, developer, &dto.SetHeadcountSlotRequest{Approved: ptr(1)}); err != nil {
+		t.Fatalf("set slot: %v", err)
+	}
+
+	svc := newStrictEmployeeService(store)
+	hire := func(department, position int64) error {
+		_, err := svc.Create(ctx, department, &dto.CreateEmployeeRequest{FullName: "Employee", PositionID: &position})
+		return err
+	}
+
+	if err := hire(it, developer); err != nil {
+		t.Fatalf("expected hire into open slot, got %v", err)
+	}
+	if err := hire(it, developer); !errors.Is(err, domain.ErrHeadcountExhausted) {
+		t.Errorf("expected exhausted headcount, got %v", err)
+	}
+	// Должность без мест в плане подразделения тоже не принимается
+	if err := hire(it, tester); !errors.Is(err, domain.ErrHeadcountExhausted) {
+		t.Errorf("expected exhausted headcount for unplanned position, got %v", err)
+	}
+	// Подразделение без плана не ограничено
+	if err := hire(hr, tester); err != nil {
+		t.Errorf("expected hire into unplanned department, got %v", err)
+	}
+
+	// Без строгого контроля сверхштатный приём разрешён
+	lenient, _ := newEmployeeService(store)
+	if _, err := lenient.Create(ctx, it, &dto.CreateEmployeeRequest{FullName: "Extra", PositionID: &developer}); err != nil {
+		t.Errorf("expected lenient hire, got %v", err)
+	}
+}
+
+func TestStrictHeadcount_CoversMovesAndPositionChanges(t *testing.T) {
+	store := newMemStore()
+	it := store.addDepartment("IT", nil)
+	hr := store.addDepartment("HR", nil)
+	sales := store.addDepartment("Sales", nil)
+	developer := addPosition(t, store, "Developer")
+	tester := addPosition(t, store, "Tester")
+
+	ctx := context.Background()
+	if _, err := newHeadcountService(store).SetSlot(ctx, it, developer, &dto.SetHeadcountSlotRequest{Approved: ptr(1)}); err != nil {
+		t.Fatalf("set slot: %v", err)
+	}
+	staff := func(department int64, name string) int64 {
+		id := store.addEmployee(department, name)
+		emp := store.employees[id]
+		emp.PositionID = &developer
+		store.employees[id] = emp
+		return id
+	}
+	anna, bob := staff(hr, "Anna"), staff(hr, "Bob")
+	staff(sales, "Carl")
+
+	svc := newStrictEmployeeService(store)
+	if _, err := svc.Transfer(ctx, anna, &dto.TransferEmployeeRequest{DepartmentID: it}); err != nil {
+		t.Fatalf("expected transfer into open slot, got %v", err)
+	}
+	if _, err := svc.Transfer(ctx, bob, &dto.TransferEmployeeRequest{DepartmentID: it}); !errors.Is(err, domain.ErrHeadcountExhausted) {
+		t.Errorf("expected exhausted headcount on transfer, got %v", err)
+	}
+	if _, err := svc.Update(ctx, anna, &dto.UpdateEmployeeRequest{PositionID: &tester}); !errors.Is(err, domain.ErrHeadcountExhausted) {
+		t.Errorf("expected exhausted headcount on position change, got %v", err)
+	}
+
+	repos := store.repositories()
+	strict := service.EmployeePolicy{StrictHeadcount: true}
+	departments := service.NewDepartmentService(repos.Departments, repos.Employees, &memTemporalRepo{store: store}, &memTxManager{store: store}, strict)
+	drafts := service.NewDraftService(repos.Drafts, departments, svc, &memTxManager{store: store})
+	draft, err := drafts.Create(ctx, &dto.CreateDraftRequest{Name: "Move Bob"})
+	if err != nil {
+		t.Fatalf("create draft: %v", err)
+	}
+	if _, err := drafts.AddOperation(ctx, draft.ID, &dto.DraftOperationRequest{Type: domain.DraftOperationTransfer, EmployeeID: &bob, DepartmentID: &it}); err != nil {
+		t.Fatalf("add operation: %v", err)
+	}
+	if _, err := drafts.Apply(ctx, draft.ID); !errors.Is(err, domain.ErrHeadcountExhausted) {
+		t.Errorf("expected exhausted headcount on draft transfer, got %v", err)
+	}
+
+	if _, err := departments.Merge(ctx, sales, &dto.MergeDepartmentRequest{TargetDepartmentID: it}); !errors.Is(err, domain.ErrHeadcountExhausted) {
+		t.Errorf("expected exhausted headcount on merge, got %v", err)
+	}
+	err = departments.Delete(ctx, hr, &dto.DeleteDepartmentQuery{Mode: "reassign", ReassignToDepartmentID: &it})
+	if !errors.Is(err, domain.ErrHeadcountExhausted) {
+		t.Errorf("expected exhausted headcount on reassign, got %v", err)
+	}
+
+	if store.employees[bob].DepartmentID != hr || len(store.departments) != 3 {
+		t.Errorf("rejected moves must not change the structure")
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/repository"
+)
+
+// ManagementService определяет интерфейс запросов по линии подчинения
+type ManagementService interface {
+	GetManagers(ctx context.Context, employeeID int64) ([]domain.Manager, error)
+	GetReports(ctx context.Context, employeeID int64, query *dto.ReportsQuery) ([]domain.Report, error)
+	// GetSpanOfControl возвращает руководителей с числом прямых подчинённых больше порога
+	// и сам применённый порог
+	GetSpanOfControl(ctx context.Context, query *dto.SpanOfControlQuery) ([]domain.SpanOfControl, int, error)
+}
+
+type managementService struct {
+	empRepo        repository.EmployeeRepository
+	deptRepo       repository.DepartmentRepository
+	managementRepo repository.ManagementRepository
+	delegationRepo repository.DelegationRepository
+	// spanThreshold - порог отчёта о числе подчинённых по умолчанию
+	spanThreshold int
+}
+
+// NewManagementService создаёт новый экземпляр сервиса
+func NewManagementService(
+	empRepo repository.EmployeeRepository,
+	deptRepo repository.DepartmentRepository,
+	managementRepo repository.ManagementRepository,
+	delegationRepo repository.DelegationRepository,
+	spanThreshold int,
+) ManagementService {
+	return &managementService{
+		empRepo:        empRepo,
+		deptRepo:       deptRepo,
+		managementRepo: managementRepo,
+		delegationRepo: delegationRepo,
+		spanThreshold:  spanThreshold,
+	}
+}
+
+// GetManagers возвращает цепочку руководителей сотрудника от ближайшего к высшему:
+// руководителей его подразделения и всех предков. Руководителя, передавшего полномочия
+// действующим делегированием, заменяет заместитель. Подразделения без руководителя,
+// сам сотрудник и руководитель нескольких подразделений подряд пропускаются
+func (s *managementService) GetManagers(ctx context.Context, employeeID int64) ([]domain.Manager, error) {
+	emp, err := s.empRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := s.deptRepo.GetAncestors(ctx, emp.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	chainIDs := make([]int64, len(chain))
+	heads := make(map[int64]int64, len(chain))
+	for i, dept := range chain {
+		chainIDs[i] = dept.ID
+		if dept.HeadEmployeeID != nil {
+			heads[dept.ID] = *dept.HeadEmployeeID
+		}
+	}
+	active, err := s.delegationRepo.GetActive(ctx, chainIDs, today())
+	if err != nil {
+		return nil, err
+	}
+	// Делегирование прежнего руководителя после его смены не действует, поэтому
+	// учитываются только делегирования текущих руководителей
+	delegations := make(map[int64]domain.Delegation, len(active))
+	for _, delegation := range active {
+		if head, ok := heads[delegation.DepartmentID]; ok && head == delegation.DelegatorID {
+			delegations[delegation.DepartmentID] = delegation
+		}
+	}
+
+	var managers []domain.Manager
+	var managerIDs []int64
+	last := emp.ID
+	for _, dept := range slices.Backward(chain) {
+		if dept.HeadEmployeeID == nil {
+			continue
+		}
+
+		manager := domain.Manager{Department: dept}
+		managerID := *dept.HeadEmployeeID
+		if delegation, ok := delegations[dept.ID]; ok {
+			manager.Delegation = &delegation
+			managerID = delegation.DelegateID
+		}
+
+		if managerID == emp.ID || managerID == last {
+			continue
+		}
+		last = managerID
+		managers = append(managers, manager)
+		managerIDs = append(managerIDs, managerID)
+	}
+	if len(managerIDs) == 0 {
+		return []domain.Manager{}, nil
+	}
+
+	byID, err := s.employeesByID(ctx, managerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Manager, 0, len(managers))
+	for i, manager := range managers {
+		if employee, ok := byID[managerIDs[i]]; ok {
+			manager.Employee = employee
+			result = append(result, manager)
+		}
+	}
+	return result, nil
+}
+
+// GetReports возвращает подчинённых сотрудника: членов подразделений, которыми он руководит,
+// и руководителей их дочерних подразделений. С Recursive - также всех подчинённых ниже
+func (s *managementService) GetReports(ctx context.Context, employeeID int64, query *dto.ReportsQuery) ([]domain.Report, error) {
+	if _, err := s.empRepo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+
+	maxDepth := 1
+	if query.Recursive {
+		maxDepth = 0
+	}
+	lines, err := s.managementRepo.GetReports(ctx, employeeID, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(lines))
+	for i, line := range lines {
+		ids[i] = line.EmployeeID
+	}
+	byID, err := s.employeesByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]domain.Report, 0, len(lines))
+	for _, line := range lines {
+		if emp, ok := byID[line.EmployeeID]; ok {
+			reports = append(reports, domain.Report{Employee: emp, ManagerID: line.ManagerID, Depth: line.Depth})
+		}
+	}
+	return reports, nil
+}
+
+func (s *managementService) GetSpanOfControl(ctx context.Context, query *dto.SpanOfControlQuery) ([]domain.SpanOfControl, int, error) {
+	threshold := s.spanThreshold
+	if query.Threshold != nil {
+		threshold = *query.Threshold
+	}
+
+	spans, err := s.managementRepo.GetSpans(ctx, threshold)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]int64, len(spans))
+	for i, span := range spans {
+		ids[i] = span.ManagerID
+	}
+	byID, err := s.employeesByID(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]domain.SpanOfControl, 0, len(spans))
+	for _, span := range spans {
+		if manager, ok := byID[span.ManagerID]; ok {
+			result = append(result, domain.SpanOfControl{Manager: manager, DirectReports: span.DirectReports})
+		}
+	}
+	return result, threshold, nil
+}
+
+// employeesByID загружает сотрудников с указанными ID
+func (s *managementService) employeesByID(ctx context.Context, ids []int64) (map[int64]domain.Employee, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	employees, err := s.empRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]domain.Employee, len(employees))
+	for _, emp := range employees {
+		byID[emp.ID] = emp
+	}
+	return byID, nil
+}
@@ -0,0 +1,278 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func newManagementService(store *memStore) service.ManagementService {
+	repos := store.repositories()
+	return service.NewManagementService(repos.Employees, repos.Departments, &memManagementRepo{store: store}, repos.Delegations, 3)
+}
+
+func TestGetManagers_WalksDepartmentChain(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	office := store.addDepartment("Office", &root)
+	sales := store.addDepartment("Sales", &office)
+	team := store.addDepartment("Team", &sales)
+	ceo := store.addEmployee(root, "Anna")
+	lead := store.addEmployee(team, "Bob")
+	john := store.addEmployee(team, "John")
+
+	deptSvc, _ := newDepartmentService(store)
+	ctx := context.Background()
+	for dept, head := range map[int64]int64{root: ceo, office: ceo, team: lead} {
+		if _, err := deptSvc.SetHead(ctx, dept, &dto.SetDepartmentHeadRequest{EmployeeID: head}); err != nil {
+			t.Fatalf("set head: %v", err)
+		}
+	}
+
+	svc := newManagementService(store)
+
+	// Sales без руководителя пропускается, Anna руководит двумя уровнями подряд
+	managers, err := svc.GetManagers(ctx, john)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(managers) != 2 || managers[0].Employee.ID != lead || managers[0].Department.ID != team ||
+		managers[1].Employee.ID != ceo || managers[1].Department.ID != office {
+		t.Fatalf("unexpected managers: %+v", managers)
+	}
+
+	// Руководитель подразделения подчиняется руководителям выше
+	managers, err = svc.GetManagers(ctx, lead)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(managers) != 1 || managers[0].Employee.ID != ceo {
+		t.Fatalf("expected Anna above the team lead, got %+v", managers)
+	}
+
+	managers, err = svc.GetManagers(ctx, ceo)
+	if err != nil || len(managers) != 0 {
+		t.Fatalf("expected no managers for the CEO, got %+v, %v", managers, err)
+	}
+
+	if _, err := svc.GetManagers(ctx, 99); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("expected employee not found, got %v", err)
+	}
+}
+
+// newReportingLines строит структуру:
+//
+//	Company (Anna: Anna, Bob)
+//	├── Sales (Bob: Carl, Dina)
+//	│   └── Team (без руководителя: Eve)
+//	│       └── Squad (Fay: Fay, Gus)
+//	└── Ops (без руководителя: Hal)
+func newReportingLines(t *testing.T, store *memStore) map[string]int64 {
+	company := store.addDepartment("Company", nil)
+	sales := store.addDepartment("Sales", &company)
+	team := store.addDepartment("Team", &sales)
+	squad := store.addDepartment("Squad", &team)
+	ops := store.addDepartment("Ops", &company)
+
+	ids := make(map[string]int64)
+	for name, dept := range map[string]int64{
+		"Anna": company, "Bob": company, "Carl": sales, "Dina": sales,
+		"Eve": team, "Fay": squad, "Gus": squad, "Hal": ops,
+	} {
+		ids[name] = store.addEmployee(dept, name)
+	}
+
+	deptSvc, _ := newDepartmentService(store)
+	for dept, head := range map[int64]string{company: "Anna", sales: "Bob", squad: "Fay"} {
+		if _, err := deptSvc.SetHead(context.Background(), dept, &dto.SetDepartmentHeadRequest{EmployeeID: ids[head]}); err != nil {
+			t.Fatalf("set head: %v", err)
+		}
+	}
+	return ids
+}
+
+func TestGetReports_DirectAndRecursive(t *testing.T) {
+	store := newMemStore()
+	ids := newReportingLines(t, store)
+	svc := newManagementService(store)
+	ctx := context.Background()
+
+	names := func(reports []domain.Report) []string {
+		var result []string
+		for _, report := range reports {
+			result = append(result, report.Employee.FullName+":"+strconv.Itoa(report.Depth))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		manager   string
+		recursive bool
+		want      []string
+	}{
+		// Руководитель дочернего подразделения и члены подразделения без руководителя - прямые подчинённые
+		{"direct", "Anna", false, []string{"Bob:1", "Hal:1"}},
+		{"recursive", "Anna", true, []string{"Bob:1", "Hal:1", "Carl:2", "Dina:2", "Eve:2", "Fay:2", "Gus:3"}},
+		{"middle manager", "Bob", false, []string{"Carl:1", "Dina:1", "Eve:1", "Fay:1"}},
+		{"not a manager", "Gus", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports, err := svc.GetReports(ctx, ids[tt.manager], &dto.ReportsQuery{Recursive: tt.recursive})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := names(reports); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	reports, err := svc.GetReports(ctx, ids["Anna"], &dto.ReportsQuery{Recursive: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gus := reports[len(reports)-1]; gus.ManagerID != ids["Fay"] {
+		t.Errorf("expected Gus to report to Fay, got %d", gus.ManagerID)
+	}
+
+	if _, err := svc.GetReports(ctx, 99, &dto.ReportsQuery{}); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("expected employee not found, got %v", err)
+	}
+}
+
+func TestGetSpanOfControl_Threshold(t *testing.T) {
+	store := newMemStore()
+	ids := newReportingLines(t, store)
+	svc := newManagementService(store)
+	ctx := context.Background()
+
+	// Порог по умолчанию - 3: в отчёт попадает только Bob с четырьмя подчинёнными
+	spans, threshold, err := svc.GetSpanOfControl(ctx, &dto.SpanOfControlQuery{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if threshold != 3 || len(spans) != 1 || spans[0].Manager.ID != ids["Bob"] || spans[0].DirectReports != 4 {
+		t.Fatalf("unexpected report: threshold %d, %+v", threshold, spans)
+	}
+
+	spans, threshold, err = svc.GetSpanOfControl(ctx, &dto.SpanOfControlQuery{Threshold: ptr(0)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, span := range spans {
+		got = append(got, span.Manager.FullName+":"+strconv.Itoa(span.DirectReports))
+	}
+	if want := []string{"Bob:4", "Anna:2", "Fay:1"}; threshold != 0 || !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestGetManagers_HonorsActiveDelegation(t *testing.T) {
+	store := newMemStore()
+	ids := newReportingLines(t, store)
+	svc := newManagementService(store)
+	delegations := newDelegationService(store)
+	ctx := context.Background()
+
+	sales := store.employees[ids["Carl"]].DepartmentID
+	create := func(delegate int64, from, to int) *domain.Delegation {
+		t.Helper()
+		delegation, err := delegations.Create(ctx, &dto.CreateDelegationRequest{
+			DepartmentID: sales, DelegatorID: ids["Bob"], DelegateID: delegate,
+			StartsOn: dateAfter(from), EndsOn: dateAfter(to),
+		})
+		if err != nil {
+			t.Fatalf("create delegation: %v", err)
+		}
+		return delegation
+	}
+	managerNames := func(employee string) []string {
+		t.Helper()
+		managers, err := svc.GetManagers(ctx, ids[employee])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var names []string
+		for _, manager := range managers {
+			names = append(names, manager.Employee.FullName)
+		}
+		return names
+	}
+
+	// Будущее делегирование ещё не действует
+	create(ids["Dina"], 1, 7)
+	if got := managerNames("Carl"); !slices.Equal(got, []string{"Bob", "Anna"}) {
+		t.Fatalf("expected Bob, Anna, got %v", got)
+	}
+
+	current := create(ids["Dina"], 0, 0)
+	managers, err := svc.GetManagers(ctx, ids["Carl"])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(managers) != 2 || managers[0].Employee.ID != ids["Dina"] || managers[0].Delegation == nil || managers[0].Delegation.ID != current.ID {
+		t.Fatalf("expected Dina acting for Bob, got %+v", managers)
+	}
+
+	// Заместитель не руководит сам собой: его руководитель - следующий уровень
+	if got := managerNames("Dina"); !slices.Equal(got, []string{"Anna"}) {
+		t.Errorf("expected Anna above the deputy, got %v", got)
+	}
+
+	if _, err := delegations.Revoke(ctx, current.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if got := managerNames("Carl"); !slices.Equal(got, []string{"Bob", "Anna"}) {
+		t.Errorf("expected Bob back after revocation, got %v", got)
+	}
+
+	// Делегирование прежнего руководителя не действует после его смены
+	create(ids["Dina"], 0, 0)
+	deptSvc, _ := newDepartmentService(store)
+	if _, err := deptSvc.SetHead(ctx, sales, &dto.SetDepartmentHeadRequest{EmployeeID: ids["Anna"]}); err != nil {
+		t.Fatalf("set head: %v", err)
+	}
+	if got := managerNames("Carl"); !slices.Equal(got, []string{"Anna"}) {
+		t.Errorf("expected only Anna after the head change, got %v", got)
+	}
+}
+
+func TestGetManagers_IgnoresFormerHeadDelegation(t *testing.T) {
+	store := newMemStore()
+	ids := newReportingLines(t, store)
+	svc := newManagementService(store)
+	ctx := context.Background()
+	sales := store.employees[ids["Carl"]].DepartmentID
+
+	current, err := newDelegationService(store).Create(ctx, &dto.CreateDelegationRequest{
+		DepartmentID: sales, DelegatorID: ids["Bob"], DelegateID: ids["Dina"],
+		StartsOn: dateAfter(0), EndsOn: dateAfter(7),
+	})
+	if err != nil {
+		t.Fatalf("create delegation: %v", err)
+	}
+	// Делегирование прежнего руководителя с большим ID, пересекающееся с текущим
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	store.delegations = append(store.delegations, domain.Delegation{
+		ID: int64(len(store.delegations) + 1), DepartmentID: sales, DelegatorID: ids["Eve"], DelegateID: ids["Hal"],
+		StartsOn: today, EndsOn: today.AddDate(0, 0, 7), CreatedBy: "alice",
+	})
+
+	managers, err := svc.GetManagers(ctx, ids["Carl"])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(managers) == 0 || managers[0].Employee.ID != ids["Dina"] || managers[0].Delegation == nil || managers[0].Delegation.ID != current.ID {
+		t.Fatalf("expected Dina acting for Bob, got %+v", managers)
+	}
+}
@@ -0,0 +1,1400 @@
+package service_test
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+var errInjected = errors.New("injected failure")
+
+// memStore - in-memory состояние БД, которое копируется на время транзакции
+type memStore struct {
+	departments map[int64]domain.Department
+	employees   map[int64]domain.Employee
+	transfers   []domain.EmployeeTransfer
+	audit       []domain.AuditEntry
+	snapshots   []domain.Snapshot
+	// snapshotStates - содержимое снимков по ID
+	snapshotStates map[int64]domain.OrgState
+	// drafts - черновики реорганизации; срез операций копируется при изменении
+	drafts   map[int64]domain.Draft
+	nextOpID int64
+	// delegations - делегирования полномочий; ID равен позиции в срезе плюс один
+	delegations []domain.Delegation
+	// positions - справочник должностей
+	positions      map[int64]domain.Position
+	nextPositionID int64
+	// headcount - утверждённые места по подразделению и должности
+	headcount map[[2]int64]domain.HeadcountSlot
+
+	// versions и assignments - история подразделений и назначений для чтения на дату.
+	// Заполняются тестами, кроме назначений, которые пишут переводы
+	versions    []domain.DepartmentVersion
+	assignments []domain.EmployeeAssignment
+
+	// deletedDepartments и deletedEmployees - мягко удалённые записи
+	deletedDepartments map[int64]domain.Department
+	deletedEmployees   map[int64]domain.Employee
+
+	nextDeptID int64
+	nextEmpID  int64
+
+	// moveLocks и nameLocks фиксируют взятые блокировки
+	moveLocks [][2]int64
+	nameLocks []string
+
+	// failures задаёт ошибки, которые вернут методы репозиториев (ключ - "Repo.Method")
+	failures map[string]error
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		departments:        make(map[int64]domain.Department),
+		employees:          make(map[int64]domain.Employee),
+		deletedDepartments: make(map[int64]domain.Department),
+		deletedEmployees:   make(map[int64]domain.Employee),
+		snapshotStates:     make(map[int64]domain.OrgState),
+		drafts:             make(map[int64]domain.Draft),
+		positions:          make(map[int64]domain.Position),
+		nextPositionID:     1,
+		headcount:          make(map[[2]int64]domain.HeadcountSlot),
+		nextDeptID:         1,
+		nextEmpID:          1,
+		failures:           make(map[string]error),
+	}
+}
+
+func (s *memStore) clone() *memStore {
+	return &memStore{
+		departments:        maps.Clone(s.departments),
+		employees:          maps.Clone(s.employees),
+		transfers:          slices.Clone(s.transfers),
+		audit:              slices.Clone(s.audit),
+		snapshots:          slices.Clone(s.snapshots),
+		snapshotStates:     maps.Clone(s.snapshotStates),
+		drafts:             maps.Clone(s.drafts),
+		nextOpID:           s.nextOpID,
+		delegations:        slices.Clone(s.delegations),
+		positions:          maps.Clone(s.positions),
+		nextPositionID:     s.nextPositionID,
+		headcount:          maps.Clone(s.headcount),
+		versions:           slices.Clone(s.versions),
+		assignments:        slices.Clone(s.assignments),
+		deletedDepartments: maps.Clone(s.deletedDepartments),
+		deletedEmployees:   maps.Clone(s.deletedEmployees),
+		nextDeptID:         s.nextDeptID,
+		nextEmpID:          s.nextEmpID,
+		failures:           s.failures,
+		moveLocks:          slices.Clone(s.moveLocks),
+		nameLocks:          slices.Clone(s.nameLocks),
+	}
+}
+
+func (s *memStore) fail(method string) error {
+	return s.failures[method]
+}
+
+func (s *memStore) repositories() repository.Repositories {
+	return repository.Repositories{
+		Departments: &memDepartmentRepo{store: s},
+		Employees:   &memEmployeeRepo{store: s},
+		Transfers:   &memTransferRepo{store: s},
+		Audit:       &memAuditRepo{store: s},
+		Drafts:      &memDraftRepo{store: s},
+		Delegations: &memDelegationRepo{store: s},
+		Positions:   &memPositionRepo{store: s},
+		Headcount:   &memHeadcountRepo{store: s},
+	}
+}
+
+func (s *memStore) addDepartment(name string, parentID *int64) int64 {
+	id := s.nextDeptID
+	s.nextDeptID++
+	s.departments[id] = domain.Department{ID: id, Name: name, ParentID: parentID, CreatedAt: time.Now()}
+	return id
+}
+
+func (s *memStore) addEmployee(departmentID int64, fullName string) int64 {
+	id := s.nextEmpID
+	s.nextEmpID++
+	s.employees[id] = domain.Employee{ID: id, DepartmentID: departmentID, FullName: fullName, Position: "Dev", CreatedAt: time.Now()}
+	return id
+}
+
+// memTxManager выполняет функцию над копией состояния и подменяет
+// исходное состояние только при успешном завершении
+type memTxManager struct {
+	store     *memStore
+	commits   int
+	rollbacks int
+}
+
+func (m *memTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context, repos repository.Repositories) error) error {
+	tx := m.store.clone()
+	if err := fn(ctx, tx.repositories()); err != nil {
+		m.rollbacks++
+		return err
+	}
+	*m.store = *tx
+	m.commits++
+	return nil
+}
+
+type memDepartmentRepo struct {
+	store *memStore
+}
+
+func (r *memDepartmentRepo) Create(ctx context.Context, dept *domain.Department) error {
+	if err := r.store.fail("Departments.Create"); err != nil {
+		return err
+	}
+	dept.ID = r.store.nextDeptID
+	dept.CreatedAt = time.Now()
+	r.store.nextDeptID++
+	r.store.departments[dept.ID] = *dept
+	return nil
+}
+
+func (r *memDepartmentRepo) GetByID(ctx context.Context, id int64) (*domain.Department, error) {
+	dept, ok := r.store.departments[id]
+	if !ok {
+		return nil, domain.ErrDepartmentNotFound
+	}
+	return &dept, nil
+}
+
+func (r *memDepartmentRepo) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Department, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memDepartmentRepo) GetByIDWithChildren(ctx context.Context, id int64, depth int, includeEmployees bool) (*domain.Department, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memDepartmentRepo) Update(ctx context.Context, dept *domain.Department) error {
+	if err := r.store.fail("Departments.Update"); err != nil {
+		return err
+	}
+	r.store.departments[dept.ID] = *dept
+	return nil
+}
+
+func (r *memDepartmentRepo) Delete(ctx context.Context, id int64) error {
+	if err := r.store.fail("Departments.Delete"); err != nil {
+		return err
+	}
+	if _, ok := r.store.departments[id]; !ok {
+		return domain.ErrDepartmentNotFound
+	}
+	r.store.softDeleteDepartment(id, time.Now())
+	return nil
+}
+
+func (r *memDepartmentRepo) DeleteCascade(ctx context.Context, id int64) error {
+	if err := r.store.fail("Departments.DeleteCascade"); err != nil {
+		return err
+	}
+	if _, ok := r.store.departments[id]; !ok {
+		return domain.ErrDepartmentNotFound
+	}
+
+	deletedAt := time.Now()
+	descendants, _ := r.GetAllDescendantIDs(ctx, id)
+	for _, deptID := range append(descendants, id) {
+		for empID, emp := range r.store.employees {
+			if emp.DepartmentID == deptID {
+				r.store.softDeleteEmployee(empID, deletedAt)
+			}
+		}
+		r.store.softDeleteDepartment(deptID, deletedAt)
+	}
+	return nil
+}
+
+func (r *memDepartmentRepo) GetWithDeletedForUpdate(ctx context.Context, id int64) (*domain.Department, error) {
+	if dept, ok := r.store.departments[id]; ok {
+		return &dept, nil
+	}
+	if dept, ok := r.store.deletedDepartments[id]; ok {
+		return &dept, nil
+	}
+	return nil, domain.ErrDepartmentNotFound
+}
+
+func (r *memDepartmentRepo) Restore(ctx context.Context, id int64, deletedAt time.Time) ([]domain.Department, []domain.Employee, error) {
+	if err := r.store.fail("Departments.Restore"); err != nil {
+		return nil, nil, err
+	}
+
+	// Подразделения поддерева id, удалённые вместе с ним
+	var departments []domain.Department
+	restored := make(map[int64]bool)
+	for deptID, dept := range r.store.deletedDepartments {
+		if dept.DeletedAt.Time.Equal(deletedAt) && r.store.isDeletedDescendant(deptID, id) {
+			restored[deptID] = true
+			departments = append(departments, dept)
+		}
+	}
+
+	for deptID := range restored {
+		dept := r.store.deletedDepartments[deptID]
+		dept.DeletedAt = gorm.DeletedAt{}
+		r.store.departments[deptID] = dept
+		delete(r.store.deletedDepartments, deptID)
+	}
+	var employees []domain.Employee
+	for empID, emp := range r.store.deletedEmployees {
+		if restored[emp.DepartmentID] && emp.DeletedAt.Time.Equal(deletedAt) {
+			employees = append(employees, emp)
+			emp.DeletedAt = gorm.DeletedAt{}
+			r.store.employees[empID] = emp
+			delete(r.store.deletedEmployees, empID)
+		}
+	}
+	slices.SortFunc(departments, func(a, b domain.Department) int { return cmp.Compare(a.ID, b.ID) })
+	slices.SortFunc(employees, func(a, b domain.Employee) int { return cmp.Compare(a.ID, b.ID) })
+	return departments, employees, nil
+}
+
+func (r *memDepartmentRepo) ListDeleted(ctx context.Context, filter repository.TrashFilter) ([]domain.Department, error) {
+	return listDeleted(slices.Collect(maps.Values(r.store.deletedDepartments)), filter,
+		func(dept domain.Department) (time.Time, int64) { return dept.DeletedAt.Time, dept.ID })
+}
+
+func (r *memDepartmentRepo) Purge(ctx context.Context, deletedBefore time.Time) ([]domain.Department, error) {
+	var purged []domain.Department
+	for id, dept := range r.store.deletedDepartments {
+		if dept.DeletedAt.Time.Before(deletedBefore) {
+			delete(r.store.deletedDepartments, id)
+			purged = append(purged, dept)
+		}
+	}
+	return purged, nil
+}
+
+func (r *memDepartmentRepo) ExistsByNameAndParent(ctx context.Context, name string, parentID *int64, excludeID *int64) (bool, error) {
+	for _, dept := range r.store.departments {
+		if dept.Name != name || !domain.SameParent(dept.ParentID, parentID) {
+			continue
+		}
+		if excludeID == nil || dept.ID != *excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memDepartmentRepo) IsDescendant(ctx context.Context, ancestorID, descendantID int64) (bool, error) {
+	ids, _ := r.GetAllDescendantIDs(ctx, ancestorID)
+	return slices.Contains(ids, descendantID), nil
+}
+
+func (r *memDepartmentRepo) GetAllDescendantIDs(ctx context.Context, id int64) ([]int64, error) {
+	var result []int64
+	for _, childID := range r.childIDs(id) {
+		result = append(result, childID)
+		nested, _ := r.GetAllDescendantIDs(ctx, childID)
+		result = append(result, nested...)
+	}
+	return result, nil
+}
+
+func (r *memDepartmentRepo) GetChildren(ctx context.Context, parentID int64) ([]domain.Department, error) {
+	var result []domain.Department
+	for _, id := range r.childIDs(parentID) {
+		result = append(result, r.store.departments[id])
+	}
+	return result, nil
+}
+
+func (r *memDepartmentRepo) DetachChildren(ctx context.Context, parentID int64) error {
+	for _, id := range r.childIDs(parentID) {
+		dept := r.store.departments[id]
+		dept.ParentID = nil
+		r.store.departments[id] = dept
+	}
+	return nil
+}
+
+func (r *memDepartmentRepo) LockForMove(ctx context.Context, id, newParentID int64) error {
+	r.store.moveLocks = append(r.store.moveLocks, [2]int64{id, newParentID})
+	return nil
+}
+
+func (r *memDepartmentRepo) LockName(ctx context.Context, name string, parentID *int64) error {
+	parent := "root"
+	if parentID != nil {
+		parent = strconv.FormatInt(*parentID, 10)
+	}
+	r.store.nameLocks = append(r.store.nameLocks, parent+":"+name)
+	return nil
+}
+
+func (r *memDepartmentRepo) GetAncestorIDs(ctx context.Context, id int64) ([]int64, error) {
+	var result []int64
+	for dept, ok := r.store.departments[id]; ok; {
+		result = append(result, dept.ID)
+		if dept.ParentID == nil {
+			break
+		}
+		dept, ok = r.store.departments[*dept.ParentID]
+	}
+	return result, nil
+}
+
+func (r *memDepartmentRepo) GetAncestors(ctx context.Context, id int64) ([]domain.Department, error) {
+	ids, _ := r.GetAncestorIDs(ctx, id)
+	if len(ids) == 0 {
+		return nil, domain.ErrDepartmentNotFound
+	}
+
+	chain := make([]domain.Department, 0, len(ids))
+	for _, ancestorID := range slices.Backward(ids) {
+		chain = append(chain, r.store.departments[ancestorID])
+	}
+	return chain, nil
+}
+
+func (r *memDepartmentRepo) GetForest(ctx context.Context, depth int, includeEmployees bool) ([]domain.Department, error) {
+	departments := make([]domain.Department, 0, len(r.store.departments))
+	for _, id := range slices.Sorted(maps.Keys(r.store.departments)) {
+		departments = append(departments, r.store.departments[id])
+	}
+	employees := slices.Collect(maps.Values(r.store.employees))
+	slices.SortFunc(employees, func(a, b domain.Employee) int { return cmp.Compare(a.ID, b.ID) })
+
+	var forest []domain.Department
+	for _, dept := range departments {
+		if dept.ParentID == nil {
+			tree, _ := buildSubtree(departments, employees, dept.ID, depth, includeEmployees)
+			forest = append(forest, *tree)
+		}
+	}
+	return forest, nil
+}
+
+func (r *memDepartmentRepo) ListRoots(ctx context.Context, filter repository.DepartmentFilter) ([]domain.Department, error) {
+	var result []domain.Department
+	for _, dept := range r.store.departments {
+		if dept.ParentID != nil || !strings.Contains(strings.ToLower(dept.Name), strings.ToLower(filter.Name)) {
+			continue
+		}
+		if after := filter.After; after != nil && (dept.Name < after.Value || dept.Name == after.Value && dept.ID <= after.ID) {
+			continue
+		}
+		result = append(result, dept)
+	}
+	slices.SortFunc(result, func(a, b domain.Department) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	if len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (r *memDepartmentRepo) childIDs(parentID int64) []int64 {
+	var result []int64
+	for id, dept := range r.store.departments {
+		if dept.ParentID != nil && *dept.ParentID == parentID {
+			result = append(result, id)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+type memEmployeeRepo struct {
+	store *memStore
+}
+
+func (r *memEmployeeRepo) Create(ctx context.Context, emp *domain.Employee) error {
+	if err := r.store.fail("Employees.Create"); err != nil {
+		return err
+	}
+	emp.ID = r.store.nextEmpID
+	emp.CreatedAt = time.Now()
+	r.store.nextEmpID++
+	r.store.employees[emp.ID] = *emp
+	return nil
+}
+
+func (r *memEmployeeRepo) GetByID(ctx context.Context, id int64) (*domain.Employee, error) {
+	emp, ok := r.store.employees[id]
+	if !ok {
+		return nil, domain.ErrEmployeeNotFound
+	}
+	return &emp, nil
+}
+
+func (r *memEmployeeRepo) GetByIDs(ctx context.Context, ids []int64) ([]domain.Employee, error) {
+	var result []domain.Employee
+	for _, id := range slices.Sorted(slices.Values(ids)) {
+		if emp, ok := r.store.employees[id]; ok {
+			result = append(result, emp)
+		}
+	}
+	return result, nil
+}
+
+func (r *memEmployeeRepo) GetByDepartmentID(ctx context.Context, departmentID int64) ([]domain.Employee, error) {
+	var result []domain.Employee
+	for _, emp := range r.store.employees {
+		if emp.DepartmentID == departmentID {
+			result = append(result, emp)
+		}
+	}
+	return result, nil
+}
+
+func (r *memEmployeeRepo) CountByDepartmentIDs(ctx context.Context, departmentIDs []int64) (int64, error) {
+	var count int64
+	for _, emp := range r.store.employees {
+		if slices.Contains(departmentIDs, emp.DepartmentID) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memEmployeeRepo) Update(ctx context.Context, emp *domain.Employee) error {
+	if err := r.store.fail("Employees.Update"); err != nil {
+		return err
+	}
+	r.store.employees[emp.ID] = *emp
+	return nil
+}
+
+func (r *memEmployeeRepo) Delete(ctx context.Context, id int64) error {
+	if _, ok := r.store.employees[id]; !ok {
+		return domain.ErrEmployeeNotFound
+	}
+	r.store.softDeleteEmployee(id, time.Now())
+	return nil
+}
+
+func (r *memEmployeeRepo) GetWithDeletedForUpdate(ctx context.Context, id int64) (*domain.Employee, error) {
+	if emp, ok := r.store.employees[id]; ok {
+		return &emp, nil
+	}
+	if emp, ok := r.store.deletedEmployees[id]; ok {
+		return &emp, nil
+	}
+	return nil, domain.ErrEmployeeNotFound
+}
+
+func (r *memEmployeeRepo) Restore(ctx context.Context, id int64) error {
+	emp, ok := r.store.deletedEmployees[id]
+	if !ok {
+		return nil
+	}
+	emp.DeletedAt = gorm.DeletedAt{}
+	r.store.employees[id] = emp
+	delete(r.store.deletedEmployees, id)
+	return nil
+}
+
+func (r *memEmployeeRepo) ListDeleted(ctx context.Context, filter repository.TrashFilter) ([]domain.Employee, error) {
+	return listDeleted(slices.Collect(maps.Values(r.store.deletedEmployees)), filter,
+		func(emp domain.Employee) (time.Time, int64) { return emp.DeletedAt.Time, emp.ID })
+}
+
+func (r *memEmployeeRepo) Purge(ctx context.Context, deletedBefore time.Time) ([]domain.Employee, error) {
+	if err := r.store.fail("Employees.Purge"); err != nil {
+		return nil, err
+	}
+	var purged []domain.Employee
+	for id, emp := range r.store.deletedEmployees {
+		if emp.DeletedAt.Time.Before(deletedBefore) {
+			delete(r.store.deletedEmployees, id)
+			purged = append(purged, emp)
+		}
+	}
+	return purged, nil
+}
+
+func (r *memEmployeeRepo) Transfer(ctx context.Context, emp *domain.Employee, effectiveDate time.Time) error {
+	for _, a := range r.store.assignments {
+		if a.EmployeeID == emp.ID && a.ValidFrom.After(effectiveDate) {
+			return domain.ErrEffectiveDateTooEarly
+		}
+	}
+	if err := r.Update(ctx, emp); err != nil {
+		return err
+	}
+	r.store.assign(emp.ID, emp.DepartmentID, effectiveDate)
+	return nil
+}
+
+func (r *memEmployeeRepo) ReassignToDepartment(ctx context.Context, fromDeptID, toDeptID int64, effectiveDate time.Time) error {
+	if err := r.store.fail("Employees.ReassignToDepartment"); err != nil {
+		return err
+	}
+	for id, emp := range r.store.employees {
+		if emp.DepartmentID == fromDeptID {
+			emp.DepartmentID = toDeptID
+			r.store.employees[id] = emp
+			r.store.assign(id, toDeptID, effectiveDate)
+		}
+	}
+	return nil
+}
+
+func (r *memEmployeeRepo) List(ctx context.Context, filter repository.EmployeeFilter) ([]domain.Employee, error) {
+	employees := slices.Collect(maps.Values(r.store.employees))
+	if filter.AsOf != nil {
+		employees = r.store.employeesAsOf(*filter.AsOf)
+	}
+
+	var result []domain.Employee
+	for _, emp := range employees {
+		if !slices.Contains(filter.DepartmentIDs, emp.DepartmentID) {
+			continue
+		}
+		if filter.Position != "" && !strings.Contains(strings.ToLower(emp.Position), strings.ToLower(filter.Position)) {
+			continue
+		}
+		if filter.PositionID != nil && (emp.PositionID == nil || *emp.PositionID != *filter.PositionID) {
+			continue
+		}
+		if filter.HiredFrom != nil && (emp.HiredAt == nil || emp.HiredAt.Before(*filter.HiredFrom)) {
+			continue
+		}
+		if filter.HiredTo != nil && (emp.HiredAt == nil || emp.HiredAt.After(*filter.HiredTo)) {
+			continue
+		}
+		result = append(result, emp)
+	}
+
+	compare := func(a, b domain.Employee) int {
+		c := cmp.Or(
+			cmp.Compare(repository.EmployeeSortValue(&a, filter.SortField), repository.EmployeeSortValue(&b, filter.SortField)),
+			cmp.Compare(a.ID, b.ID),
+		)
+		if filter.Desc {
+			return -c
+		}
+		return c
+	}
+	slices.SortFunc(result, compare)
+
+	if filter.After != nil {
+		result = slices.DeleteFunc(result, func(emp domain.Employee) bool {
+			c := cmp.Or(
+				cmp.Compare(repository.EmployeeSortValue(&emp, filter.SortField), filter.After.Value),
+				cmp.Compare(emp.ID, filter.After.ID),
+			)
+			return (!filter.Desc && c <= 0) || (filter.Desc && c >= 0)
+		})
+	}
+
+	if len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+type memTransferRepo struct {
+	store *memStore
+}
+
+func (r *memTransferRepo) Create(ctx context.Context, transfer *domain.EmployeeTransfer) error {
+	if err := r.store.fail("Transfers.Create"); err != nil {
+		return err
+	}
+	transfer.ID = int64(len(r.store.transfers) + 1)
+	r.store.transfers = append(r.store.transfers, *transfer)
+	return nil
+}
+
+func (r *memTransferRepo) CreateForDepartment(ctx context.Context, fromDeptID, toDeptID int64, effectiveDate time.Time) error {
+	for _, emp := range r.store.employees {
+		if emp.DepartmentID != fromDeptID {
+			continue
+		}
+		r.Create(ctx, &domain.EmployeeTransfer{
+			EmployeeID:       emp.ID,
+			FromDepartmentID: ptr(fromDeptID),
+			ToDepartmentID:   ptr(toDeptID),
+			EffectiveDate:    effectiveDate,
+		})
+	}
+	return nil
+}
+
+func (r *memTransferRepo) GetByEmployeeID(ctx context.Context, employeeID int64) ([]domain.EmployeeTransfer, error) {
+	var result []domain.EmployeeTransfer
+	for _, transfer := range r.store.transfers {
+		if transfer.EmployeeID == employeeID {
+			result = append(result, transfer)
+		}
+	}
+	return result, nil
+}
+
+func (s *memStore) softDeleteDepartment(id int64, deletedAt time.Time) {
+	dept := s.departments[id]
+	dept.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+	s.deletedDepartments[id] = dept
+	delete(s.departments, id)
+}
+
+func (s *memStore) softDeleteEmployee(id int64, deletedAt time.Time) {
+	emp := s.employees[id]
+	emp.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+	s.deletedEmployees[id] = emp
+	delete(s.employees, id)
+
+	// Как триггер в БД, снимаем удалённого сотрудника с руководства
+	for deptID, dept := range s.departments {
+		if dept.HeadEmployeeID != nil && *dept.HeadEmployeeID == id {
+			dept.HeadEmployeeID = nil
+			s.departments[deptID] = dept
+		}
+	}
+}
+
+// isDeletedDescendant проверяет, что удалённое подразделение id совпадает с ancestorID
+// или лежит в его поддереве, поднимаясь только по удалённым подразделениям
+func (s *memStore) isDeletedDescendant(id, ancestorID int64) bool {
+	for dept, ok := s.deletedDepartments[id]; ok; dept, ok = s.deletedDepartments[*dept.ParentID] {
+		if dept.ID == ancestorID {
+			return true
+		}
+		if dept.ParentID == nil {
+			break
+		}
+	}
+	return false
+}
+
+// listDeleted сортирует удалённые записи по (deleted_at, id) по убыванию и применяет пагинацию
+func listDeleted[T any](items []T, filter repository.TrashFilter, key func(T) (time.Time, int64)) ([]T, error) {
+	compare := func(a, b T) int {
+		aAt, aID := key(a)
+		bAt, bID := key(b)
+		return cmp.Or(bAt.Compare(aAt), cmp.Compare(bID, aID))
+	}
+	slices.SortFunc(items, compare)
+
+	if filter.After != nil {
+		after, err := time.Parse(time.RFC3339Nano, filter.After.Value)
+		if err != nil {
+			return nil, domain.ErrInvalidCursor
+		}
+		items = slices.DeleteFunc(items, func(item T) bool {
+			at, id := key(item)
+			return cmp.Or(at.Compare(after), cmp.Compare(id, filter.After.ID)) >= 0
+		})
+	}
+
+	if len(items) > filter.Limit {
+		items = items[:filter.Limit]
+	}
+	return items, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+type memAuditRepo struct {
+	store *memStore
+}
+
+func (r *memAuditRepo) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	if err := r.store.fail("Audit.Create"); err != nil {
+		return err
+	}
+	entry.ID = int64(len(r.store.audit) + 1)
+	entry.OccurredAt = time.Now()
+	r.store.audit = append(r.store.audit, *entry)
+	return nil
+}
+
+func (r *memAuditRepo) List(ctx context.Context, filter repository.AuditFilter) ([]domain.AuditEntry, error) {
+	var result []domain.AuditEntry
+	for _, entry := range slices.Backward(r.store.audit) {
+		switch {
+		case filter.Entity != "" && entry.Entity != filter.Entity,
+			filter.EntityID != nil && entry.EntityID != *filter.EntityID,
+			filter.From != nil && entry.OccurredAt.Before(*filter.From),
+			filter.To != nil && !entry.OccurredAt.Before(*filter.To),
+			filter.After != nil && entry.ID >= filter.After.ID:
+			continue
+		}
+		if len(result) == filter.Limit {
+			break
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// assign начинает с даты date назначение сотрудника в подразделение
+func (s *memStore) assign(employeeID, departmentID int64, date time.Time) {
+	for i := range s.assignments {
+		if a := &s.assignments[i]; a.EmployeeID == employeeID && a.ValidTo == nil {
+			a.ValidTo = &date
+		}
+	}
+	s.assignments = append(s.assignments, domain.EmployeeAssignment{
+		ID:           int64(len(s.assignments) + 1),
+		EmployeeID:   employeeID,
+		DepartmentID: departmentID,
+		ValidFrom:    date,
+	})
+}
+
+// addVersion добавляет версию подразделения, действующую в [from, to)
+func (s *memStore) addVersion(departmentID int64, name string, parentID *int64, from time.Time, to *time.Time) {
+	s.versions = append(s.versions, domain.DepartmentVersion{
+		ID:           int64(len(s.versions) + 1),
+		DepartmentID: departmentID,
+		Name:         name,
+		ParentID:     parentID,
+		ValidFrom:    from,
+		ValidTo:      to,
+	})
+}
+
+func activeAt(from time.Time, to *time.Time, asOf time.Time) bool {
+	return !from.After(asOf) && (to == nil || to.After(asOf))
+}
+
+// departmentsAsOf возвращает подразделения в состоянии на дату, упорядоченные по ID
+func (s *memStore) departmentsAsOf(asOf time.Time) []domain.Department {
+	var result []domain.Department
+	for _, v := range s.versions {
+		if activeAt(v.ValidFrom, v.ValidTo, asOf) {
+			result = append(result, domain.Department{ID: v.DepartmentID, Name: v.Name, ParentID: v.ParentID})
+		}
+	}
+	slices.SortFunc(result, func(a, b domain.Department) int { return cmp.Compare(a.ID, b.ID) })
+	return result
+}
+
+// employeesAsOf возвращает сотрудников (включая удалённых) с подразделениями на дату
+func (s *memStore) employeesAsOf(asOf time.Time) []domain.Employee {
+	var result []domain.Employee
+	for _, a := range s.assignments {
+		if !activeAt(a.ValidFrom, a.ValidTo, asOf) {
+			continue
+		}
+		emp, ok := s.employees[a.EmployeeID]
+		if !ok {
+			emp = s.deletedEmployees[a.EmployeeID]
+		}
+		emp.DepartmentID = a.DepartmentID
+		emp.DeletedAt = gorm.DeletedAt{}
+		result = append(result, emp)
+	}
+	return result
+}
+
+type memTemporalRepo struct {
+	store *memStore
+}
+
+func (r *memTemporalRepo) subtree(id int64, depth int, includeEmployees bool, asOf time.Time) (*domain.Department, bool) {
+	return buildSubtree(r.store.departmentsAsOf(asOf), r.store.employeesAsOf(asOf), id, depth, includeEmployees)
+}
+
+// buildSubtree собирает поддерево id глубиной depth из плоских списков
+func buildSubtree(departments []domain.Department, employees []domain.Employee, id int64, depth int, includeEmployees bool) (*domain.Department, bool) {
+	var build func(dept domain.Department, depth int) domain.Department
+	build = func(dept domain.Department, depth int) domain.Department {
+		if includeEmployees {
+			for _, emp := range employees {
+				if emp.DepartmentID == dept.ID {
+					dept.Employees = append(dept.Employees, emp)
+				}
+			}
+		}
+		if depth == 0 {
+			return dept
+		}
+		for _, child := range departments {
+			if child.ParentID != nil && *child.ParentID == dept.ID {
+				dept.Children = append(dept.Children, build(child, depth-1))
+			}
+		}
+		return dept
+	}
+
+	for _, dept := range departments {
+		if dept.ID == id {
+			tree := build(dept, depth)
+			return &tree, true
+		}
+	}
+	return nil, false
+}
+
+func (r *memTemporalRepo) GetSubtree(ctx context.Context, id int64, depth int, includeEmployees bool, asOf time.Time) (*domain.Department, error) {
+	tree, ok := r.subtree(id, depth, includeEmployees, asOf)
+	if !ok {
+		return nil, domain.ErrDepartmentNotFound
+	}
+	return tree, nil
+}
+
+func (r *memTemporalRepo) GetForest(ctx context.Context, depth int, includeEmployees bool, asOf time.Time) ([]domain.Department, error) {
+	var forest []domain.Department
+	for _, dept := range r.store.departmentsAsOf(asOf) {
+		if dept.ParentID == nil {
+			tree, _ := r.subtree(dept.ID, depth, includeEmployees, asOf)
+			forest = append(forest, *tree)
+		}
+	}
+	return forest, nil
+}
+
+func (r *memTemporalRepo) GetAncestors(ctx context.Context, id int64, asOf time.Time) ([]domain.Department, error) {
+	byID := make(map[int64]domain.Department)
+	for _, dept := range r.store.departmentsAsOf(asOf) {
+		byID[dept.ID] = dept
+	}
+
+	var chain []domain.Department
+	for current, ok := byID[id]; ok; {
+		chain = append([]domain.Department{current}, chain...)
+		if current.ParentID == nil {
+			break
+		}
+		current, ok = byID[*current.ParentID]
+	}
+	if len(chain) == 0 {
+		return nil, domain.ErrDepartmentNotFound
+	}
+	return chain, nil
+}
+
+func (r *memTemporalRepo) GetSubtreeIDs(ctx context.Context, id int64, asOf time.Time) ([]int64, error) {
+	tree, ok := r.subtree(id, 10_000, false, asOf)
+	if !ok {
+		return nil, domain.ErrDepartmentNotFound
+	}
+
+	var ids []int64
+	var walk func(dept *domain.Department)
+	walk = func(dept *domain.Department) {
+		ids = append(ids, dept.ID)
+		for i := range dept.Children {
+			walk(&dept.Children[i])
+		}
+	}
+	walk(tree)
+	return ids, nil
+}
+
+func (r *memTemporalRepo) GetEmployee(ctx context.Context, id int64, asOf time.Time) (*domain.Employee, error) {
+	for _, emp := range r.store.employeesAsOf(asOf) {
+		if emp.ID == id {
+			return &emp, nil
+		}
+	}
+	return nil, domain.ErrEmployeeNotFound
+}
+
+func (r *memTemporalRepo) GetState(ctx context.Context, asOf time.Time) (*domain.OrgState, error) {
+	return &domain.OrgState{
+		Departments: r.store.departmentsAsOf(asOf),
+		Employees:   r.store.employeesAsOf(asOf),
+	}, nil
+}
+
+type memSnapshotRepo struct {
+	store *memStore
+}
+
+func (r *memSnapshotRepo) Create(ctx context.Context, snap *domain.Snapshot) error {
+	snap.ID = int64(len(r.store.snapshots) + 1)
+	snap.Version = 1
+	snap.CreatedAt = time.Now()
+	for _, other := range r.store.snapshots {
+		if other.Name == snap.Name {
+			snap.Version = other.Version + 1
+		}
+	}
+
+	var state domain.OrgState
+	for _, dept := range r.store.departments {
+		state.Departments = append(state.Departments, domain.Department{ID: dept.ID, Name: dept.Name, ParentID: dept.ParentID, CreatedAt: dept.CreatedAt})
+	}
+	for _, emp := range r.store.employees {
+		state.Employees = append(state.Employees, emp)
+	}
+	slices.SortFunc(state.Departments, func(a, b domain.Department) int { return cmp.Compare(a.ID, b.ID) })
+	slices.SortFunc(state.Employees, func(a, b domain.Employee) int { return cmp.Compare(a.ID, b.ID) })
+
+	r.store.snapshots = append(r.store.snapshots, *snap)
+	r.store.snapshotStates[snap.ID] = state
+	return nil
+}
+
+func (r *memSnapshotRepo) GetByID(ctx context.Context, id int64) (*domain.Snapshot, error) {
+	if id < 1 || id > int64(len(r.store.snapshots)) {
+		return nil, domain.ErrSnapshotNotFound
+	}
+	snap := r.store.snapshots[id-1]
+	return &snap, nil
+}
+
+func (r *memSnapshotRepo) List(ctx context.Context, filter repository.SnapshotFilter) ([]domain.Snapshot, error) {
+	var items []domain.Snapshot
+	for _, snap := range slices.Backward(r.store.snapshots) {
+		if (filter.Name == "" || snap.Name == filter.Name) && (filter.After == nil || snap.ID < filter.After.ID) {
+			items = append(items, snap)
+		}
+	}
+	if len(items) > filter.Limit {
+		items = items[:filter.Limit]
+	}
+	return items, nil
+}
+
+func (r *memSnapshotRepo) GetForest(ctx context.Context, id int64, depth int, includeEmployees bool) ([]domain.Department, error) {
+	state := r.store.snapshotStates[id]
+	var forest []domain.Department
+	for _, dept := range state.Departments {
+		if dept.ParentID == nil {
+			tree, _ := buildSubtree(state.Departments, state.Employees, dept.ID, depth, includeEmployees)
+			forest = append(forest, *tree)
+		}
+	}
+	return forest, nil
+}
+
+func (r *memSnapshotRepo) GetState(ctx context.Context, id int64) (*domain.OrgState, error) {
+	state := r.store.snapshotStates[id]
+	return &state, nil
+}
+
+type memDraftRepo struct {
+	store *memStore
+}
+
+func (r *memDraftRepo) Create(ctx context.Context, draft *domain.Draft) error {
+	draft.ID = int64(len(r.store.drafts) + 1)
+	draft.CreatedAt = time.Now()
+	r.store.drafts[draft.ID] = *draft
+	return nil
+}
+
+func (r *memDraftRepo) GetByID(ctx context.Context, id int64) (*domain.Draft, error) {
+	draft, ok := r.store.drafts[id]
+	if !ok {
+		return nil, domain.ErrDraftNotFound
+	}
+	draft.Operations = slices.Clone(draft.Operations)
+	return &draft, nil
+}
+
+func (r *memDraftRepo) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Draft, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memDraftRepo) AddOperation(ctx context.Context, op *domain.DraftOperation) error {
+	draft := r.store.drafts[op.DraftID]
+	r.store.nextOpID++
+	op.ID = r.store.nextOpID
+	op.Position = 1
+	if n := len(draft.Operations); n > 0 {
+		op.Position = draft.Operations[n-1].Position + 1
+	}
+	draft.Operations = append(slices.Clone(draft.Operations), *op)
+	r.store.drafts[op.DraftID] = draft
+	return nil
+}
+
+func (r *memDraftRepo) DeleteOperation(ctx context.Context, draftID, id int64) error {
+	draft := r.store.drafts[draftID]
+	i := slices.IndexFunc(draft.Operations, func(op domain.DraftOperation) bool { return op.ID == id })
+	if i < 0 {
+		return domain.ErrDraftOperationNotFound
+	}
+	draft.Operations = slices.Delete(slices.Clone(draft.Operations), i, i+1)
+	r.store.drafts[draftID] = draft
+	return nil
+}
+
+func (r *memDraftRepo) Close(ctx context.Context, draft *domain.Draft, status string) error {
+	closedAt := time.Now()
+	draft.Status = status
+	draft.ClosedAt = &closedAt
+
+	stored := r.store.drafts[draft.ID]
+	stored.Status = status
+	stored.ClosedAt = &closedAt
+	r.store.drafts[draft.ID] = stored
+	return nil
+}
+
+// memManagementRepo повторяет обход managementEdgesCTE: руководитель членов подразделения
+// наследуется от родителя, пока у подразделения нет своего руководителя
+type memManagementRepo struct {
+	store *memStore
+}
+
+// edges возвращает рёбра подчинения от подразделений start вниз до уровня maxDepth (0 - без ограничения)
+func (r *memManagementRepo) edges(start []int64, maxDepth int) []repository.ReportLine {
+	children := make(map[int64][]int64)
+	for _, dept := range r.store.departments {
+		if dept.ParentID != nil {
+			children[*dept.ParentID] = append(children[*dept.ParentID], dept.ID)
+		}
+	}
+
+	var edges []repository.ReportLine
+	var walk func(deptID int64, manager *int64, level int)
+	walk = func(deptID int64, manager *int64, level int) {
+		if manager != nil {
+			for _, emp := range r.store.employees {
+				if emp.DepartmentID == deptID && emp.ID != *manager {
+					edges = append(edges, repository.ReportLine{EmployeeID: emp.ID, ManagerID: *manager, Depth: level + 1})
+				}
+			}
+		}
+		if maxDepth != 0 && level >= maxDepth {
+			return
+		}
+		for _, childID := range children[deptID] {
+			head := r.store.departments[childID].HeadEmployeeID
+			if head == nil || (manager != nil && *head == *manager) {
+				walk(childID, manager, level)
+				continue
+			}
+			if manager != nil {
+				edges = append(edges, repository.ReportLine{EmployeeID: *head, ManagerID: *manager, Depth: level + 1})
+			}
+			walk(childID, head, level+1)
+		}
+	}
+	for _, id := range start {
+		walk(id, r.store.departments[id].HeadEmployeeID, 0)
+	}
+	return edges
+}
+
+func (r *memManagementRepo) GetReports(ctx context.Context, managerID int64, maxDepth int) ([]repository.ReportLine, error) {
+	var start []int64
+	for _, dept := range r.store.departments {
+		if dept.HeadEmployeeID != nil && *dept.HeadEmployeeID == managerID {
+			start = append(start, dept.ID)
+		}
+	}
+
+	nearest := make(map[int64]repository.ReportLine)
+	for _, edge := range r.edges(start, maxDepth) {
+		if edge.EmployeeID == managerID || (maxDepth != 0 && edge.Depth > maxDepth) {
+			continue
+		}
+		if cur, ok := nearest[edge.EmployeeID]; !ok || edge.Depth < cur.Depth || (edge.Depth == cur.Depth && edge.ManagerID < cur.ManagerID) {
+			nearest[edge.EmployeeID] = edge
+		}
+	}
+
+	return slices.SortedFunc(maps.Values(nearest), func(a, b repository.ReportLine) int {
+		return cmp.Or(
+			cmp.Compare(a.Depth, b.Depth),
+			cmp.Compare(r.store.employees[a.EmployeeID].FullName, r.store.employees[b.EmployeeID].FullName),
+			cmp.Compare(a.EmployeeID, b.EmployeeID),
+		)
+	}), nil
+}
+
+func (r *memManagementRepo) GetSpans(ctx context.Context, threshold int) ([]repository.ManagerSpan, error) {
+	var roots []int64
+	for _, dept := range r.store.departments {
+		if dept.ParentID == nil {
+			roots = append(roots, dept.ID)
+		}
+	}
+
+	reports := make(map[int64]map[int64]bool)
+	for _, edge := range r.edges(roots, 0) {
+		if reports[edge.ManagerID] == nil {
+			reports[edge.ManagerID] = make(map[int64]bool)
+		}
+		reports[edge.ManagerID][edge.EmployeeID] = true
+	}
+
+	var spans []repository.ManagerSpan
+	for managerID, employees := range reports {
+		if len(employees) > threshold {
+			spans = append(spans, repository.ManagerSpan{ManagerID: managerID, DirectReports: len(employees)})
+		}
+	}
+	slices.SortFunc(spans, func(a, b repository.ManagerSpan) int {
+		return cmp.Or(cmp.Compare(b.DirectReports, a.DirectReports), cmp.Compare(a.ManagerID, b.ManagerID))
+	})
+	return spans, nil
+}
+
+type memDelegationRepo struct {
+	store *memStore
+}
+
+func (r *memDelegationRepo) Create(ctx context.Context, delegation *domain.Delegation) error {
+	delegation.ID = int64(len(r.store.delegations) + 1)
+	delegation.CreatedAt = time.Now()
+	r.store.delegations = append(r.store.delegations, *delegation)
+	return nil
+}
+
+func (r *memDelegationRepo) GetByID(ctx context.Context, id int64) (*domain.Delegation, error) {
+	if id < 1 || id > int64(len(r.store.delegations)) {
+		return nil, domain.ErrDelegationNotFound
+	}
+	delegation := r.store.delegations[id-1]
+	return &delegation, nil
+}
+
+func (r *memDelegationRepo) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Delegation, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memDelegationRepo) List(ctx context.Context, filter repository.DelegationFilter) ([]domain.Delegation, error) {
+	var result []domain.Delegation
+	for _, d := range slices.Backward(r.store.delegations) {
+		switch {
+		case filter.DepartmentID != nil && d.DepartmentID != *filter.DepartmentID,
+			filter.EmployeeID != nil && d.DelegatorID != *filter.EmployeeID && d.DelegateID != *filter.EmployeeID,
+			filter.ActiveOn != nil && !d.ActiveOn(*filter.ActiveOn),
+			filter.After != nil && d.ID >= filter.After.ID:
+			continue
+		}
+		if len(result) == filter.Limit {
+			break
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func (r *memDelegationRepo) GetActive(ctx context.Context, departmentIDs []int64, date time.Time) ([]domain.Delegation, error) {
+	var result []domain.Delegation
+	for _, d := range r.store.delegations {
+		if _, ok := r.store.employees[d.DelegateID]; ok && slices.Contains(departmentIDs, d.DepartmentID) && d.ActiveOn(date) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (r *memDelegationRepo) HasOverlap(ctx context.Context, departmentID, delegatorID int64, from, to time.Time) (bool, error) {
+	for _, d := range r.store.delegations {
+		if d.DepartmentID == departmentID && d.DelegatorID == delegatorID && d.RevokedAt == nil && !d.StartsOn.After(to) && !d.EndsOn.Before(from) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memDelegationRepo) Revoke(ctx context.Context, delegation *domain.Delegation, revokedBy string) error {
+	revokedAt := time.Now()
+	delegation.RevokedBy = &revokedBy
+	delegation.RevokedAt = &revokedAt
+	r.store.delegations[delegation.ID-1] = *delegation
+	return nil
+}
+
+type memPositionRepo struct {
+	store *memStore
+}
+
+func (r *memPositionRepo) Create(ctx context.Context, position *domain.Position) error {
+	if r.findByTitle(position.Title, 0) != nil {
+		return domain.ErrDuplicatePositionTitle
+	}
+	position.ID = r.store.nextPositionID
+	position.CreatedAt = time.Now()
+	position.UpdatedAt = position.CreatedAt
+	r.store.nextPositionID++
+	r.store.positions[position.ID] = *position
+	return nil
+}
+
+func (r *memPositionRepo) GetByID(ctx context.Context, id int64) (*domain.Position, error) {
+	position, ok := r.store.positions[id]
+	if !ok {
+		return nil, domain.ErrPositionNotFound
+	}
+	return &position, nil
+}
+
+func (r *memPositionRepo) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Position, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memPositionRepo) GetByTitle(ctx context.Context, title string) (*domain.Position, error) {
+	if position := r.findByTitle(title, 0); position != nil {
+		return position, nil
+	}
+	return nil, domain.ErrPositionNotFound
+}
+
+func (r *memPositionRepo) GetOrCreateByTitle(ctx context.Context, title string) (*domain.Position, bool, error) {
+	if position := r.findByTitle(title, 0); position != nil {
+		return position, false, nil
+	}
+	position := &domain.Position{Title: title}
+	if err := r.Create(ctx, position); err != nil {
+		return nil, false, err
+	}
+	return position, true, nil
+}
+
+func (r *memPositionRepo) List(ctx context.Context, filter repository.PositionFilter) ([]domain.Position, error) {
+	var result []domain.Position
+	for _, position := range r.store.positions {
+		switch {
+		case filter.Title != "" && !strings.Contains(strings.ToLower(position.Title), strings.ToLower(filter.Title)),
+			filter.JobFamily != "" && (position.JobFamily == nil || *position.JobFamily != filter.JobFamily):
+			continue
+		}
+		if filter.After != nil && cmp.Or(
+			cmp.Compare(strings.ToLower(position.Title), strings.ToLower(filter.After.Value)),
+			cmp.Compare(position.ID, filter.After.ID),
+		) <= 0 {
+			continue
+		}
+		result = append(result, position)
+	}
+
+	slices.SortFunc(result, func(a, b domain.Position) int {
+		return cmp.Or(cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)), cmp.Compare(a.ID, b.ID))
+	})
+	if len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (r *memPositionRepo) Update(ctx context.Context, position *domain.Position) ([]domain.Employee, error) {
+	if r.findByTitle(position.Title, position.ID) != nil {
+		return nil, domain.ErrDuplicatePositionTitle
+	}
+	position.UpdatedAt = time.Now()
+	r.store.positions[position.ID] = *position
+
+	var renamed []domain.Employee
+	for _, employees := range []map[int64]domain.Employee{r.store.employees, r.store.deletedEmployees} {
+		for id, emp := range employees {
+			if emp.PositionID != nil && *emp.PositionID == position.ID && emp.Position != position.Title {
+				emp.Position = position.Title
+				employees[id] = emp
+				renamed = append(renamed, emp)
+			}
+		}
+	}
+	return renamed, nil
+}
+
+func (r *memPositionRepo) Delete(ctx context.Context, id int64) error {
+	if _, ok := r.store.positions[id]; !ok {
+		return domain.ErrPositionNotFound
+	}
+	delete(r.store.positions, id)
+	return nil
+}
+
+func (r *memPositionRepo) IsUsed(ctx context.Context, id int64) (bool, error) {
+	for _, employees := range []map[int64]domain.Employee{r.store.employees, r.store.deletedEmployees} {
+		for _, emp := range employees {
+			if emp.PositionID != nil && *emp.PositionID == id {
+				return true, nil
+			}
+		}
+	}
+	for key := range r.store.headcount {
+		if key[1] == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// findByTitle ищет должность по названию без учёта регистра, кроме должности excludeID
+func (r *memPositionRepo) findByTitle(title string, excludeID int64) *domain.Position {
+	for _, position := range r.store.positions {
+		if position.ID != excludeID && strings.EqualFold(position.Title, title) {
+			return &position
+		}
+	}
+	return nil
+}
+
+type memHeadcountRepo struct {
+	store *memStore
+}
+
+func (r *memHeadcountRepo) Save(ctx context.Context, slot *domain.HeadcountSlot) error {
+	key := [2]int64{slot.DepartmentID, slot.PositionID}
+	if existing, ok := r.store.headcount[key]; ok {
+		slot.ID, slot.CreatedAt = existing.ID, existing.CreatedAt
+	} else {
+		slot.ID, slot.CreatedAt = int64(len(r.store.headcount)+1), time.Now()
+	}
+	slot.UpdatedAt = time.Now()
+	r.store.headcount[key] = *slot
+	return nil
+}
+
+func (r *memHeadcountRepo) Get(ctx context.Context, departmentID, positionID int64) (*domain.HeadcountSlot, error) {
+	slot, ok := r.store.headcount[[2]int64{departmentID, positionID}]
+	if !ok {
+		return nil, domain.ErrHeadcountSlotNotFound
+	}
+	r.fill(&slot)
+	return &slot, nil
+}
+
+func (r *memHeadcountRepo) List(ctx context.Context, departmentIDs []int64) ([]domain.HeadcountSlot, error) {
+	var result []domain.HeadcountSlot
+	for _, slot := range r.store.headcount {
+		if slices.Contains(departmentIDs, slot.DepartmentID) {
+			r.fill(&slot)
+			result = append(result, slot)
+		}
+	}
+	slices.SortFunc(result, func(a, b domain.HeadcountSlot) int {
+		return cmp.Or(
+			cmp.Compare(a.DepartmentID, b.DepartmentID),
+			cmp.Compare(strings.ToLower(a.PositionTitle), strings.ToLower(b.PositionTitle)),
+			cmp.Compare(a.PositionID, b.PositionID),
+		)
+	})
+	return result, nil
+}
+
+func (r *memHeadcountRepo) Delete(ctx context.Context, departmentID, positionID int64) error {
+	key := [2]int64{departmentID, positionID}
+	if _, ok := r.store.headcount[key]; !ok {
+		return domain.ErrHeadcountSlotNotFound
+	}
+	delete(r.store.headcount, key)
+	return nil
+}
+
+// fill заполняет вычисляемые поля места: название должности и число занятых
+func (r *memHeadcountRepo) fill(slot *domain.HeadcountSlot) {
+	slot.PositionTitle = r.store.positions[slot.PositionID].Title
+	slot.Filled = 0
+	for _, emp := range r.store.employees {
+		if emp.DepartmentID == slot.DepartmentID && emp.PositionID != nil && *emp.PositionID == slot.PositionID {
+			slot.Filled++
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/repository"
+)
+
+// encodeCursor упаковывает позицию пагинации в непрозрачную строку
+func encodeCursor(cursor repository.Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor распаковывает строку курсора; пустая строка означает первую страницу
+func decodeCursor(s string) (*repository.Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var cursor repository.Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID <= 0 {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/repository"
+)
+
+// PositionService определяет интерфейс работы со справочником должностей
+type PositionService interface {
+	Create(ctx context.Context, req *dto.CreatePositionRequest) (*domain.Position, error)
+	GetByID(ctx context.Context, id int64) (*domain.Position, error)
+	List(ctx context.Context, query *dto.ListPositionsQuery) ([]domain.Position, string, error)
+	Update(ctx context.Context, id int64, req *dto.UpdatePositionRequest) (*domain.Position, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type positionService struct {
+	positionRepo repository.PositionRepository
+	txManager    repository.TxManager
+}
+
+// NewPositionService создаёт новый экземпляр сервиса
+func NewPositionService(positionRepo repository.PositionRepository, txManager repository.TxManager) PositionService {
+	return &positionService{
+		positionRepo: positionRepo,
+		txManager:    txManager,
+	}
+}
+
+func (s *positionService) Create(ctx context.Context, req *dto.CreatePositionRequest) (*domain.Position, error) {
+	position := &domain.Position{
+		Title:     strings.TrimSpace(req.Title),
+		JobFamily: trimOptional(req.JobFamily),
+		Grade:     req.Grade,
+	}
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.Positions.Create(ctx, position); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, domain.AuditEntityPosition, position.ID, domain.AuditOperationCreate, nil, *position)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return position, nil
+}
+
+func (s *positionService) GetByID(ctx context.Context, id int64) (*domain.Position, error) {
+	return s.positionRepo.GetByID(ctx, id)
+}
+
+// List возвращает страницу справочника, упорядоченного по названию, и курсор следующей страницы
+func (s *positionService) List(ctx context.Context, query *dto.ListPositionsQuery) ([]domain.Position, string, error) {
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	positions, err := s.positionRepo.List(ctx, repository.PositionFilter{
+		Title:     strings.TrimSpace(query.Title),
+		JobFamily: strings.TrimSpace(query.JobFamily),
+		After:     after,
+		// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+		Limit: query.Limit + 1,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(positions) <= query.Limit {
+		return positions, "", nil
+	}
+
+	positions = positions[:query.Limit]
+	last := &positions[len(positions)-1]
+	return positions, encodeCursor(repository.Cursor{Value: last.Title, ID: last.ID}), nil
+}
+
+// Update изменяет должность; новое название сразу отражается в поле position её сотрудников,
+// и каждое такое изменение сотрудника попадает в журнал аудита
+func (s *positionService) Update(ctx context.Context, id int64, req *dto.UpdatePositionRequest) (*domain.Position, error) {
+	var position *domain.Position
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		position, err = repos.Positions.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		before := *position
+
+		if req.Title != nil {
+			position.Title = strings.TrimSpace(*req.Title)
+		}
+		if req.JobFamily != nil {
+			position.JobFamily = trimOptional(req.JobFamily)
+		}
+		if req.Grade != nil {
+			position.Grade = req.Grade
+		}
+
+		renamed, err := repos.Positions.Update(ctx, position)
+		if err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, repos, domain.AuditEntityPosition, id, domain.AuditOperationUpdate, before, *position); err != nil {
+			return err
+		}
+
+		for _, emp := range renamed {
+			empBefore := emp
+			empBefore.Position = before.Title
+			if err := recordAudit(ctx, repos, domain.AuditEntityEmployee, emp.ID, domain.AuditOperationUpdate, empBefore, emp); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return position, nil
+}
+
+// Delete удаляет должность, если она не указана ни у одного сотрудника, включая удалённых,
+// и ни в одном плане численности
+func (s *positionService) Delete(ctx context.Context, id int64) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		position, err := repos.Positions.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		used, err := repos.Positions.IsUsed(ctx, id)
+		if err != nil {
+			return err
+		}
+		if used {
+			return domain.ErrPositionInUse
+		}
+
+		if err := repos.Positions.Delete(ctx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, repos, domain.AuditEntityPosition, id, domain.AuditOperationDelete, *position, nil)
+	})
+}
+
+// resolvePosition привязывает сотрудника к должности из справочника: по positionID,
+// если он задан, иначе по названию emp.Position без учёта регистра. Неизвестное название -
+// ErrPositionNotFound, а при autoCreate - новая должность справочника.
+// Поле Position получает название должности из справочника
+func resolvePosition(ctx context.Context, repos repository.Repositories, emp *domain.Employee, positionID *int64, autoCreate bool) error {
+	var (
+		position *domain.Position
+		created  bool
+		err      error
+	)
+	switch {
+	case positionID != nil:
+		position, err = repos.Positions.GetByID(ctx, *positionID)
+	case autoCreate:
+		position, created, err = repos.Positions.GetOrCreateByTitle(ctx, emp.Position)
+	default:
+		position, err = repos.Positions.GetByTitle(ctx, emp.Position)
+	}
+	if err != nil {
+		return err
+	}
+	if created {
+		if err := recordAudit(ctx, repos, domain.AuditEntityPosition, position.ID, domain.AuditOperationCreate, nil, *position); err != nil {
+			return err
+		}
+	}
+
+	emp.PositionID = &position.ID
+	emp.Position = position.Title
+	return nil
+}
+
+// trimOptional обрезает пробелы в необязательной строке
+func trimOptional(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
@@ -0,0 +1,213 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func newPositionService(store *memStore) service.PositionService {
+	return service.NewPositionService(store.repositories().Positions, &memTxManager{store: store})
+}
+
+func TestCreateEmployee_ResolvesPosition(t *testing.T) {
+	store := newMemStore()
+	it := store.addDepartment("IT", nil)
+	svc, _ := newEmployeeService(store)
+	ctx := context.Background()
+
+	backend, err := newPositionService(store).Create(ctx, &dto.CreatePositionRequest{Title: "Backend Developer", Grade: ptr(5)})
+	if err != nil {
+		t.Fatalf("create position: %v", err)
+	}
+
+	byID, err := svc.Create(ctx, it, &dto.CreateEmployeeRequest{FullName: "Anna", PositionID: &backend.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *byID.PositionID != backend.ID || byID.Position != "Backend Developer" {
+		t.Errorf("expected position from catalog, got %+v", byID)
+	}
+
+	// Название ищется без учёта регистра и приводится к названию из справочника
+	byTitle, err := svc.Create(ctx, it, &dto.CreateEmployeeRequest{FullName: "Bob", Position: " backend developer "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *byTitle.PositionID != backend.ID || byTitle.Position != "Backend Developer" {
+		t.Errorf("expected existing position, got %+v", byTitle)
+	}
+
+	// Неизвестное название не попадает в справочник без явного разрешения
+	if _, err := svc.Create(ctx, it, &dto.CreateEmployeeRequest{FullName: "Carl", Position: "Designer"}); !errors.Is(err, domain.ErrPositionNotFound) {
+		t.Fatalf("expected position not found, got %v", err)
+	}
+	repos := store.repositories()
+	autoCreate := service.NewEmployeeService(repos.Employees, repos.Departments, repos.Transfers, &memTemporalRepo{store: store},
+		&memTxManager{store: store}, service.EmployeePolicy{AutoCreatePositions: true})
+	created, err := autoCreate.Create(ctx, it, &dto.CreateEmployeeRequest{FullName: "Carl", Position: "Designer"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.PositionID == nil || store.positions[*created.PositionID].Title != "Designer" {
+		t.Errorf("expected new position in catalog, got %+v", created)
+	}
+	if len(store.positions) != 2 {
+		t.Errorf("expected 2 positions, got %d", len(store.positions))
+	}
+	if !slices.ContainsFunc(store.audit, func(e domain.AuditEntry) bool {
+		return e.Entity == domain.AuditEntityPosition && e.EntityID == *created.PositionID && e.Operation == domain.AuditOperationCreate
+	}) {
+		t.Errorf("expected auto-created position to be audited")
+	}
+
+	missing := int64(99)
+	if _, err := svc.Create(ctx, it, &dto.CreateEmployeeRequest{FullName: "Dan", PositionID: &missing}); !errors.Is(err, domain.ErrPositionNotFound) {
+		t.Errorf("expected position not found, got %v", err)
+	}
+
+	updated, err := svc.Update(ctx, created.ID, &dto.UpdateEmployeeRequest{PositionID: &backend.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *updated.PositionID != backend.ID || updated.Position != "Backend Developer" {
+		t.Errorf("expected updated position, got %+v", updated)
+	}
+
+	// Изменение ФИО не трогает должность
+	renamed, err := svc.Update(ctx, created.ID, &dto.UpdateEmployeeRequest{FullName: ptr("Carl Smith")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *renamed.PositionID != backend.ID {
+		t.Errorf("expected position to be kept, got %+v", renamed)
+	}
+
+	employees, _, err := svc.ListByDepartment(ctx, it, &dto.ListEmployeesQuery{Limit: 10, Sort: "full_name", PositionID: &backend.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(employees) != 3 {
+		t.Errorf("expected 3 employees with position %d, got %d", backend.ID, len(employees))
+	}
+}
+
+func TestUpdatePosition_RenamesEmployeePositions(t *testing.T) {
+	store := newMemStore()
+	it := store.addDepartment("IT", nil)
+	empSvc, _ := newEmployeeService(store)
+	svc := newPositionService(store)
+	ctx := context.Background()
+	addPosition(t, store, "Developer")
+
+	emp, err := empSvc.Create(ctx, it, &dto.CreateEmployeeRequest{FullName: "Anna", Position: "developer"})
+	if err != nil {
+		t.Fatalf("create employee: %v", err)
+	}
+	if _, err := svc.Create(ctx, &dto.CreatePositionRequest{Title: "Analyst"}); err != nil {
+		t.Fatalf("create position: %v", err)
+	}
+
+	if _, err := svc.Update(ctx, *emp.PositionID, &dto.UpdatePositionRequest{Title: ptr("ANALYST")}); !errors.Is(err, domain.ErrDuplicatePositionTitle) {
+		t.Fatalf("expected duplicate title, got %v", err)
+	}
+
+	position, err := svc.Update(ctx, *emp.PositionID, &dto.UpdatePositionRequest{Title: ptr("Software Engineer"), JobFamily: ptr("Engineering")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if position.Title != "Software Engineer" || *position.JobFamily != "Engineering" {
+		t.Errorf("unexpected position: %+v", position)
+	}
+	if got := store.employees[emp.ID].Position; got != "Software Engineer" {
+		t.Errorf("expected employee position to follow the catalog, got %q", got)
+	}
+}
+
+func TestDeletePosition_InUse(t *testing.T) {
+	store := newMemStore()
+	it := store.addDepartment("IT", nil)
+	empSvc, _ := newEmployeeService(store)
+	svc := newPositionService(store)
+	ctx := context.Background()
+	addPosition(t, store, "Developer")
+
+	emp, err := empSvc.Create(ctx, it, &dto.CreateEmployeeRequest{FullName: "Anna", Position: "developer"})
+	if err != nil {
+		t.Fatalf("create employee: %v", err)
+	}
+
+	// Должность удалённого сотрудника тоже занята: его можно восстановить
+	if err := empSvc.Delete(ctx, emp.ID); err != nil {
+		t.Fatalf("delete employee: %v", err)
+	}
+	if err := svc.Delete(ctx, *emp.PositionID); !errors.Is(err, domain.ErrPositionInUse) {
+		t.Fatalf("expected position in use, got %v", err)
+	}
+
+	// Должность с местами в плане численности тоже занята
+	planned, err := svc.Create(ctx, &dto.CreatePositionRequest{Title: "Designer"})
+	if err != nil {
+		t.Fatalf("create position: %v", err)
+	}
+	if _, err := newHeadcountService(store).SetSlot(ctx, it, planned.ID, &dto.SetHeadcountSlotRequest{Approved: ptr(1)}); err != nil {
+		t.Fatalf("set slot: %v", err)
+	}
+	if err := svc.Delete(ctx, planned.ID); !errors.Is(err, domain.ErrPositionInUse) {
+		t.Fatalf("expected position with headcount in use, got %v", err)
+	}
+
+	unused, err := svc.Create(ctx, &dto.CreatePositionRequest{Title: "Analyst"})
+	if err != nil {
+		t.Fatalf("create position: %v", err)
+	}
+	if err := svc.Delete(ctx, unused.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.GetByID(ctx, unused.ID); !errors.Is(err, domain.ErrPositionNotFound) {
+		t.Errorf("expected position not found, got %v", err)
+	}
+}
+
+func TestListPositions_Paginates(t *testing.T) {
+	store := newMemStore()
+	svc := newPositionService(store)
+	ctx := context.Background()
+
+	for _, title := range []string{"Tester", "analyst", "Developer", "Designer"} {
+		if _, err := svc.Create(ctx, &dto.CreatePositionRequest{Title: title}); err != nil {
+			t.Fatalf("create position: %v", err)
+		}
+	}
+
+	var titles []string
+	query := &dto.ListPositionsQuery{Limit: 3}
+	for {
+		positions, next, err := svc.List(ctx, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, position := range positions {
+			titles = append(titles, position.Title)
+		}
+		if next == "" {
+			break
+		}
+		query.Cursor = next
+	}
+
+	want := []string{"analyst", "Designer", "Developer", "Tester"}
+	if len(titles) != len(want) {
+		t.Fatalf("expected %v, got %v", want, titles)
+	}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, titles)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/repository"
+)
+
+// SnapshotService определяет интерфейс работы со снимками структуры организации
+type SnapshotService interface {
+	Create(ctx context.Context, req *dto.CreateSnapshotRequest) (*domain.Snapshot, error)
+	GetByID(ctx context.Context, id int64) (*domain.Snapshot, error)
+	List(ctx context.Context, query *dto.ListSnapshotsQuery) ([]domain.Snapshot, string, error)
+	GetTree(ctx context.Context, id int64, query *dto.SnapshotTreeQuery) ([]domain.Department, error)
+}
+
+type snapshotService struct {
+	snapshotRepo repository.SnapshotRepository
+}
+
+// NewSnapshotService создаёт новый экземпляр сервиса
+func NewSnapshotService(snapshotRepo repository.SnapshotRepository) SnapshotService {
+	return &snapshotService{snapshotRepo: snapshotRepo}
+}
+
+// Create сохраняет текущие подразделения и сотрудников в новый снимок.
+// Снимок получает следующую версию среди снимков с тем же именем
+func (s *snapshotService) Create(ctx context.Context, req *dto.CreateSnapshotRequest) (*domain.Snapshot, error) {
+	snap := &domain.Snapshot{
+		Name:      strings.TrimSpace(req.Name),
+		CreatedBy: currentActor(ctx),
+	}
+
+	if err := s.snapshotRepo.Create(ctx, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+func (s *snapshotService) GetByID(ctx context.Context, id int64) (*domain.Snapshot, error) {
+	return s.snapshotRepo.GetByID(ctx, id)
+}
+
+// List возвращает страницу снимков, начиная с новых, и курсор следующей страницы
+func (s *snapshotService) List(ctx context.Context, query *dto.ListSnapshotsQuery) ([]domain.Snapshot, string, error) {
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	snapshots, err := s.snapshotRepo.List(ctx, repository.SnapshotFilter{
+		Name:  strings.TrimSpace(query.Name),
+		After: after,
+		// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+		Limit: query.Limit + 1,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(snapshots) <= query.Limit {
+		return snapshots, "", nil
+	}
+
+	snapshots = snapshots[:query.Limit]
+	last := &snapshots[len(snapshots)-1]
+	return snapshots, encodeCursor(repository.Cursor{ID: last.ID}), nil
+}
+
+// GetTree возвращает корневые подразделения снимка с поддеревьями в формате дерева организации
+func (s *snapshotService) GetTree(ctx context.Context, id int64, query *dto.SnapshotTreeQuery) ([]domain.Department, error) {
+	if _, err := s.snapshotRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.snapshotRepo.GetForest(ctx, id, query.Depth, query.IncludeEmployees)
+}
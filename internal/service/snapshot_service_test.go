@@ -0,0 +1,115 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func TestSnapshot_CreateVersionsByName(t *testing.T) {
+	store := newMemStore()
+	store.addDepartment("Company", nil)
+
+	svc := service.NewSnapshotService(&memSnapshotRepo{store: store})
+	ctx := domain.WithActor(context.Background(), "alice")
+
+	first, err := svc.Create(ctx, &dto.CreateSnapshotRequest{Name: " Q1 "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _ := svc.Create(ctx, &dto.CreateSnapshotRequest{Name: "Q1"})
+	other, _ := svc.Create(context.Background(), &dto.CreateSnapshotRequest{Name: "Q2"})
+
+	if first.Name != "Q1" || first.Version != 1 || second.Version != 2 || other.Version != 1 {
+		t.Errorf("unexpected versions: %+v %+v %+v", first, second, other)
+	}
+	if first.CreatedBy != "alice" || other.CreatedBy != "anonymous" {
+		t.Errorf("unexpected authors: %q %q", first.CreatedBy, other.CreatedBy)
+	}
+
+	page, cursor, err := svc.List(ctx, &dto.ListSnapshotsQuery{Limit: 1, Name: "Q1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != second.ID || cursor == "" {
+		t.Fatalf("expected newest Q1 and a cursor, got %+v %q", page, cursor)
+	}
+	page, cursor, _ = svc.List(ctx, &dto.ListSnapshotsQuery{Limit: 1, Name: "Q1", Cursor: cursor})
+	if len(page) != 1 || page[0].ID != first.ID || cursor != "" {
+		t.Errorf("expected first Q1 on the last page, got %+v %q", page, cursor)
+	}
+}
+
+func TestSnapshot_TreeIsFrozen(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	child := store.addDepartment("IT", &root)
+	store.addEmployee(child, "John")
+
+	svc := service.NewSnapshotService(&memSnapshotRepo{store: store})
+	ctx := context.Background()
+
+	snap, err := svc.Create(ctx, &dto.CreateSnapshotRequest{Name: "Q1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Изменения после снимка не попадают в него
+	dept := store.departments[child]
+	dept.Name = "Platform"
+	store.departments[child] = dept
+	store.addDepartment("HR", &root)
+
+	tree, err := svc.GetTree(ctx, snap.ID, &dto.SnapshotTreeQuery{Depth: 5, IncludeEmployees: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tree) != 1 || len(tree[0].Children) != 1 || tree[0].Children[0].Name != "IT" {
+		t.Fatalf("expected frozen tree Company > IT, got %+v", tree)
+	}
+	if employees := tree[0].Children[0].Employees; len(employees) != 1 || employees[0].FullName != "John" {
+		t.Errorf("expected John in IT, got %+v", employees)
+	}
+
+	if _, err := svc.GetTree(ctx, 99, &dto.SnapshotTreeQuery{Depth: 1}); !errors.Is(err, domain.ErrSnapshotNotFound) {
+		t.Errorf("expected snapshot not found, got %v", err)
+	}
+}
+
+func TestDiff_BetweenSnapshots(t *testing.T) {
+	store := newMemStore()
+	root := store.addDepartment("Company", nil)
+	emp := store.addEmployee(root, "John")
+
+	snapshots := &memSnapshotRepo{store: store}
+	snapshotSvc := service.NewSnapshotService(snapshots)
+	diffSvc := service.NewDiffService(&memTemporalRepo{store: store}, snapshots)
+	ctx := context.Background()
+
+	before, _ := snapshotSvc.Create(ctx, &dto.CreateSnapshotRequest{Name: "Q1"})
+	it := store.addDepartment("IT", &root)
+	employee := store.employees[emp]
+	employee.DepartmentID = it
+	store.employees[emp] = employee
+	after, _ := snapshotSvc.Create(ctx, &dto.CreateSnapshotRequest{Name: "Q2"})
+
+	diff, err := diffSvc.Diff(ctx, &dto.OrgDiffQuery{FromSnapshotID: &before.ID, ToSnapshotID: &after.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diff.Departments) != 1 || diff.Departments[0].Type != domain.ChangeDepartmentAdded || diff.Departments[0].DepartmentID != it {
+		t.Errorf("expected IT to be added, got %+v", diff.Departments)
+	}
+	if len(diff.Employees) != 1 || diff.Employees[0].Type != domain.ChangeEmployeeTransferred {
+		t.Errorf("expected John to be transferred, got %+v", diff.Employees)
+	}
+
+	missing := int64(99)
+	if _, err := diffSvc.Diff(ctx, &dto.OrgDiffQuery{FromSnapshotID: &missing, To: ptr("2024-01-01")}); !errors.Is(err, domain.ErrSnapshotNotFound) {
+		t.Errorf("expected snapshot not found, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/org-structure-api/internal/domain"
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/repository"
+)
+
+// TrashService определяет интерфейс работы с корзиной удалённых записей
+type TrashService interface {
+	ListDepartments(ctx context.Context, query *dto.ListTrashQuery) ([]domain.Department, string, error)
+	ListEmployees(ctx context.Context, query *dto.ListTrashQuery) ([]domain.Employee, string, error)
+	Purge(ctx context.Context, retention time.Duration) (*PurgeResult, error)
+}
+
+// PurgeResult - число окончательно удалённых записей
+type PurgeResult struct {
+	Departments int64
+	Employees   int64
+}
+
+type trashService struct {
+	deptRepo  repository.DepartmentRepository
+	empRepo   repository.EmployeeRepository
+	txManager repository.TxManager
+}
+
+// NewTrashService создаёт новый экземпляр сервиса
+func NewTrashService(
+	deptRepo repository.DepartmentRepository,
+	empRepo repository.EmployeeRepository,
+	txManager repository.TxManager,
+) TrashService {
+	return &trashService{
+		deptRepo:  deptRepo,
+		empRepo:   empRepo,
+		txManager: txManager,
+	}
+}
+
+// ListDepartments возвращает страницу удалённых подразделений, начиная с удалённых последними
+func (s *trashService) ListDepartments(ctx context.Context, query *dto.ListTrashQuery) ([]domain.Department, string, error) {
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	departments, err := s.deptRepo.ListDeleted(ctx, repository.TrashFilter{After: after, Limit: query.Limit + 1})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(departments) <= query.Limit {
+		return departments, "", nil
+	}
+
+	departments = departments[:query.Limit]
+	last := &departments[len(departments)-1]
+	return departments, encodeCursor(repository.DeletedAtCursor(last.DeletedAt.Time, last.ID)), nil
+}
+
+// ListEmployees возвращает страницу удалённых сотрудников, начиная с удалённых последними
+func (s *trashService) ListEmployees(ctx context.Context, query *dto.ListTrashQuery) ([]domain.Employee, string, error) {
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	employees, err := s.empRepo.ListDeleted(ctx, repository.TrashFilter{After: after, Limit: query.Limit + 1})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(employees) <= query.Limit {
+		return employees, "", nil
+	}
+
+	employees = employees[:query.Limit]
+	last := &employees[len(employees)-1]
+	return employees, encodeCursor(repository.DeletedAtCursor(last.DeletedAt.Time, last.ID)), nil
+}
+
+// Purge окончательно удаляет записи, пролежавшие в корзине дольше retention
+func (s *trashService) Purge(ctx context.Context, retention time.Duration) (*PurgeResult, error) {
+	deletedBefore := time.Now().Add(-retention)
+
+	result := &PurgeResult{}
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		employees, err := repos.Employees.Purge(ctx, deletedBefore)
+		if err != nil {
+			return err
+		}
+		for _, emp := range employees {
+			if err := recordAudit(ctx, repos, domain.AuditEntityEmployee, emp.ID, domain.AuditOperationPurge, emp, nil); err != nil {
+				return err
+			}
+		}
+
+		departments, err := repos.Departments.Purge(ctx, deletedBefore)
+		if err != nil {
+			return err
+		}
+		for _, dept := range departments {
+			if err := recordAudit(ctx, repos, domain.AuditEntityDepartment, dept.ID, domain.AuditOperationPurge, dept, nil); err != nil {
+				return err
+			}
+		}
+
+		result.Employees = int64(len(employees))
+		result.Departments = int64(len(departments))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
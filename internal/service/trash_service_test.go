@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/org-structure-api/internal/dto"
+	"github.com/org-structure-api/internal/service"
+)
+
+func newTrashService(store *memStore) (service.TrashService, *memTxManager) {
+	txManager := &memTxManager{store: store}
+	repos := store.repositories()
+	return service.NewTrashService(repos.Departments, repos.Employees, txManager), txManager
+}
+
+func TestTrashListDepartments_NewestFirst(t *testing.T) {
+	store := newMemStore()
+	now := time.Now()
+	var want []int64
+	for i := range 5 {
+		id := store.addDepartment("Dept", nil)
+		store.softDeleteDepartment(id, now.Add(time.Duration(i)*time.Minute))
+		want = append([]int64{id}, want...)
+	}
+	store.addDepartment("Live", nil)
+
+	svc, _ := newTrashService(store)
+
+	var got []int64
+	query := &dto.ListTrashQuery{Limit: 2}
+	for {
+		page, next, err := svc.ListDepartments(context.Background(), query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, dept := range page {
+			got = append(got, dept.ID)
+		}
+		if next == "" {
+			break
+		}
+		query.Cursor = next
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTrashPurge_RespectsRetention(t *testing.T) {
+	store := newMemStore()
+	old := store.addDepartment("Old", nil)
+	recent := store.addDepartment("Recent", nil)
+	oldEmp := store.addEmployee(recent, "Old")
+	recentEmp := store.addEmployee(recent, "Recent")
+
+	now := time.Now()
+	store.softDeleteDepartment(old, now.Add(-48*time.Hour))
+	store.softDeleteEmployee(oldEmp, now.Add(-48*time.Hour))
+	store.softDeleteEmployee(recentEmp, now.Add(-time.Hour))
+	store.softDeleteDepartment(recent, now.Add(-time.Hour))
+
+	svc, _ := newTrashService(store)
+	result, err := svc.Purge(context.Background(), 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Departments != 1 || result.Employees != 1 {
+		t.Errorf("expected 1 department and 1 employee purged, got %+v", result)
+	}
+	if _, ok := store.deletedDepartments[recent]; !ok {
+		t.Error("expected recently deleted department to stay in trash")
+	}
+	if _, ok := store.deletedEmployees[recentEmp]; !ok {
+		t.Error("expected recently deleted employee to stay in trash")
+	}
+}
+
+func TestTrashPurge_RollbackOnFailure(t *testing.T) {
+	store := newMemStore()
+	dept := store.addDepartment("Old", nil)
+	store.softDeleteDepartment(dept, time.Now().Add(-48*time.Hour))
+	store.failures["Employees.Purge"] = errInjected
+
+	svc, txManager := newTrashService(store)
+	if _, err := svc.Purge(context.Background(), time.Hour); !errors.Is(err, errInjected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if txManager.rollbacks != 1 {
+		t.Errorf("expected 1 rollback, got %d", txManager.rollbacks)
+	}
+	if _, ok := store.deletedDepartments[dept]; !ok {
+		t.Error("expected department to stay in trash")
+	}
+}